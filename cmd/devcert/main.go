@@ -0,0 +1,50 @@
+// Command devcert writes a self-signed certificate for testing the server
+// over HTTPS locally. The certificate is its own CA, so it can also be given
+// as server.tls.client_ca_file and used as the client certificate to try
+// mutual TLS.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mysql-tui-editor/server/internal/certs"
+)
+
+func main() {
+	hosts := flag.String("hosts", "localhost,127.0.0.1,::1", "Comma-separated host names and IP addresses")
+	validFor := flag.Duration("valid-for", 30*24*time.Hour, "Validity period of the certificate")
+	certFile := flag.String("cert", "./certs/cert.pem", "Path of the certificate file")
+	keyFile := flag.String("key", "./certs/key.pem", "Path of the private key file")
+	flag.Parse()
+
+	certPEM, keyPEM, err := certs.GenerateSelfSigned(strings.Split(*hosts, ","), *validFor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, file := range []struct {
+		path string
+		data []byte
+		perm os.FileMode
+	}{
+		{*certFile, certPEM, 0o644},
+		{*keyFile, keyPEM, 0o600},
+	} {
+		if err := os.MkdirAll(filepath.Dir(file.path), 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(file.path, file.data, file.perm); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("✅ Wrote %s and %s for %s\n", *certFile, *keyFile, *hosts)
+}
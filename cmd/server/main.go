@@ -1,18 +1,25 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 
 	"mysql-tui-editor/server/internal/app"
+	"mysql-tui-editor/server/internal/config"
 )
 
 func main() {
 	// Parse command-line flags
 	configPath := flag.String("config", "./config/config.yml", "Path to configuration file")
+	printConfig := flag.Bool("print-config", false, "Print the effective configuration with secrets redacted and exit")
 	flag.Parse()
 
+	if *printConfig {
+		os.Exit(runPrintConfig(*configPath))
+	}
+
 	// Print banner
 	printBanner()
 
@@ -37,6 +44,29 @@ func main() {
 	}
 }
 
+// runPrintConfig prints the effective configuration as JSON and reports
+// validation errors. It returns the exit code.
+func runPrintConfig(configPath string) int {
+	cfg, err := config.Read(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+		return 1
+	}
+
+	data, err := json.MarshalIndent(cfg.Redacted(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to print config: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(data))
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
 func printBanner() {
 	banner := `
 ╔═══════════════════════════════════════════════════╗
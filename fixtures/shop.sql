@@ -0,0 +1,38 @@
+-- @description: Интернет-магазин: покупатели, товары и заказы
+
+CREATE TABLE customers (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE products (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL
+);
+
+CREATE TABLE orders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL DEFAULT 1,
+    ordered_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_orders_ordered_at (ordered_at),
+    FOREIGN KEY (customer_id) REFERENCES customers (id),
+    FOREIGN KEY (product_id) REFERENCES products (id)
+);
+
+INSERT INTO customers (name, email) VALUES
+    ('Анна', 'anna@example.com'),
+    ('Борис', 'boris@example.com');
+
+INSERT INTO products (title, price) VALUES
+    ('Клавиатура', 2500.00),
+    ('Мышь', 990.00);
+
+INSERT INTO orders (customer_id, product_id, quantity) VALUES
+    (1, 1, 1),
+    (1, 2, 2),
+    (2, 2, 1);
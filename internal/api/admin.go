@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mysql-tui-editor/server/internal/audit"
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/session"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultAuditLimit is the number of audit records returned when no limit is given
+	defaultAuditLimit = 100
+
+	// maxAuditLimit caps the number of audit records returned in one response
+	maxAuditLimit = 10000
+)
+
+// AuditLog handles GET /api/v1/admin/audit
+//
+// Query parameters: user (principal), from and to (RFC 3339), limit.
+func (h *Handler) AuditLog(c *gin.Context) {
+	filter := audit.Filter{
+		Principal: c.Query("user"),
+		Limit:     defaultAuditLimit,
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			writeError(c, domain.NewError(domain.CodeValidation, "Invalid 'from' time, expected RFC 3339"))
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			writeError(c, domain.NewError(domain.CodeValidation, "Invalid 'to' time, expected RFC 3339"))
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit <= 0 || filter.Limit > maxAuditLimit {
+			writeError(c, domain.Errorf(domain.CodeValidation, "Invalid 'limit', expected 1..%d", maxAuditLimit))
+			return
+		}
+	}
+
+	records, err := h.audit.Query(filter)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	if records == nil {
+		records = []audit.Record{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(records),
+		"records": records,
+	})
+}
+
+// AdminSandboxes handles GET /api/v1/admin/sandboxes
+//
+// It lists the live MySQL sandboxes with owner, age, size and the statement
+// currently running, and the session each belongs to.
+func (h *Handler) AdminSandboxes(c *gin.Context) {
+	sandboxes := h.backends.Sandboxes(c.Request.Context())
+
+	sessionsBySandbox := make(map[string]string)
+	for _, info := range h.sessions.List() {
+		sessionsBySandbox[info.Sandbox] = info.ID
+	}
+	for i := range sandboxes {
+		sandboxes[i].Session = sessionsBySandbox[sandboxes[i].Name]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":     len(sandboxes),
+		"sandboxes": sandboxes,
+	})
+}
+
+// AdminSessions handles GET /api/v1/admin/sessions
+func (h *Handler) AdminSessions(c *gin.Context) {
+	sessions := h.sessions.List()
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(sessions),
+		"sessions": sessions,
+	})
+}
+
+// AdminTerminateSession handles DELETE /api/v1/admin/sessions/:id
+//
+// The session is closed regardless of its owner and a running statement is
+// aborted.
+func (h *Handler) AdminTerminateSession(c *gin.Context) {
+	err := h.sessions.Terminate(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, session.ErrNotFound) {
+		writeError(c, err)
+		return
+	}
+
+	// The session is gone even if its sandbox could not be dropped
+	if err != nil {
+		fmt.Printf("WARNING: Failed to close session %s: %v\n", c.Param("id"), err)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AdminOrphans handles GET /api/v1/admin/orphans
+//
+// It lists databases with a sandbox prefix that belong to no live sandbox.
+// Unreachable replicas are skipped. The server assumes it owns the
+// prefixes; servers sharing a MySQL instance must use different prefixes.
+func (h *Handler) AdminOrphans(c *gin.Context) {
+	orphans := h.backends.Orphans(c.Request.Context())
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(orphans),
+		"orphans": orphans,
+	})
+}
+
+// AdminDropOrphans handles DELETE /api/v1/admin/orphans
+func (h *Handler) AdminDropOrphans(c *gin.Context) {
+	orphans := h.backends.DropOrphans(c.Request.Context())
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(orphans),
+		"orphans": orphans,
+	})
+}
+
+// Drain handles POST /api/v1/admin/drain
+//
+// New executions, explains and sessions are rejected with 503 until Resume
+// is called. Running executions and open sessions are not affected.
+func (h *Handler) Drain(c *gin.Context) {
+	h.draining.Store(true)
+	h.DrainStatus(c)
+}
+
+// Resume handles DELETE /api/v1/admin/drain
+func (h *Handler) Resume(c *gin.Context) {
+	h.draining.Store(false)
+	h.DrainStatus(c)
+}
+
+// DrainStatus handles GET /api/v1/admin/drain
+func (h *Handler) DrainStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"draining":  h.draining.Load(),
+		"sandboxes": h.backends.SandboxCount(),
+		"sessions":  h.sessions.Count(),
+	})
+}
+
+// Draining reports whether the server is draining
+func (h *Handler) Draining() bool {
+	return h.draining.Load()
+}
+
+// AdminConfig handles GET /api/v1/admin/config
+//
+// Passwords and tokens are redacted.
+func (h *Handler) AdminConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.config.Load().Redacted())
+}
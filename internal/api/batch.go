@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ndjsonContentType is the media type of streamed batch results
+const ndjsonContentType = "application/x-ndjson"
+
+// Batch handles POST /api/v1/batch
+//
+// Every item runs like /execute in its own sandbox, at most
+// batch.max_parallel items at once and within the slots of the scheduler.
+// The results come in request order together with a summary. A client
+// accepting application/x-ndjson instead receives one line per result as
+// items finish, followed by a {"summary": ...} line. The batch is charged
+// batch.item_cost per item against the rate limit.
+func (h *Handler) Batch(c *gin.Context) {
+	if h.rejectDraining(c) {
+		return
+	}
+
+	cfg := h.config.Load().Batch
+
+	var req domain.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, domain.Errorf(domain.CodeValidation, "Invalid request format: %w", err))
+		return
+	}
+	if err := checkBatchItems(req.Items, cfg.MaxItems); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	// The rate limit middleware has already charged one request
+	if !h.rateLimiter.AllowN(c.ClientIP(), batchCost(len(req.Items), cfg.ItemCost)-1) {
+		writeError(c, errRateLimited)
+		return
+	}
+
+	// A batch may run longer than the write timeout of single executions
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		fmt.Printf("WARNING: Failed to clear the write deadline of a batch: %v\n", err)
+	}
+
+	startTime := time.Now()
+	results := make(chan *domain.BatchItemResult)
+	go h.runBatch(c, req.Items, cfg.MaxParallel, results)
+
+	if strings.Contains(c.GetHeader("Accept"), ndjsonContentType) {
+		streamBatch(c, results, startTime)
+		return
+	}
+
+	response := domain.BatchResponse{Results: make([]*domain.BatchItemResult, len(req.Items))}
+	for result := range results {
+		response.Results[result.Index-1] = result
+		response.Summary.Add(result)
+	}
+	response.Summary.ExecutionTimeMs = time.Since(startTime).Milliseconds()
+
+	c.JSON(http.StatusOK, response)
+}
+
+// checkBatchItems checks the number of items and gives items without an ID
+// their position. IDs must be unique.
+func checkBatchItems(items []domain.BatchItem, maxItems int) error {
+	if len(items) == 0 || len(items) > maxItems {
+		return domain.Errorf(domain.CodeValidation, "A batch must contain 1..%d items, got %d", maxItems, len(items))
+	}
+
+	seen := make(map[string]bool, len(items))
+	for i := range items {
+		if items[i].ID == "" {
+			items[i].ID = strconv.Itoa(i + 1)
+		}
+		if seen[items[i].ID] {
+			return domain.Errorf(domain.CodeValidation, "Duplicate batch item id %q", items[i].ID)
+		}
+		seen[items[i].ID] = true
+	}
+	return nil
+}
+
+// batchCost returns the rate limit cost of a batch: the rounded-up cost of
+// its items, at least one request
+func batchCost(items int, itemCost float64) int {
+	return max(1, int(math.Ceil(float64(items)*itemCost)))
+}
+
+// runBatch executes the items with parallel workers and sends every result.
+// The channel is closed once all items are done.
+func (h *Handler) runBatch(c *gin.Context, items []domain.BatchItem, parallel int, results chan<- *domain.BatchItemResult) {
+	defer close(results)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(parallel, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results <- h.runBatchItem(c, i, &items[i])
+			}
+		}()
+	}
+
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// runBatchItem executes one item like /execute. Rejected items are reported
+// in their result like failed executions.
+func (h *Handler) runBatchItem(c *gin.Context, index int, item *domain.BatchItem) *domain.BatchItemResult {
+	response, err := h.executeRequest(c, &item.ExecuteRequest, h.runEngine)
+	if err != nil {
+		response = domain.NewErrorResponse(domain.CodeOf(err), err.Error())
+	}
+
+	return &domain.BatchItemResult{
+		ID:              item.ID,
+		Index:           index + 1,
+		Status:          resultStatus(response.Success, response.ErrorCode),
+		ExecuteResponse: response,
+	}
+}
+
+// streamBatch writes the results as NDJSON as they arrive, followed by the
+// summary. Results are drained even when the client has gone away.
+func streamBatch(c *gin.Context, results <-chan *domain.BatchItemResult, startTime time.Time) {
+	c.Header("Content-Type", ndjsonContentType)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	var summary domain.BatchSummary
+	for result := range results {
+		summary.Add(result)
+		if err := encoder.Encode(result); err == nil {
+			c.Writer.Flush()
+		}
+	}
+
+	summary.ExecutionTimeMs = time.Since(startTime).Milliseconds()
+	if err := encoder.Encode(gin.H{"summary": summary}); err == nil {
+		c.Writer.Flush()
+	}
+}
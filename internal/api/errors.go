@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"mysql-tui-editor/server/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// errDraining rejects new work while the server is draining
+	errDraining = domain.NewError(domain.CodeUnavailable, "Server is draining and does not accept new executions")
+
+	// errRateLimited rejects clients over the rate limit
+	errRateLimited = domain.NewError(domain.CodeRateLimited, "Rate limit exceeded. Please slow down your requests.")
+
+	// errInternal replaces the details of a recovered panic
+	errInternal = domain.NewError(domain.CodeInternal, "Internal server error")
+)
+
+// writeError aborts the request with the error envelope and the HTTP status
+// of the error code
+func writeError(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(domain.CodeOf(err).HTTPStatus(), domain.NewErrorEnvelope(err))
+}
+
+// respondError writes an error response. While the database is unavailable
+// clients are told to retry after the circuit breaker cooldown.
+func (h *Handler) respondError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrDatabaseUnavailable) {
+		if cooldown := h.config.Load().Executor.BreakerCooldown; cooldown > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(cooldown.Seconds()))))
+		}
+	}
+	writeError(c, err)
+}
+
+// resultStatus returns the HTTP status of an execution result: 200 for a
+// success, the status of the error code otherwise
+func resultStatus(success bool, code domain.ErrorCode) int {
+	if success {
+		return http.StatusOK
+	}
+	return code.HTTPStatus()
+}
+
+// NotFound handles requests to unknown routes
+func NotFound(c *gin.Context) {
+	writeError(c, domain.NewError(domain.CodeNotFound, "Route not found"))
+}
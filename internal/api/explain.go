@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"mysql-tui-editor/server/internal/audit"
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/executor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Explain handles POST /api/v1/explain
+func (h *Handler) Explain(c *gin.Context) {
+	if h.rejectDraining(c) {
+		return
+	}
+
+	var req domain.ExplainRequest
+
+	// Bind JSON body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, domain.Errorf(domain.CodeValidation, "Invalid request format: %w", err))
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	exec, err := h.backends.Get(req.Backend)
+	if err != nil {
+		writeError(c, domain.WithCode(domain.CodeValidation, err))
+		return
+	}
+
+	req.Owner = c.GetHeader(h.principalHeader)
+	script := explainScript(&req)
+	record := h.newAuditRecord(c, script)
+
+	// Validate SQL security of both the setup and the explained query
+	if err := h.validator.Validate(script); err != nil {
+		record.Verdict = audit.VerdictRejected
+		record.VerdictReason = err.Error()
+		h.audit.Log(record)
+
+		writeError(c, domain.Errorf(domain.CodePolicyViolation, "Security validation failed: %w", err))
+		return
+	}
+
+	// Wait for a slot, then explain query
+	startTime := time.Now()
+	response, err := h.explain(c.Request.Context(), exec, &req)
+	executionTime := time.Since(startTime)
+
+	record.DurationMs = executionTime.Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+		h.audit.Log(record)
+
+		if domain.CodeOf(err) == domain.CodeInternal {
+			err = domain.Errorf(domain.CodeInternal, "Internal server error: %w", err)
+		}
+		h.respondError(c, err)
+		return
+	}
+
+	logQueryExecution(script, response.Success, executionTime)
+
+	record.Success = response.Success
+	record.Error = response.Error
+	record.Sandbox = response.Sandbox
+	h.audit.Log(record)
+
+	c.JSON(resultStatus(response.Success, response.ErrorCode), response)
+}
+
+// explain explains the request with exec while holding a scheduler slot
+func (h *Handler) explain(ctx context.Context, exec *executor.MySQLExecutor, req *domain.ExplainRequest) (*domain.ExplainResponse, error) {
+	if err := h.scheduler.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer h.scheduler.Release()
+
+	return exec.Explain(ctx, req)
+}
+
+// explainScript returns the full SQL run for an explain request,
+// used for validation and auditing
+func explainScript(req *domain.ExplainRequest) string {
+	var script strings.Builder
+	if setup := strings.TrimSpace(req.Setup); setup != "" {
+		script.WriteString(setup)
+		if !strings.HasSuffix(setup, ";") {
+			script.WriteString(";")
+		}
+		script.WriteString("\n")
+	}
+
+	script.WriteString("EXPLAIN ")
+	script.WriteString(req.Query)
+	return script.String()
+}
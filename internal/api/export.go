@@ -0,0 +1,128 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/export"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export handles POST /api/v1/export
+//
+// It executes the request like /execute and downloads a result set as a
+// file. The format comes from the "format" query parameter or the Accept
+// header (csv by default). The "statement" query parameter selects the
+// 1-based statement whose result set is exported; by default the last
+// result set is used. XLSX exports contain one worksheet per result set.
+func (h *Handler) Export(c *gin.Context) {
+	format, err := export.Negotiate(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		writeError(c, domain.WithCode(domain.CodeValidation, err))
+		return
+	}
+	if format == export.FormatJSON {
+		format = export.FormatCSV
+	}
+
+	response, ok := h.execute(c, h.runEngine)
+	if !ok {
+		return
+	}
+
+	if !response.Success {
+		c.JSON(resultStatus(response.Success, response.ErrorCode), response)
+		return
+	}
+
+	h.writeExport(c, response, format, true)
+}
+
+// writeExport writes the selected result sets of a successful execution in
+// the given format, optionally as a file attachment
+func (h *Handler) writeExport(c *gin.Context, response *domain.ExecuteResponse, format export.Format, attachment bool) {
+	sheets, err := selectSheets(response.Statements, c.Query("statement"), format == export.FormatXLSX)
+	if err != nil {
+		writeError(c, domain.WithCode(domain.CodeValidation, err))
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	if attachment {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="result.%s"`, format.Extension()))
+	}
+	c.Status(http.StatusOK)
+
+	if err := export.Write(c.Writer, format, sheets); err != nil {
+		// Headers are already sent, so the error can only be logged
+		fmt.Printf("WARNING: Failed to write %s export: %v\n", format, err)
+	}
+}
+
+// selectSheets picks the result sets to export. When statement is empty the
+// last result set is used, or every result set for workbook formats. A
+// selected statement contributes its last result set, or all of them for
+// workbook formats.
+func selectSheets(statements []domain.StatementResult, statement string, all bool) ([]export.Sheet, error) {
+	if statement != "" {
+		index, err := strconv.Atoi(statement)
+		if err != nil || index < 1 || index > len(statements) {
+			return nil, fmt.Errorf("invalid statement %q: expected 1..%d", statement, len(statements))
+		}
+
+		stmt := statements[index-1]
+		if len(stmt.ResultSets) == 0 {
+			return nil, fmt.Errorf("statement %d did not return a result set", index)
+		}
+		return statementSheets(stmt, all), nil
+	}
+
+	var sheets []export.Sheet
+	for i := len(statements) - 1; i >= 0; i-- {
+		if len(statements[i].ResultSets) == 0 {
+			continue
+		}
+		sheets = append(statementSheets(statements[i], all), sheets...)
+		if !all {
+			break
+		}
+	}
+
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("the query did not return a result set")
+	}
+
+	return sheets, nil
+}
+
+// statementSheets creates the sheets of a statement: all of its result sets,
+// or only the last one
+func statementSheets(stmt domain.StatementResult, all bool) []export.Sheet {
+	first := 0
+	if !all {
+		first = len(stmt.ResultSets) - 1
+	}
+
+	sheets := make([]export.Sheet, 0, len(stmt.ResultSets)-first)
+	for i := first; i < len(stmt.ResultSets); i++ {
+		sheets = append(sheets, newSheet(stmt, i))
+	}
+	return sheets
+}
+
+// newSheet creates an export sheet named after the statement position and,
+// for statements with several result sets, the result set position
+func newSheet(stmt domain.StatementResult, resultSet int) export.Sheet {
+	name := fmt.Sprintf("Statement %d", stmt.Index)
+	if len(stmt.ResultSets) > 1 {
+		name = fmt.Sprintf("Statement %d.%d", stmt.Index, resultSet+1)
+	}
+
+	return export.Sheet{
+		Name: name,
+		Rows: stmt.ResultSets[resultSet].Iterator(),
+	}
+}
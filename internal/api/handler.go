@@ -1,92 +1,299 @@
 package api
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"mysql-tui-editor/server/internal/audit"
+	"mysql-tui-editor/server/internal/config"
 	"mysql-tui-editor/server/internal/domain"
 	"mysql-tui-editor/server/internal/executor"
+	"mysql-tui-editor/server/internal/export"
+	"mysql-tui-editor/server/internal/formatter"
+	"mysql-tui-editor/server/internal/jobs"
 	"mysql-tui-editor/server/internal/security"
+	"mysql-tui-editor/server/internal/session"
+	"mysql-tui-editor/server/internal/snapshot"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Handler handles HTTP requests
 type Handler struct {
-	executor  *executor.MySQLExecutor
-	validator *security.Validator
+	backends        *executor.Backends
+	engines         *executor.Engines
+	validator       *security.Validator
+	audit           *audit.Logger
+	sessions        *session.Manager
+	jobs            *jobs.Manager
+	snapshots       *snapshot.Manager
+	scheduler       *executor.Scheduler
+	rateLimiter     *RateLimiter
+	principalHeader string
+
+	// config is the current configuration, replaced on reload
+	config atomic.Pointer[config.Config]
+
+	// fixtureSchemas caches fixture schemas by fixture name
+	fixtureSchemas sync.Map
+
+	// draining is set while the server accepts no new executions
+	draining atomic.Bool
+
+	// shuttingDown is set once a graceful shutdown begins
+	shuttingDown atomic.Bool
+
+	startedAt time.Time
 }
 
-// NewHandler creates a new HTTP handler
-func NewHandler(executor *executor.MySQLExecutor, validator *security.Validator) *Handler {
-	return &Handler{
-		executor:  executor,
-		validator: validator,
+// runFunc executes a validated execution request
+type runFunc func(ctx context.Context, req *domain.ExecuteRequest) (*domain.ExecuteResponse, error)
+
+// NewHandler creates a new HTTP handler. The MySQL backends serve sessions,
+// fixtures and EXPLAIN; engines serve one-off executions and jobs. All
+// executions share the slots of the scheduler. The audit logger may be nil.
+func NewHandler(backends *executor.Backends, engines *executor.Engines, validator *security.Validator, auditLogger *audit.Logger, sessions *session.Manager, jobManager *jobs.Manager, snapshots *snapshot.Manager, scheduler *executor.Scheduler, rateLimiter *RateLimiter, cfg *config.Config) *Handler {
+	h := &Handler{
+		backends:        backends,
+		engines:         engines,
+		validator:       validator,
+		audit:           auditLogger,
+		sessions:        sessions,
+		jobs:            jobManager,
+		snapshots:       snapshots,
+		scheduler:       scheduler,
+		rateLimiter:     rateLimiter,
+		principalHeader: cfg.Audit.PrincipalHeader,
+		startedAt:       time.Now(),
 	}
+	h.config.Store(cfg)
+	return h
+}
+
+// SetConfig replaces the configuration after a reload
+func (h *Handler) SetConfig(cfg *config.Config) {
+	h.config.Store(cfg)
 }
 
 // ExecuteQuery handles POST /api/v1/execute
+//
+// The response is JSON by default. A non-JSON format selected by the
+// "format" query parameter or the Accept header returns the chosen result
+// set in that format instead (see Export).
 func (h *Handler) ExecuteQuery(c *gin.Context) {
+	format, err := export.Negotiate(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		writeError(c, domain.WithCode(domain.CodeValidation, err))
+		return
+	}
+
+	response, ok := h.execute(c, h.runEngine)
+	if !ok {
+		return
+	}
+
+	if format != export.FormatJSON && response.Success {
+		h.writeExport(c, response, format, false)
+		return
+	}
+
+	// Return response
+	c.JSON(resultStatus(response.Success, response.ErrorCode), response)
+}
+
+// execute binds, validates, audits and executes an execution request with
+// run. It returns false when an error response has already been written.
+func (h *Handler) execute(c *gin.Context, run runFunc) (*domain.ExecuteResponse, bool) {
+	if h.rejectDraining(c) {
+		return nil, false
+	}
+
 	var req domain.ExecuteRequest
 
 	// Bind JSON body
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, domain.NewErrorResponse("Invalid request format: "+err.Error()))
-		return
+		writeError(c, domain.Errorf(domain.CodeValidation, "Invalid request format: %w", err))
+		return nil, false
 	}
 
+	response, err := h.executeRequest(c, &req, run)
+	if err != nil {
+		h.respondError(c, err)
+		return nil, false
+	}
+
+	return response, true
+}
+
+// executeRequest validates, audits and executes a bound execution request
+// with run once the scheduler has a free slot. Requests that are rejected
+// or cannot be run return an error.
+func (h *Handler) executeRequest(c *gin.Context, req *domain.ExecuteRequest, run runFunc) (*domain.ExecuteResponse, error) {
+	record, err := h.admit(c, req)
+	if err != nil {
+		return nil, err
+	}
+	return h.runAdmitted(c.Request.Context(), req, record, run)
+}
+
+// admit validates a bound execution request and checks it against the
+// security rules. It returns the audit record of an admitted request;
+// rejections are audited.
+func (h *Handler) admit(c *gin.Context, req *domain.ExecuteRequest) (*audit.Record, error) {
 	// Validate request
 	if err := req.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, domain.NewErrorResponse(err.Error()))
-		return
+		return nil, err
+	}
+
+	if _, err := formatter.ParseMode(req.OutputMode); err != nil {
+		return nil, domain.WithCode(domain.CodeValidation, err)
+	}
+
+	engine, err := h.engines.Get(req.Engine)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fixtures are MySQL scripts and backends are MySQL servers
+	if engine.Engine() != domain.EngineMySQL && (req.Fixture != "" || req.Backend != "") {
+		return nil, domain.NewError(domain.CodeValidation, "Fixtures and backends are only supported by the mysql engine")
+	}
+
+	// Params are bound with MySQL prepared statements
+	if engine.Engine() != domain.EngineMySQL && req.Params != nil {
+		return nil, domain.NewError(domain.CodeValidation, "Params are only supported by the mysql engine")
 	}
 
+	if req.Fixture != "" {
+		if _, err := h.backends.Fixtures().Get(req.Fixture); err != nil {
+			return nil, domain.WithCode(domain.CodeValidation, err)
+		}
+	}
+
+	if engine.Engine() == domain.EngineMySQL {
+		if _, err := h.backends.Resolve(req.Backend, req.Fixture); err != nil {
+			return nil, domain.WithCode(domain.CodeValidation, err)
+		}
+	}
+
+	req.Owner = c.GetHeader(h.principalHeader)
+	record := h.newAuditRecord(c, req.Query)
+
 	// Validate SQL security
-	if err := h.validator.Validate(req.Query); err != nil {
-		c.JSON(http.StatusForbidden, domain.NewErrorResponse("Security validation failed: "+err.Error()))
-		return
+	if err := h.validator.ValidateEngine(engine.Engine(), req.Query); err != nil {
+		record.Verdict = audit.VerdictRejected
+		record.VerdictReason = err.Error()
+		h.audit.Log(record)
+
+		return nil, domain.Errorf(domain.CodePolicyViolation, "Security validation failed: %w", err)
 	}
 
-	// Execute query
+	return record, nil
+}
+
+// runAdmitted executes an admitted request with run once the scheduler has
+// a free slot and completes its audit record
+func (h *Handler) runAdmitted(ctx context.Context, req *domain.ExecuteRequest, record *audit.Record, run runFunc) (*domain.ExecuteResponse, error) {
+	// Wait for a slot, then execute query
 	startTime := time.Now()
-	response, err := h.executor.Execute(c.Request.Context(), req.Query)
+	response, err := h.schedule(ctx, req, run)
 	executionTime := time.Since(startTime)
 
+	record.DurationMs = executionTime.Milliseconds()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.NewErrorResponse("Internal server error: "+err.Error()))
-		return
+		record.Error = err.Error()
+		h.audit.Log(record)
+
+		if domain.CodeOf(err) == domain.CodeInternal {
+			err = domain.Errorf(domain.CodeInternal, "Internal server error: %w", err)
+		}
+		return nil, err
 	}
 
 	// Log execution
 	logQueryExecution(req.Query, response.Success, executionTime)
 
-	// Return response
-	if response.Success {
-		c.JSON(http.StatusOK, response)
-	} else {
-		c.JSON(http.StatusOK, response) // Still 200 OK, but success=false
+	record.Success = response.Success
+	record.Error = response.Error
+	record.Statements = response.Statements
+	record.Sandbox = response.Sandbox
+	h.audit.Log(record)
+
+	return response, nil
+}
+
+// schedule runs the request with run while holding a scheduler slot
+func (h *Handler) schedule(ctx context.Context, req *domain.ExecuteRequest, run runFunc) (*domain.ExecuteResponse, error) {
+	if err := h.scheduler.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer h.scheduler.Release()
+
+	return run(ctx, req)
+}
+
+// rejectDraining responds with 503 Service Unavailable while the server is
+// draining. It returns true when the request was rejected.
+func (h *Handler) rejectDraining(c *gin.Context) bool {
+	if !h.draining.Load() {
+		return false
 	}
+
+	writeError(c, errDraining)
+	return true
+}
+
+// runEngine executes a request with the executor of the requested engine
+func (h *Handler) runEngine(ctx context.Context, req *domain.ExecuteRequest) (*domain.ExecuteResponse, error) {
+	exec, err := h.engines.Get(req.Engine)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Execute(ctx, req)
 }
 
 // HealthCheck handles GET /api/v1/health
+//
+// Every MySQL backend is checked independently. The server is unhealthy
+// when all replicas of a backend are down.
 func (h *Handler) HealthCheck(c *gin.Context) {
-	// Check MySQL connection
-	if err := h.executor.GetDB().Ping(); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"status":  "unhealthy",
-			"message": "MySQL connection failed",
-			"error":   err.Error(),
-		})
-		return
+	backends := h.backends.Health(c.Request.Context())
+
+	for _, backend := range backends {
+		if !backend.Healthy {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":   "unhealthy",
+				"message":  "MySQL connection failed",
+				"error":    "backend " + backend.Name + " is unavailable",
+				"backends": backends,
+			})
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
-		"message": "Server is running",
-		"time":    time.Now().Format(time.RFC3339),
+		"status":   "healthy",
+		"message":  "Server is running",
+		"time":     time.Now().Format(time.RFC3339),
+		"backends": backends,
 	})
 }
 
+// newAuditRecord creates an audit record for the current request
+func (h *Handler) newAuditRecord(c *gin.Context, query string) *audit.Record {
+	return &audit.Record{
+		Time:      time.Now().UTC(),
+		RequestID: c.GetString(requestIDKey),
+		Principal: c.GetHeader(h.principalHeader),
+		ClientIP:  c.ClientIP(),
+		Query:     query,
+		Verdict:   audit.VerdictAllowed,
+	}
+}
+
 // logQueryExecution logs query execution details
 func logQueryExecution(query string, success bool, duration time.Duration) {
 	// Simple logging - in production, use structured logger
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkFunc runs one liveness or readiness check and returns its details
+type checkFunc func(ctx context.Context) (interface{}, error)
+
+// Livez handles GET /livez
+//
+// The process is alive while it can serve requests; dependencies are not
+// checked, so that a MySQL outage does not restart the server.
+func (h *Handler) Livez(c *gin.Context) {
+	h.writeChecks(c, h.runCheck(c.Request.Context(), "process", func(ctx context.Context) (interface{}, error) {
+		return gin.H{
+			"uptime":     time.Since(h.startedAt).Round(time.Second).String(),
+			"goroutines": runtime.NumGoroutine(),
+		}, nil
+	}))
+}
+
+// Readyz handles GET /readyz
+//
+// The server is ready when every MySQL backend answers within the readiness
+// timeout and can create and drop a sandbox, the connection pools are not
+// saturated, few sandboxes await cleanup and the server is neither draining
+// nor shutting down.
+func (h *Handler) Readyz(c *gin.Context) {
+	ctx := c.Request.Context()
+	cfg := h.config.Load().Readiness
+
+	checks := []domain.CheckResult{
+		h.runCheck(ctx, "draining", func(ctx context.Context) (interface{}, error) {
+			details := gin.H{"draining": h.Draining(), "shutting_down": h.shuttingDown.Load()}
+			switch {
+			case h.shuttingDown.Load():
+				return details, errors.New("server is shutting down")
+			case h.Draining():
+				return details, errors.New("server is draining")
+			}
+			return details, nil
+		}),
+		h.runCheck(ctx, "mysql", func(ctx context.Context) (interface{}, error) {
+			backends := h.backends.Health(ctx)
+			for _, backend := range backends {
+				if !backend.Healthy {
+					return backends, fmt.Errorf("backend %s is unavailable", backend.Name)
+				}
+			}
+			return backends, nil
+		}),
+	}
+
+	if cfg.ProbeSandbox {
+		checks = append(checks, h.runCheck(ctx, "sandbox", func(ctx context.Context) (interface{}, error) {
+			return nil, h.backends.Probe(ctx)
+		}))
+	}
+
+	checks = append(checks,
+		h.runCheck(ctx, "pool", func(ctx context.Context) (interface{}, error) {
+			stats := h.backends.PoolStats()
+			if cfg.MaxPoolUsage > 0 {
+				for _, pool := range stats {
+					if pool.Usage >= cfg.MaxPoolUsage {
+						return stats, fmt.Errorf("backend %s uses %d of %d connections", pool.Backend, pool.InUse, pool.MaxOpen)
+					}
+				}
+			}
+			return stats, nil
+		}),
+		h.runCheck(ctx, "cleanup", func(ctx context.Context) (interface{}, error) {
+			backlog := h.backends.CleanupBacklog()
+			details := gin.H{"backlog": backlog, "max": cfg.MaxCleanupBacklog}
+			if cfg.MaxCleanupBacklog > 0 && backlog >= cfg.MaxCleanupBacklog {
+				return details, fmt.Errorf("%d sandboxes could not be dropped", backlog)
+			}
+			return details, nil
+		}),
+	)
+
+	h.writeChecks(c, checks...)
+}
+
+// runCheck runs a check within the readiness timeout and measures it
+func (h *Handler) runCheck(ctx context.Context, name string, check checkFunc) domain.CheckResult {
+	if timeout := h.config.Load().Readiness.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	details, err := check(ctx)
+
+	result := domain.CheckResult{
+		Name:       name,
+		Healthy:    err == nil,
+		DurationMs: time.Since(start).Milliseconds(),
+		Details:    details,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// writeChecks responds with 200 when every check passed and 503 otherwise
+func (h *Handler) writeChecks(c *gin.Context, checks ...domain.CheckResult) {
+	status, code := "ok", http.StatusOK
+	for _, check := range checks {
+		if !check.Healthy {
+			status, code = "failing", http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	c.JSON(code, gin.H{
+		"status": status,
+		"time":   time.Now().Format(time.RFC3339),
+		"checks": checks,
+	})
+}
+
+// ShutDown makes readiness fail for the rest of the process lifetime. It is
+// called when a graceful shutdown begins.
+func (h *Handler) ShutDown() {
+	h.shuttingDown.Store(true)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"mysql-tui-editor/server/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubmitJob handles POST /api/v1/jobs
+//
+// The request is validated like /execute and answered with 202 Accepted
+// and the queued job right away. The execution runs in the job worker pool
+// with jobs.query_timeout and its outcome is fetched with GetJob.
+func (h *Handler) SubmitJob(c *gin.Context) {
+	if h.rejectDraining(c) {
+		return
+	}
+
+	var req domain.JobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, domain.Errorf(domain.CodeValidation, "Invalid request format: %w", err))
+		return
+	}
+
+	if req.Callback && !h.jobs.CallbacksEnabled() {
+		writeError(c, domain.NewError(domain.CodeValidation, "Callbacks are disabled: jobs.callback_url is not configured"))
+		return
+	}
+
+	execReq := &req.ExecuteRequest
+	record, err := h.admit(c, execReq)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	execReq.Timeout = h.config.Load().Jobs.QueryTimeout
+
+	job, err := h.jobs.Submit(execReq.Owner, c.GetString(requestIDKey), req.Callback, func(ctx context.Context) (*domain.ExecuteResponse, error) {
+		return h.runAdmitted(ctx, execReq, record, h.runEngine)
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob handles GET /api/v1/jobs/:id
+func (h *Handler) GetJob(c *gin.Context) {
+	job, err := h.jobs.Get(c.Param("id"), c.GetHeader(h.principalHeader))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelJob handles DELETE /api/v1/jobs/:id
+//
+// A queued or running job is cancelled and kept with its partial result; a
+// finished job is deleted.
+func (h *Handler) CancelJob(c *gin.Context) {
+	if err := h.jobs.Cancel(c.Param("id"), c.GetHeader(h.principalHeader)); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,14 +1,31 @@
 package api
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/logging"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"golang.org/x/time/rate"
 )
 
+const (
+	// RequestIDHeader is the header carrying the request identifier
+	RequestIDHeader = "X-Request-ID"
+
+	// requestIDKey is the Gin context key for the request identifier
+	requestIDKey = "request_id"
+
+	// maxRequestIDLength limits client-supplied request identifiers
+	maxRequestIDLength = 64
+)
+
 // RateLimiter implements per-IP rate limiting
 type RateLimiter struct {
 	limiters map[string]*rate.Limiter
@@ -26,6 +43,20 @@ func NewRateLimiter(ratePerSecond int, burst int) *RateLimiter {
 	}
 }
 
+// SetLimits changes the rate and burst of all clients, including the
+// clients seen before
+func (rl *RateLimiter) SetLimits(ratePerSecond int, burst int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.rate = rate.Limit(ratePerSecond)
+	rl.burst = burst
+	for _, limiter := range rl.limiters {
+		limiter.SetLimit(rl.rate)
+		limiter.SetBurst(rl.burst)
+	}
+}
+
 // getLimiter gets or creates a limiter for an IP address
 func (rl *RateLimiter) getLimiter(ip string) *rate.Limiter {
 	rl.mu.Lock()
@@ -40,6 +71,21 @@ func (rl *RateLimiter) getLimiter(ip string) *rate.Limiter {
 	return limiter
 }
 
+// AllowN reports whether the client at ip may spend n more requests now and
+// spends them if so. n is capped at the burst, so that any cost can be paid
+// once the client has waited long enough.
+func (rl *RateLimiter) AllowN(ip string, n int) bool {
+	if n <= 0 {
+		return true
+	}
+
+	rl.mu.RLock()
+	n = min(n, rl.burst)
+	rl.mu.RUnlock()
+
+	return rl.getLimiter(ip).AllowN(time.Now(), n)
+}
+
 // RateLimitMiddleware creates a Gin middleware for rate limiting
 func (rl *RateLimiter) RateLimitMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -47,10 +93,7 @@ func (rl *RateLimiter) RateLimitMiddleware() gin.HandlerFunc {
 		limiter := rl.getLimiter(ip)
 
 		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded. Please slow down your requests.",
-			})
-			c.Abort()
+			writeError(c, errRateLimited)
 			return
 		}
 
@@ -63,7 +106,8 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, X-User-ID, If-None-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -75,6 +119,60 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
+// RequestIDMiddleware assigns every request an identifier, reusing a sane
+// client-supplied X-Request-ID header when present
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength || strings.ContainsAny(requestID, " \t\r\n") {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(requestIDKey, requestID)
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// AdminAuthMiddleware protects admin endpoints with a static bearer token.
+// An empty token disables the admin API entirely.
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			writeError(c, domain.NewError(domain.CodeForbidden, "Admin API is disabled"))
+			return
+		}
+
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			writeError(c, domain.NewError(domain.CodeUnauthorized, "Invalid admin token"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SetLogLevel sets the logging level of the request log and the warnings.
+// At warn only failed requests are logged, at error only server errors and
+// no warnings.
+func SetLogLevel(level string) {
+	logging.SetLevel(level)
+}
+
+// logsStatus reports whether a request with the status code is logged at the
+// current level
+func logsStatus(statusCode int) bool {
+	switch logging.Level() {
+	case "warn":
+		return statusCode >= http.StatusBadRequest
+	case "error":
+		return statusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
 // LoggingMiddleware logs HTTP requests
 func LoggingMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -88,9 +186,12 @@ func LoggingMiddleware() gin.HandlerFunc {
 		// Log after processing
 		duration := time.Since(startTime)
 		statusCode := c.Writer.Status()
+		if !logsStatus(statusCode) {
+			return
+		}
 		clientIP := c.ClientIP()
 
-		println(time.Now().Format("2006-01-02 15:04:05"), method, path, statusCode, duration.String(), clientIP)
+		println(time.Now().Format("2006-01-02 15:04:05"), method, path, statusCode, duration.String(), clientIP, c.GetString(requestIDKey))
 	}
 }
 
@@ -100,9 +201,7 @@ func RecoveryMiddleware() gin.HandlerFunc {
 		defer func() {
 			if err := recover(); err != nil {
 				println("PANIC RECOVERED:", err)
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal server error",
-				})
+				writeError(c, errInternal)
 			}
 		}()
 		c.Next()
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/export"
+	"mysql-tui-editor/server/internal/fixture"
+	"mysql-tui-editor/server/internal/session"
+	"mysql-tui-editor/server/internal/snapshot"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSession handles POST /api/v1/sessions
+//
+// The sandbox is prepared from a fixture or restored from a snapshot, if
+// one is given.
+func (h *Handler) CreateSession(c *gin.Context) {
+	if h.rejectDraining(c) {
+		return
+	}
+
+	var req domain.CreateSessionRequest
+
+	// The body is optional
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeError(c, domain.Errorf(domain.CodeValidation, "Invalid request format: %w", err))
+			return
+		}
+	}
+
+	if req.Fixture != "" && req.Snapshot != "" {
+		writeError(c, domain.NewError(domain.CodeValidation, "A session is created from a fixture or a snapshot, not both"))
+		return
+	}
+
+	if req.Fixture != "" {
+		if _, err := h.backends.Fixtures().Get(req.Fixture); err != nil {
+			writeError(c, domain.WithCode(domain.CodeValidation, err))
+			return
+		}
+	}
+
+	// A snapshot replaces the fixture and selects the backend by default
+	var snap *snapshot.Snapshot
+	if req.Snapshot != "" {
+		var err error
+		snap, err = h.snapshots.Get(req.Snapshot, c.GetHeader(h.principalHeader))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		if req.Backend == "" {
+			req.Backend = snap.Info.Backend
+		}
+	}
+
+	if _, err := h.backends.Resolve(req.Backend, req.Fixture); err != nil {
+		writeError(c, domain.WithCode(domain.CodeValidation, err))
+		return
+	}
+
+	sess, err := h.sessions.Create(c.Request.Context(), c.GetHeader(h.principalHeader), req.Fixture, req.Backend)
+	if err != nil {
+		h.respondError(c, fmt.Errorf("Failed to create session: %w", err))
+		return
+	}
+
+	if snap != nil {
+		if err := h.restore(c, sess, snap.Script); err != nil {
+			if deleteErr := h.sessions.Delete(context.Background(), sess.ID, sess.Owner); deleteErr != nil {
+				fmt.Printf("WARNING: Failed to close session %s: %v\n", sess.ID, deleteErr)
+			}
+			h.respondError(c, fmt.Errorf("Failed to restore snapshot: %w", err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusCreated, h.sessions.Info(sess))
+}
+
+// GetSession handles GET /api/v1/sessions/:id
+func (h *Handler) GetSession(c *gin.Context) {
+	sess, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, h.sessions.Info(sess))
+}
+
+// DeleteSession handles DELETE /api/v1/sessions/:id
+func (h *Handler) DeleteSession(c *gin.Context) {
+	err := h.sessions.Delete(c.Request.Context(), c.Param("id"), c.GetHeader(h.principalHeader))
+	if errors.Is(err, session.ErrNotFound) || errors.Is(err, session.ErrForbidden) {
+		writeError(c, err)
+		return
+	}
+
+	// The session is gone even if its sandbox could not be dropped
+	if err != nil {
+		fmt.Printf("WARNING: Failed to close session %s: %v\n", c.Param("id"), err)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ExecuteInSession handles POST /api/v1/sessions/:id/execute
+func (h *Handler) ExecuteInSession(c *gin.Context) {
+	format, err := export.Negotiate(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		writeError(c, domain.WithCode(domain.CodeValidation, err))
+		return
+	}
+
+	sess, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	response, ok := h.execute(c, func(ctx context.Context, req *domain.ExecuteRequest) (*domain.ExecuteResponse, error) {
+		if req.Fixture != "" {
+			return nil, domain.NewError(domain.CodeValidation, "A fixture can only be applied when the session is created")
+		}
+		if req.Engine != "" && req.Engine != domain.EngineMySQL {
+			return nil, domain.NewError(domain.CodeValidation, "Sessions are only supported by the mysql engine")
+		}
+		if req.Backend != "" && req.Backend != sess.Backend {
+			return nil, domain.NewError(domain.CodeValidation, "A backend can only be selected when the session is created")
+		}
+		return h.sessions.Execute(ctx, sess, req)
+	})
+	if !ok {
+		return
+	}
+
+	if format != export.FormatJSON && response.Success {
+		h.writeExport(c, response, format, false)
+		return
+	}
+
+	c.JSON(resultStatus(response.Success, response.ErrorCode), response)
+}
+
+// SessionSchema handles GET /api/v1/sessions/:id/schema
+func (h *Handler) SessionSchema(c *gin.Context) {
+	sess, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	schema, err := h.sessions.Schema(c.Request.Context(), sess)
+	if err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			writeError(c, err)
+			return
+		}
+		h.respondError(c, fmt.Errorf("Failed to read schema: %w", err))
+		return
+	}
+
+	writeSchema(c, schema)
+}
+
+// ListFixtures handles GET /api/v1/fixtures
+func (h *Handler) ListFixtures(c *gin.Context) {
+	fixtures := h.backends.Fixtures().List()
+	if fixtures == nil {
+		fixtures = []*fixture.Fixture{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"fixtures": fixtures})
+}
+
+// FixtureSchema handles GET /api/v1/fixtures/:name/schema.
+// Fixtures do not change while the server runs, so the schema is built once
+// in a temporary sandbox and cached.
+func (h *Handler) FixtureSchema(c *gin.Context) {
+	name := c.Param("name")
+
+	if cached, ok := h.fixtureSchemas.Load(name); ok {
+		writeSchema(c, cached.(*domain.Schema))
+		return
+	}
+
+	if _, err := h.backends.Fixtures().Get(name); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	schema, err := h.backends.FixtureSchema(c.Request.Context(), name)
+	if err != nil {
+		h.respondError(c, fmt.Errorf("Failed to read schema: %w", err))
+		return
+	}
+
+	// The sandbox name is meaningless once the sandbox is dropped
+	schema.Database = ""
+	h.fixtureSchemas.Store(name, schema)
+
+	writeSchema(c, schema)
+}
+
+// lookupSession finds the session of the request for the calling principal.
+// It returns false when an error response has already been written.
+func (h *Handler) lookupSession(c *gin.Context) (*session.Session, bool) {
+	sess, err := h.sessions.Get(c.Param("id"), c.GetHeader(h.principalHeader))
+	if err != nil {
+		writeError(c, err)
+		return nil, false
+	}
+	return sess, true
+}
+
+// writeSchema writes the schema with its ETag, answering 304 Not Modified
+// when the client already has the current version
+func writeSchema(c *gin.Context, schema *domain.Schema) {
+	c.Header("ETag", schema.ETag)
+	c.Header("Cache-Control", "no-cache")
+
+	if c.GetHeader("If-None-Match") == schema.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
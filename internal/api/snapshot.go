@@ -0,0 +1,196 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/session"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unsafeFilenameChars are replaced in the file names of downloaded dumps
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CreateSnapshot handles POST /api/v1/sessions/:id/snapshots
+//
+// The tables with their rows, routines, views and triggers of the session
+// sandbox are saved as a SQL dump of at most snapshots.max_bytes.
+func (h *Handler) CreateSnapshot(c *gin.Context) {
+	if h.rejectDraining(c) {
+		return
+	}
+
+	sess, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateSnapshotRequest
+
+	// The body is optional
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeError(c, domain.Errorf(domain.CodeValidation, "Invalid request format: %w", err))
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	if err := h.scheduler.Acquire(ctx); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	dump, err := h.sessions.Dump(ctx, sess, h.config.Load().Snapshots.MaxBytes)
+	h.scheduler.Release()
+	if err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			writeError(c, err)
+			return
+		}
+		h.respondError(c, fmt.Errorf("Failed to create snapshot: %w", err))
+		return
+	}
+
+	info, err := h.snapshots.Add(domain.SnapshotInfo{
+		Name:      req.Name,
+		Owner:     sess.Owner,
+		SessionID: sess.ID,
+		Backend:   sess.Backend,
+		Tables:    dump.Tables,
+		Views:     dump.Views,
+		Routines:  dump.Routines,
+		Triggers:  dump.Triggers,
+		Rows:      dump.Rows,
+	}, dump.Script)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/snapshots/"+info.ID)
+	c.JSON(http.StatusCreated, info)
+}
+
+// ListSessionSnapshots handles GET /api/v1/sessions/:id/snapshots
+func (h *Handler) ListSessionSnapshots(c *gin.Context) {
+	sess, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	h.writeSnapshots(c, sess.Owner, sess.ID)
+}
+
+// ListSnapshots handles GET /api/v1/snapshots
+//
+// Snapshots outlive their sessions, so this lists every snapshot of the
+// caller, including those of closed sessions.
+func (h *Handler) ListSnapshots(c *gin.Context) {
+	h.writeSnapshots(c, c.GetHeader(h.principalHeader), "")
+}
+
+// writeSnapshots writes the snapshots of owner, optionally of one session
+func (h *Handler) writeSnapshots(c *gin.Context, owner string, sessionID string) {
+	snapshots, err := h.snapshots.List(owner, sessionID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"snapshots": snapshots})
+}
+
+// GetSnapshot handles GET /api/v1/snapshots/:id
+func (h *Handler) GetSnapshot(c *gin.Context) {
+	snapshot, err := h.snapshots.Get(c.Param("id"), c.GetHeader(h.principalHeader))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot.Info)
+}
+
+// DownloadSnapshot handles GET /api/v1/snapshots/:id/dump
+//
+// The dump is a plain SQL script that recreates the sandbox in any
+// database, e.g. with the mysql client.
+func (h *Handler) DownloadSnapshot(c *gin.Context) {
+	snapshot, err := h.snapshots.Get(c.Param("id"), c.GetHeader(h.principalHeader))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	name := snapshot.Info.ID
+	if safe := unsafeFilenameChars.ReplaceAllString(snapshot.Info.Name, "_"); safe != "" && safe != "_" {
+		name = safe
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="snapshot-%s.sql"`, name))
+	c.Data(http.StatusOK, "application/sql; charset=utf-8", []byte(snapshot.Script))
+}
+
+// DeleteSnapshot handles DELETE /api/v1/snapshots/:id
+func (h *Handler) DeleteSnapshot(c *gin.Context) {
+	if err := h.snapshots.Delete(c.Param("id"), c.GetHeader(h.principalHeader)); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreSnapshot handles POST /api/v1/sessions/:id/restore
+//
+// Everything in the session sandbox is replaced by the snapshot; an open
+// transaction is rolled back. A snapshot can be restored into any session of
+// its owner, e.g. a new one after the original session expired.
+func (h *Handler) RestoreSnapshot(c *gin.Context) {
+	if h.rejectDraining(c) {
+		return
+	}
+
+	sess, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	var req domain.RestoreSnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, domain.Errorf(domain.CodeValidation, "Invalid request format: %w", err))
+		return
+	}
+
+	snapshot, err := h.snapshots.Get(req.Snapshot, c.GetHeader(h.principalHeader))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	if err := h.restore(c, sess, snapshot.Script); err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			writeError(c, err)
+			return
+		}
+		h.respondError(c, fmt.Errorf("Failed to restore snapshot: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, h.sessions.Info(sess))
+}
+
+// restore restores a dump into a session while holding a scheduler slot
+func (h *Handler) restore(c *gin.Context, sess *session.Session, script string) error {
+	ctx := c.Request.Context()
+	if err := h.scheduler.Acquire(ctx); err != nil {
+		return err
+	}
+	defer h.scheduler.Release()
+
+	return h.sessions.Restore(ctx, sess, script)
+}
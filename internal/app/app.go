@@ -7,22 +7,42 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"mysql-tui-editor/server/internal/api"
+	"mysql-tui-editor/server/internal/audit"
 	"mysql-tui-editor/server/internal/config"
 	"mysql-tui-editor/server/internal/executor"
+	"mysql-tui-editor/server/internal/fixture"
+	"mysql-tui-editor/server/internal/jobs"
 	"mysql-tui-editor/server/internal/security"
+	"mysql-tui-editor/server/internal/session"
+	"mysql-tui-editor/server/internal/snapshot"
 
 	"github.com/gin-gonic/gin"
 )
 
 // App represents the application
 type App struct {
-	config    *config.Config
-	executor  *executor.MySQLExecutor
-	validator *security.Validator
-	handler   *api.Handler
-	server    *http.Server
+	config      *config.Config
+	configPath  string
+	backends    *executor.Backends
+	engines     *executor.Engines
+	validator   *security.Validator
+	audit       *audit.Logger
+	sessions    *session.Manager
+	jobs        *jobs.Manager
+	snapshots   *snapshot.Manager
+	handler     *api.Handler
+	rateLimiter *api.RateLimiter
+	server      *http.Server
+
+	// redirectServer redirects plain HTTP to HTTPS, if enabled
+	redirectServer *http.Server
+
+	// fatal receives errors that stop the server, e.g. MySQL staying
+	// unreachable at startup
+	fatal chan error
 }
 
 // New creates a new application instance
@@ -33,23 +53,79 @@ func New(configPath string) (*App, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Create MySQL executor
-	exec, err := executor.NewMySQLExecutor(cfg)
+	// Load fixtures
+	fixtures, err := fixture.NewStore(cfg.Fixtures.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fixtures: %w", err)
+	}
+
+	// Create MySQL executors
+	backends, err := executor.NewBackends(cfg, fixtures)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create MySQL executor: %w", err)
 	}
 
+	// Create executors of the other engines
+	engines, err := newEngines(cfg, backends)
+	if err != nil {
+		backends.Close()
+		return nil, fmt.Errorf("failed to create engines: %w", err)
+	}
+
 	// Create validator
 	validator := security.NewValidator()
+	if err := validator.SetBlockedCommands(cfg.Security.BlockedCommands); err != nil {
+		engines.Close()
+		return nil, err
+	}
+	backends.SetValidator(validator)
+
+	// Create audit logger
+	auditLogger, err := newAuditLogger(cfg)
+	if err != nil {
+		engines.Close()
+		return nil, fmt.Errorf("failed to create audit logger: %w", err)
+	}
+
+	// Create session manager
+	sessions := session.NewManager(backends, cfg.Sessions.IdleTimeout, cfg.Sessions.MaxSessions)
+
+	// Create job manager
+	jobManager, err := jobs.NewManager(cfg.Jobs)
+	if err != nil {
+		sessions.Close()
+		engines.Close()
+		return nil, fmt.Errorf("failed to create job manager: %w", err)
+	}
+
+	// Create snapshot manager
+	snapshots, err := snapshot.NewManager(cfg.Snapshots)
+	if err != nil {
+		jobManager.Close()
+		sessions.Close()
+		engines.Close()
+		return nil, fmt.Errorf("failed to create snapshot manager: %w", err)
+	}
+
+	rateLimiter := api.NewRateLimiter(cfg.Security.RateLimitPerSecond, cfg.Security.RateLimitBurst)
 
 	// Create handler
-	handler := api.NewHandler(exec, validator)
+	scheduler := executor.NewScheduler(cfg.Executor.MaxConcurrent)
+	handler := api.NewHandler(backends, engines, validator, auditLogger, sessions, jobManager, snapshots, scheduler, rateLimiter, cfg)
 
 	app := &App{
-		config:    cfg,
-		executor:  exec,
-		validator: validator,
-		handler:   handler,
+		config:      cfg,
+		configPath:  configPath,
+		backends:    backends,
+		engines:     engines,
+		validator:   validator,
+		audit:       auditLogger,
+		sessions:    sessions,
+		jobs:        jobManager,
+		snapshots:   snapshots,
+		handler:     handler,
+		rateLimiter: rateLimiter,
+		fatal:       make(chan error, 1),
 	}
 
 	return app, nil
@@ -64,29 +140,72 @@ func (a *App) Run() error {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	api.SetLogLevel(a.config.Logging.Level)
+
 	router := gin.New()
 
 	// Middleware
 	router.Use(api.RecoveryMiddleware())
+	router.Use(api.RequestIDMiddleware())
 	router.Use(api.LoggingMiddleware())
 	router.Use(api.CORSMiddleware())
 
 	// Rate limiting
-	rateLimiter := api.NewRateLimiter(
-		a.config.Security.RateLimitPerSecond,
-		a.config.Security.RateLimitBurst,
-	)
-	router.Use(rateLimiter.RateLimitMiddleware())
+	router.Use(a.rateLimiter.RateLimitMiddleware())
 
 	// Routes
 	v1 := router.Group("/api/v1")
 	{
 		v1.POST("/execute", a.handler.ExecuteQuery)
+		v1.POST("/export", a.handler.Export)
+		v1.POST("/explain", a.handler.Explain)
+		v1.POST("/batch", a.handler.Batch)
 		v1.GET("/health", a.handler.HealthCheck)
+
+		v1.POST("/sessions", a.handler.CreateSession)
+		v1.GET("/sessions/:id", a.handler.GetSession)
+		v1.DELETE("/sessions/:id", a.handler.DeleteSession)
+		v1.POST("/sessions/:id/execute", a.handler.ExecuteInSession)
+		v1.GET("/sessions/:id/schema", a.handler.SessionSchema)
+		v1.POST("/sessions/:id/snapshots", a.handler.CreateSnapshot)
+		v1.GET("/sessions/:id/snapshots", a.handler.ListSessionSnapshots)
+		v1.POST("/sessions/:id/restore", a.handler.RestoreSnapshot)
+
+		v1.GET("/snapshots", a.handler.ListSnapshots)
+		v1.GET("/snapshots/:id", a.handler.GetSnapshot)
+		v1.GET("/snapshots/:id/dump", a.handler.DownloadSnapshot)
+		v1.DELETE("/snapshots/:id", a.handler.DeleteSnapshot)
+
+		v1.POST("/jobs", a.handler.SubmitJob)
+		v1.GET("/jobs/:id", a.handler.GetJob)
+		v1.DELETE("/jobs/:id", a.handler.CancelJob)
+
+		v1.GET("/fixtures", a.handler.ListFixtures)
+		v1.GET("/fixtures/:name/schema", a.handler.FixtureSchema)
+	}
+
+	// Admin routes
+	admin := v1.Group("/admin", api.AdminAuthMiddleware(a.config.Admin.Token))
+	{
+		admin.GET("/audit", a.handler.AuditLog)
+		admin.GET("/sandboxes", a.handler.AdminSandboxes)
+		admin.GET("/sessions", a.handler.AdminSessions)
+		admin.DELETE("/sessions/:id", a.handler.AdminTerminateSession)
+		admin.GET("/orphans", a.handler.AdminOrphans)
+		admin.DELETE("/orphans", a.handler.AdminDropOrphans)
+		admin.GET("/drain", a.handler.DrainStatus)
+		admin.POST("/drain", a.handler.Drain)
+		admin.DELETE("/drain", a.handler.Resume)
+		admin.GET("/config", a.handler.AdminConfig)
 	}
 
 	// Root health check
 	router.GET("/health", a.handler.HealthCheck)
+	router.GET("/livez", a.handler.Livez)
+	router.GET("/readyz", a.handler.Readyz)
+
+	// Unknown routes answer with the error envelope
+	router.NoRoute(api.NotFound)
 
 	// Create HTTP server
 	a.server = &http.Server{
@@ -96,28 +215,98 @@ func (a *App) Run() error {
 		WriteTimeout: a.config.Server.WriteTimeout,
 	}
 
+	tlsCfg := a.config.Server.TLS
+	if tlsCfg.Enabled {
+		tlsConfig, err := newTLSConfig(tlsCfg)
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		a.server.TLSConfig = tlsConfig
+
+		if tlsCfg.RedirectPort != 0 {
+			a.redirectServer = &http.Server{
+				Addr:         fmt.Sprintf(":%d", tlsCfg.RedirectPort),
+				Handler:      redirectHandler(a.config.Server.Port),
+				ReadTimeout:  a.config.Server.ReadTimeout,
+				WriteTimeout: a.config.Server.WriteTimeout,
+			}
+		}
+	}
+
 	// Start server in goroutine
 	go func() {
-		fmt.Printf("🚀 MySQL TUI Server starting on port %d...\n", a.config.Server.Port)
-		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsCfg.Enabled {
+			fmt.Printf("🚀 MySQL TUI Server starting on port %d (HTTPS)...\n", a.config.Server.Port)
+			err = a.server.ListenAndServeTLS("", "")
+		} else {
+			fmt.Printf("🚀 MySQL TUI Server starting on port %d...\n", a.config.Server.Port)
+			err = a.server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			fmt.Printf("❌ Server error: %v\n", err)
 		}
 	}()
 
+	if a.redirectServer != nil {
+		go func() {
+			fmt.Printf("↪️  Redirecting HTTP on port %d to HTTPS\n", tlsCfg.RedirectPort)
+			if err := a.redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Printf("❌ Redirect server error: %v\n", err)
+			}
+		}()
+	}
+
+	// Wait for MySQL; the server reports not ready meanwhile
+	startupCtx, cancelStartup := context.WithCancel(context.Background())
+	defer cancelStartup()
+	go a.waitForMySQL(startupCtx)
+
+	// Reload configuration on SIGHUP and file changes
+	stopReload := make(chan struct{})
+	go a.watchConfig(stopReload)
+
 	// Wait for interrupt signal
-	a.waitForShutdown()
+	err := a.waitForShutdown()
+	close(stopReload)
 
-	return nil
+	return err
 }
 
-// waitForShutdown waits for interrupt signal and performs graceful shutdown
-func (a *App) waitForShutdown() {
+// waitForMySQL retries unreachable MySQL backends at startup and stops the
+// server when they stay unreachable longer than startup.max_wait
+func (a *App) waitForMySQL(ctx context.Context) {
+	if err := a.backends.WaitReady(ctx, a.config.Startup); err != nil {
+		if ctx.Err() == nil {
+			a.fatal <- err
+		}
+		return
+	}
+	fmt.Println("✅ MySQL is reachable")
+}
+
+// waitForShutdown waits for interrupt signal or a fatal error and performs
+// graceful shutdown. It returns the fatal error, if any.
+func (a *App) waitForShutdown() error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+
+	var fatalErr error
+	select {
+	case <-quit:
+	case fatalErr = <-a.fatal:
+		fmt.Printf("❌ %v\n", fatalErr)
+	}
 
 	fmt.Println("\n🛑 Shutting down server...")
 
+	// Fail readiness first, so that load balancers stop sending requests
+	a.handler.ShutDown()
+	if delay := a.config.Server.ShutdownDelay; delay > 0 {
+		fmt.Printf("⏳ Waiting %s before closing listeners...\n", delay)
+		time.Sleep(delay)
+	}
+
 	// Create shutdown context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), a.config.Server.ShutdownTimeout)
 	defer cancel()
@@ -126,19 +315,103 @@ func (a *App) waitForShutdown() {
 	if err := a.server.Shutdown(ctx); err != nil {
 		fmt.Printf("❌ Server forced to shutdown: %v\n", err)
 	}
+	if a.redirectServer != nil {
+		a.redirectServer.Shutdown(ctx)
+	}
+
+	// Cancel unfinished jobs, so that their sandboxes are dropped
+	a.jobs.Close()
+
+	// Drop session sandboxes
+	a.sessions.Close()
+	a.snapshots.Close()
+
+	// Flush audit log
+	if err := a.audit.Close(); err != nil {
+		fmt.Printf("❌ Error closing audit log: %v\n", err)
+	}
 
-	// Close MySQL connection
-	if err := a.executor.Close(); err != nil {
-		fmt.Printf("❌ Error closing MySQL connection: %v\n", err)
+	// Close database connections
+	if err := a.engines.Close(); err != nil {
+		fmt.Printf("❌ Error closing database connections: %v\n", err)
 	}
 
 	fmt.Println("✅ Server exited cleanly")
+	return fatalErr
 }
 
 // Close closes all resources
 func (a *App) Close() error {
-	if a.executor != nil {
-		return a.executor.Close()
+	if a.engines != nil {
+		return a.engines.Close()
 	}
 	return nil
 }
+
+// newEngines creates the executors of the enabled engines. MySQL is always
+// available.
+func newEngines(cfg *config.Config, backends *executor.Backends) (*executor.Engines, error) {
+	executors := []executor.Executor{backends}
+	valueOptions := backends.Default().ValueOptions()
+
+	if cfg.Engines.SQLite.Enabled {
+		executors = append(executors, executor.NewProviderExecutor(executor.NewSQLiteProvider(), cfg.Executor.QueryTimeout, valueOptions))
+	}
+
+	if cfg.Engines.Postgres.Enabled {
+		provider, err := executor.NewPostgresProvider(cfg.Engines.Postgres)
+		if err != nil {
+			return nil, err
+		}
+		executors = append(executors, executor.NewProviderExecutor(provider, cfg.Executor.QueryTimeout, valueOptions))
+	}
+
+	return executor.NewEngines(executors...), nil
+}
+
+// newAuditLogger creates the audit logger with the configured sinks.
+// It returns nil when auditing is disabled.
+func newAuditLogger(cfg *config.Config) (*audit.Logger, error) {
+	if !cfg.Audit.Enabled {
+		return nil, nil
+	}
+
+	var sinks []audit.Sink
+
+	if cfg.Audit.File.Enabled {
+		fileSink, err := audit.NewFileSink(cfg.Audit.File.Path, cfg.Audit.File.MaxSizeMB, cfg.Audit.File.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+	}
+
+	if cfg.Audit.MySQL.Enabled {
+		mysqlSink, err := newAuditMySQLSink(cfg)
+		if err != nil {
+			for _, sink := range sinks {
+				sink.Close()
+			}
+			return nil, err
+		}
+		sinks = append(sinks, mysqlSink)
+	}
+
+	return audit.NewLogger(cfg.Audit.StoreQuery, sinks...), nil
+}
+
+// newAuditMySQLSink opens the audit account's own connection pool, which
+// sandboxes cannot use, and creates the MySQL sink on it
+func newAuditMySQLSink(cfg *config.Config) (*audit.MySQLSink, error) {
+	db, err := executor.OpenDB(cfg.AuditBackend())
+	if err != nil {
+		return nil, err
+	}
+
+	sink, err := audit.NewMySQLSink(db, cfg.Audit.MySQL.Database, cfg.Audit.MySQL.Table)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return sink, nil
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"mysql-tui-editor/server/internal/api"
+	"mysql-tui-editor/server/internal/config"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce groups the file events of one save into a single reload
+const reloadDebounce = 500 * time.Millisecond
+
+// watchConfig reloads the configuration on SIGHUP and when the config file
+// changes, until stop is closed
+func (a *App) watchConfig(stop <-chan struct{}) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// The directory is watched, so that files replaced by editors or
+	// Kubernetes ConfigMap updates are noticed
+	var events chan fsnotify.Event
+	var watchErrors chan error
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		err = watcher.Add(filepath.Dir(a.configPath))
+	}
+	if err != nil {
+		fmt.Printf("WARNING: Config file is not watched, reload with SIGHUP: %v\n", err)
+	} else {
+		defer watcher.Close()
+		events = watcher.Events
+		watchErrors = watcher.Errors
+	}
+
+	current := a.config
+	configFile := filepath.Clean(a.configPath)
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-hup:
+			current = a.reload(current)
+		case event := <-events:
+			name := filepath.Base(event.Name)
+			if filepath.Clean(event.Name) == configFile || name == "..data" {
+				debounce.Reset(reloadDebounce)
+			}
+		case err := <-watchErrors:
+			// The watcher blocks until its errors are read
+			fmt.Printf("WARNING: Config file watcher error: %v\n", err)
+		case <-debounce.C:
+			current = a.reload(current)
+		}
+	}
+}
+
+// reload reads and validates the config file and applies its reloadable
+// settings. Settings that require a restart keep their running value. The
+// applied configuration is returned; on error it is current.
+func (a *App) reload(current *config.Config) *config.Config {
+	next, err := config.Load(a.configPath)
+	if err != nil {
+		fmt.Printf("WARNING: Configuration not reloaded: %v\n", err)
+		return current
+	}
+
+	merged, restart := current.Reload(next)
+	for _, key := range restart {
+		fmt.Printf("WARNING: %s changed but requires a restart, keeping the running value\n", key)
+	}
+
+	// Patterns were checked by validation
+	if err := a.validator.SetBlockedCommands(merged.Security.BlockedCommands); err != nil {
+		fmt.Printf("WARNING: Configuration not reloaded: %v\n", err)
+		return current
+	}
+	a.rateLimiter.SetLimits(merged.Security.RateLimitPerSecond, merged.Security.RateLimitBurst)
+	a.engines.Reload(merged)
+	api.SetLogLevel(merged.Logging.Level)
+	a.handler.SetConfig(merged)
+
+	fmt.Println("🔄 Configuration reloaded")
+	return merged
+}
@@ -0,0 +1,169 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// Validator verdicts recorded in the audit log
+const (
+	VerdictAllowed  = "allowed"
+	VerdictRejected = "rejected"
+)
+
+// Query storage modes
+const (
+	StoreQueryFull = "full"
+	StoreQueryHash = "hash"
+)
+
+// ErrQueryNotSupported is returned when no configured sink can be queried
+var ErrQueryNotSupported = domain.NewError(domain.CodeNotImplemented, "no queryable audit sink configured")
+
+// Record is a single audit log entry describing one execution request
+type Record struct {
+	Time          time.Time                `json:"time"`
+	RequestID     string                   `json:"request_id"`
+	Principal     string                   `json:"principal"`
+	ClientIP      string                   `json:"client_ip"`
+	Query         string                   `json:"query,omitempty"`
+	QueryHash     string                   `json:"query_hash"`
+	Verdict       string                   `json:"verdict"`
+	VerdictReason string                   `json:"verdict_reason,omitempty"`
+	Success       bool                     `json:"success"`
+	Error         string                   `json:"error,omitempty"`
+	Statements    []domain.StatementResult `json:"statements,omitempty"`
+	DurationMs    int64                    `json:"duration_ms"`
+	Sandbox       string                   `json:"sandbox,omitempty"`
+}
+
+// Filter selects audit records by principal and time range
+type Filter struct {
+	Principal string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+// Match reports whether the record satisfies the filter
+func (f Filter) Match(rec *Record) bool {
+	if f.Principal != "" && rec.Principal != f.Principal {
+		return false
+	}
+	if !f.From.IsZero() && rec.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && rec.Time.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// Sink persists audit records. Write is called concurrently.
+type Sink interface {
+	Write(rec *Record) error
+	Close() error
+}
+
+// Querier is implemented by sinks that can search stored records
+type Querier interface {
+	Query(filter Filter) ([]Record, error)
+}
+
+// Logger fans audit records out to all configured sinks
+type Logger struct {
+	sinks      []Sink
+	storeQuery string
+
+	// mu is held for reading by writes, which do not wait for each other,
+	// and for writing by Close
+	mu sync.RWMutex
+}
+
+// NewLogger creates a new audit logger writing to the given sinks
+func NewLogger(storeQuery string, sinks ...Sink) *Logger {
+	return &Logger{
+		sinks:      sinks,
+		storeQuery: storeQuery,
+	}
+}
+
+// Log writes the record to every sink. The query text is always hashed and
+// is dropped from the record when only hashes should be stored.
+func (l *Logger) Log(rec *Record) {
+	if l == nil {
+		return
+	}
+
+	if rec.Time.IsZero() {
+		rec.Time = time.Now().UTC()
+	}
+	rec.QueryHash = HashQuery(rec.Query)
+	if l.storeQuery == StoreQueryHash {
+		rec.Query = ""
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, sink := range l.sinks {
+		if err := sink.Write(rec); err != nil {
+			// Auditing must never break query execution
+			fmt.Printf("WARNING: Failed to write audit record %s: %v\n", rec.RequestID, err)
+		}
+	}
+}
+
+// Query searches the first queryable sink
+func (l *Logger) Query(filter Filter) ([]Record, error) {
+	if l == nil {
+		return nil, ErrQueryNotSupported
+	}
+
+	for _, sink := range l.sinks {
+		if q, ok := sink.(Querier); ok {
+			return q.Query(filter)
+		}
+	}
+
+	return nil, ErrQueryNotSupported
+}
+
+// Close closes all sinks
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// HashQuery returns the hex-encoded SHA-256 hash of the query text
+func HashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// limitRecords keeps only the newest records when a limit is set
+func limitRecords(records []Record, limit int) []Record {
+	if limit > 0 && len(records) > limit {
+		return records[len(records)-limit:]
+	}
+	return records
+}
@@ -0,0 +1,199 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"mysql-tui-editor/server/internal/logging"
+)
+
+// FileSink appends audit records as JSON lines to a file and rotates it
+// once it grows beyond the configured size
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	// rotation is held for reading by queries, so that files are not
+	// renamed while they are read, and for writing by rotate. Writes that
+	// do not rotate do not wait for queries.
+	rotation sync.RWMutex
+}
+
+// NewFileSink opens (or creates) the audit log file
+func NewFileSink(path string, maxSizeMB int, maxBackups int) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	sink := &FileSink{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+// open opens the current log file in append-only mode
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %w", s.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit log %s: %w", s.path, err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// Write appends a record to the log, rotating the file first if needed
+func (s *FileSink) Write(rec *Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		// Records are appended to the current file until rotation succeeds
+		if err := s.rotate(); err != nil {
+			logging.Warnf("%v", err)
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+
+	return nil
+}
+
+// rotate shifts path.N-1 to path.N, moves the current file to path.1 and
+// starts a new file. The oldest backup beyond maxBackups is removed. The
+// current file is closed only once the new one is open.
+func (s *FileSink) rotate() error {
+	s.rotation.Lock()
+	defer s.rotation.Unlock()
+
+	if s.maxBackups > 0 {
+		os.Remove(s.backupPath(s.maxBackups))
+		for i := s.maxBackups - 1; i >= 1; i-- {
+			os.Rename(s.backupPath(i), s.backupPath(i+1))
+		}
+		if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	previous := s.file
+	if err := s.open(); err != nil {
+		return err
+	}
+	if err := previous.Close(); err != nil {
+		return fmt.Errorf("failed to close rotated audit log: %w", err)
+	}
+	return nil
+}
+
+// backupPath returns the path of the n-th rotated file
+func (s *FileSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
+
+// Query reads the current and rotated files, oldest first, and returns
+// the matching records in chronological order. Records written during the
+// query may be missing from the result.
+func (s *FileSink) Query(filter Filter) ([]Record, error) {
+	s.rotation.RLock()
+	defer s.rotation.RUnlock()
+
+	paths := make([]string, 0, s.maxBackups+1)
+	for i := s.maxBackups; i >= 1; i-- {
+		paths = append(paths, s.backupPath(i))
+	}
+	paths = append(paths, s.path)
+
+	var records []Record
+	for _, path := range paths {
+		found, err := readRecords(path, filter)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, found...)
+	}
+
+	return limitRecords(records, filter.Limit), nil
+}
+
+// readRecords reads matching records from a single JSONL file
+func readRecords(path string, filter Filter) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// Skip partially written lines
+			continue
+		}
+		if filter.Match(&rec) {
+			records = append(records, rec)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log %s: %w", path, err)
+	}
+
+	return records, nil
+}
+
+// Close closes the log file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
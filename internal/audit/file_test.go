@@ -0,0 +1,211 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSink_WriteAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	defer sink.Close()
+
+	logger := NewLogger(StoreQueryFull, sink)
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	logger.Log(&Record{Time: base, Principal: "alice", Query: "SELECT 1", Verdict: VerdictAllowed})
+	logger.Log(&Record{Time: base.Add(time.Hour), Principal: "bob", Query: "SELECT 2", Verdict: VerdictAllowed})
+	logger.Log(&Record{Time: base.Add(2 * time.Hour), Principal: "alice", Query: "DROP DATABASE x", Verdict: VerdictRejected})
+
+	records, err := logger.Query(Filter{Principal: "alice"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records for alice, got %d", len(records))
+	}
+	if records[1].Verdict != VerdictRejected {
+		t.Errorf("Expected records in chronological order, got %+v", records)
+	}
+
+	records, err = logger.Query(Filter{From: base.Add(30 * time.Minute), To: base.Add(90 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Principal != "bob" {
+		t.Errorf("Expected only bob's record in time range, got %+v", records)
+	}
+
+	records, _ = logger.Query(Filter{Limit: 1})
+	if len(records) != 1 || records[0].Query != "DROP DATABASE x" {
+		t.Errorf("Expected limit to keep the newest record, got %+v", records)
+	}
+}
+
+func TestFileSink_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 1, 2)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	defer sink.Close()
+
+	// Each record is roughly 300KB, so every write after the third rotates
+	query := strings.Repeat("x", 300*1024)
+	for i := 0; i < 10; i++ {
+		if err := sink.Write(&Record{Time: time.Now(), Query: query}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("Expected %s to exist: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected backups beyond max_backups to be removed")
+	}
+
+	records, err := sink.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) == 0 || len(records) >= 10 {
+		t.Errorf("Expected only records from retained files, got %d", len(records))
+	}
+}
+
+func TestLogger_StoreQueryHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 10, 1)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	defer sink.Close()
+
+	logger := NewLogger(StoreQueryHash, sink)
+	logger.Log(&Record{Query: "SELECT secret"})
+
+	records, _ := logger.Query(Filter{})
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if records[0].Query != "" {
+		t.Errorf("Expected query text to be dropped, got %q", records[0].Query)
+	}
+	if records[0].QueryHash != HashQuery("SELECT secret") {
+		t.Errorf("Expected query hash to be stored")
+	}
+}
+
+func TestFileSink_WriteDuringQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	defer sink.Close()
+
+	// A query in progress holds the rotation lock for reading
+	sink.rotation.RLock()
+	defer sink.rotation.RUnlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sink.Write(&Record{Principal: "alice", Query: "SELECT 1"})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Write failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Write waited for the query")
+	}
+}
+
+// blockingSink blocks writes of the principal "slow" until release is closed
+type blockingSink struct {
+	release chan struct{}
+}
+
+func (s *blockingSink) Write(rec *Record) error {
+	if rec.Principal == "slow" {
+		<-s.release
+	}
+	return nil
+}
+
+func (s *blockingSink) Close() error { return nil }
+
+func TestLogger_SlowSinkDoesNotBlockOtherWrites(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	logger := NewLogger(StoreQueryFull, sink)
+	defer close(sink.release)
+
+	go logger.Log(&Record{Principal: "slow"})
+	time.Sleep(10 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		logger.Log(&Record{Principal: "alice"})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Log waited for the write of another record")
+	}
+}
+
+func TestFileSink_RotationFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	defer sink.Close()
+
+	// A non-empty directory in place of the backup makes the rename fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocker"), 0o750); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	query := strings.Repeat("x", 300*1024)
+	for i := 0; i < 5; i++ {
+		if err := sink.Write(&Record{Time: time.Now(), Query: query}); err != nil {
+			t.Fatalf("Write %d failed: %v", i, err)
+		}
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() < 5*300*1024 {
+		t.Fatalf("Expected all records in the current file, got %v, %v", info, err)
+	}
+
+	// Rotation resumes once the backup path is free again
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatalf("Failed to remove directory: %v", err)
+	}
+	if err := sink.Write(&Record{Time: time.Now(), Principal: "alice"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	records, err := sink.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 6 || records[5].Principal != "alice" {
+		t.Errorf("Expected all 6 records after rotation, got %d", len(records))
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() > 1024 {
+		t.Errorf("Expected a new current file after rotation, got %v, %v", info, err)
+	}
+}
@@ -0,0 +1,208 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MySQLSink stores audit records in a MySQL table. It owns its connection
+// pool, which uses an account separate from the sandboxes.
+type MySQLSink struct {
+	db       *sql.DB
+	database string
+	table    string
+
+	// ready is set once the database and table exist
+	mu    sync.Mutex
+	ready bool
+}
+
+// NewMySQLSink creates the audit database and table if they do not exist.
+// While MySQL is unreachable they are created on first use instead. The
+// connections must use parseTime with the UTC location.
+func NewMySQLSink(db *sql.DB, database string, table string) (*MySQLSink, error) {
+	sink := &MySQLSink{
+		db:       db,
+		database: database,
+		table:    fmt.Sprintf("`%s`.`%s`", database, table),
+	}
+
+	if err := sink.ensureTable(); err != nil {
+		// Errors of a reachable server, e.g. missing privileges, are fatal
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if db.PingContext(ctx) == nil {
+			return nil, err
+		}
+		fmt.Printf("WARNING: Audit table will be created once MySQL is reachable: %v\n", err)
+	}
+
+	return sink, nil
+}
+
+// ensureTable creates the audit database and table once
+func (s *MySQLSink) ensureTable() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ready {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", s.database)); err != nil {
+		return fmt.Errorf("failed to create audit database %s: %w", s.database, err)
+	}
+
+	createTable := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		created_at DATETIME(3) NOT NULL,
+		request_id VARCHAR(64) NOT NULL,
+		principal VARCHAR(255) NOT NULL,
+		client_ip VARCHAR(64) NOT NULL,
+		query MEDIUMTEXT NULL,
+		query_hash CHAR(64) NOT NULL,
+		verdict VARCHAR(16) NOT NULL,
+		verdict_reason TEXT NULL,
+		success BOOLEAN NOT NULL,
+		error TEXT NULL,
+		statements JSON NULL,
+		duration_ms BIGINT NOT NULL,
+		sandbox VARCHAR(64) NOT NULL,
+		INDEX idx_principal_time (principal, created_at),
+		INDEX idx_time (created_at)
+	)`, s.table)
+
+	if _, err := s.db.ExecContext(ctx, createTable); err != nil {
+		return fmt.Errorf("failed to create audit table %s: %w", s.table, err)
+	}
+
+	s.ready = true
+	return nil
+}
+
+// Write inserts a record into the audit table
+func (s *MySQLSink) Write(rec *Record) error {
+	if err := s.ensureTable(); err != nil {
+		return err
+	}
+
+	statements, err := json.Marshal(rec.Statements)
+	if err != nil {
+		return fmt.Errorf("failed to encode statements: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := fmt.Sprintf(`INSERT INTO %s
+		(created_at, request_id, principal, client_ip, query, query_hash, verdict,
+		 verdict_reason, success, error, statements, duration_ms, sandbox)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, s.table)
+
+	_, err = s.db.ExecContext(ctx, query,
+		rec.Time.UTC(), rec.RequestID, rec.Principal, rec.ClientIP,
+		nullString(rec.Query), rec.QueryHash, rec.Verdict,
+		nullString(rec.VerdictReason), rec.Success, nullString(rec.Error),
+		string(statements), rec.DurationMs, rec.Sandbox,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit record: %w", err)
+	}
+
+	return nil
+}
+
+// Query returns matching records in chronological order
+func (s *MySQLSink) Query(filter Filter) ([]Record, error) {
+	if err := s.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var conditions []string
+	var args []interface{}
+
+	if filter.Principal != "" {
+		conditions = append(conditions, "principal = ?")
+		args = append(args, filter.Principal)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, filter.To.UTC())
+	}
+
+	query := fmt.Sprintf(`SELECT created_at, request_id, principal, client_ip, query, query_hash,
+		verdict, verdict_reason, success, error, statements, duration_ms, sandbox
+		FROM %s`, s.table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit table: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var rec Record
+		var createdAt time.Time
+		var queryText, reason, errText, statements sql.NullString
+
+		if err := rows.Scan(&createdAt, &rec.RequestID, &rec.Principal, &rec.ClientIP,
+			&queryText, &rec.QueryHash, &rec.Verdict, &reason, &rec.Success, &errText,
+			&statements, &rec.DurationMs, &rec.Sandbox); err != nil {
+			return nil, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+
+		rec.Time = createdAt.UTC()
+		rec.Query = queryText.String
+		rec.VerdictReason = reason.String
+		rec.Error = errText.String
+		if statements.Valid {
+			json.Unmarshal([]byte(statements.String), &rec.Statements)
+		}
+
+		records = append(records, rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit records: %w", err)
+	}
+
+	// Rows were fetched newest first so that LIMIT keeps the latest ones
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+
+	return records, nil
+}
+
+// Close closes the connection pool
+func (s *MySQLSink) Close() error {
+	return s.db.Close()
+}
+
+// nullString converts empty strings to NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
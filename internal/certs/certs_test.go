@@ -0,0 +1,72 @@
+package certs
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePair(t *testing.T, dir string, host string) (string, string) {
+	t.Helper()
+	certPEM, keyPEM, err := GenerateSelfSigned([]string{host, "127.0.0.1"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writePair(t, dir, "first.local")
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commonName := func() string {
+		cert, err := r.GetCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+
+	if name := commonName(); name != "first.local" {
+		t.Fatalf("Expected first.local, got %s", name)
+	}
+
+	// Replace the pair and let the check interval pass
+	writePair(t, dir, "second.local")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	r.lastCheck = time.Time{}
+
+	if name := commonName(); name != "second.local" {
+		t.Errorf("Expected reloaded second.local, got %s", name)
+	}
+
+	// A broken file keeps the current certificate
+	os.WriteFile(keyFile, []byte("broken"), 0o600)
+	os.Chtimes(keyFile, later.Add(time.Minute), later.Add(time.Minute))
+	r.lastCheck = time.Time{}
+
+	if name := commonName(); name != "second.local" {
+		t.Errorf("Expected second.local after broken renewal, got %s", name)
+	}
+}
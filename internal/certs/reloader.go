@@ -0,0 +1,100 @@
+package certs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkInterval limits how often the certificate files are checked for
+// changes
+const checkInterval = 5 * time.Second
+
+// Reloader serves a certificate and key pair from files and loads them again
+// when they change, e.g. after a renewal. Files are checked lazily during
+// handshakes, so no goroutine is needed.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.Mutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	lastCheck   time.Time
+}
+
+// NewReloader loads the certificate and key pair
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate. It is meant for
+// tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) >= checkInterval {
+		r.lastCheck = time.Now()
+		if r.changed() {
+			// A broken renewal keeps the previous certificate in use
+			if err := r.loadLocked(); err != nil {
+				fmt.Printf("WARNING: Failed to reload TLS certificate: %v\n", err)
+			} else {
+				fmt.Printf("TLS certificate reloaded from %s\n", r.certFile)
+			}
+		}
+	}
+
+	return r.cert, nil
+}
+
+// load loads the certificate and key pair
+func (r *Reloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.loadLocked()
+}
+
+// loadLocked loads the certificate and key pair; r.mu must be held
+func (r *Reloader) loadLocked() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.cert = &cert
+	r.certModTime = certInfo.ModTime()
+	r.keyModTime = keyInfo.ModTime()
+	r.lastCheck = time.Now()
+	return nil
+}
+
+// changed reports whether a file was modified since it was loaded
+func (r *Reloader) changed() bool {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return false
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return false
+	}
+	return !certInfo.ModTime().Equal(r.certModTime) || !keyInfo.ModTime().Equal(r.keyModTime)
+}
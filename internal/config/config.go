@@ -1,6 +1,10 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -8,11 +12,23 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	MySQL    MySQLConfig    `mapstructure:"mysql"`
-	Executor ExecutorConfig `mapstructure:"executor"`
-	Security SecurityConfig `mapstructure:"security"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
+	Server    ServerConfig    `mapstructure:"server"`
+	MySQL     MySQLConfig     `mapstructure:"mysql"`
+	Backends  []BackendConfig `mapstructure:"backends"`
+	Executor  ExecutorConfig  `mapstructure:"executor"`
+	Security  SecurityConfig  `mapstructure:"security"`
+	Logging   LoggingConfig   `mapstructure:"logging"`
+	Audit     AuditConfig     `mapstructure:"audit"`
+	Admin     AdminConfig     `mapstructure:"admin"`
+	Sessions  SessionsConfig  `mapstructure:"sessions"`
+	Snapshots SnapshotsConfig `mapstructure:"snapshots"`
+	Fixtures  FixturesConfig  `mapstructure:"fixtures"`
+	Engines   EnginesConfig   `mapstructure:"engines"`
+	Quota     QuotaConfig     `mapstructure:"quota"`
+	Readiness ReadinessConfig `mapstructure:"readiness"`
+	Startup   StartupConfig   `mapstructure:"startup"`
+	Batch     BatchConfig     `mapstructure:"batch"`
+	Jobs      JobsConfig      `mapstructure:"jobs"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -21,29 +37,228 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+
+	// ShutdownDelay keeps serving with failing readiness before the server
+	// stops accepting connections, so that load balancers can react
+	ShutdownDelay time.Duration `mapstructure:"shutdown_delay"`
+
+	TLS TLSConfig `mapstructure:"tls"`
+}
+
+// TLSConfig holds HTTPS configuration
+type TLSConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+
+	// MinVersion is "1.2" or "1.3"
+	MinVersion string `mapstructure:"min_version"`
+
+	// ClientCAFile enables mutual TLS: clients must present a certificate
+	// signed by one of these CAs
+	ClientCAFile string `mapstructure:"client_ca_file"`
+
+	// RedirectPort serves a plain HTTP listener that redirects to HTTPS,
+	// 0 = disabled
+	RedirectPort int `mapstructure:"redirect_port"`
 }
 
 // MySQLConfig holds MySQL connection configuration
 type MySQLConfig struct {
 	Host            string        `mapstructure:"host"`
 	Port            int           `mapstructure:"port"`
+	Socket          string        `mapstructure:"socket"`
 	User            string        `mapstructure:"user"`
 	Password        string        `mapstructure:"password"`
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+
+	MySQLOptions `mapstructure:",squash"`
+}
+
+// MySQLOptions holds the driver options of MySQL connections. They are
+// shared by all backends.
+type MySQLOptions struct {
+	Charset   string `mapstructure:"charset"`
+	Collation string `mapstructure:"collation"`
+
+	// ParseTime returns DATE and DATETIME values as time.Time in loc
+	ParseTime bool   `mapstructure:"parse_time"`
+	Loc       string `mapstructure:"loc"`
+
+	// Timeout bounds dialing, ReadTimeout and WriteTimeout bound network
+	// I/O, 0 = driver default
+	Timeout      time.Duration `mapstructure:"timeout"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+
+	InterpolateParams    bool              `mapstructure:"interpolate_params"`
+	ConnectionAttributes map[string]string `mapstructure:"connection_attributes"`
+
+	TLS MySQLTLSConfig `mapstructure:"tls"`
+}
+
+// MySQLTLSConfig holds TLS configuration of MySQL connections
+type MySQLTLSConfig struct {
+	// Mode is disabled, preferred, required, verify_ca or verify_identity,
+	// like the --ssl-mode option of the mysql client
+	Mode string `mapstructure:"mode"`
+
+	// CAFile verifies the server, default system roots
+	CAFile string `mapstructure:"ca_file"`
+
+	// CertFile and KeyFile are the client certificate, if the server
+	// requires one
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+
+	// ServerName overrides the host name checked by verify_identity
+	ServerName string `mapstructure:"server_name"`
+}
+
+// BackendConfig holds the configuration of a named MySQL backend, e.g. one
+// per server version. Sandboxes are spread across its replicas, which must
+// run the same version.
+type BackendConfig struct {
+	Name            string          `mapstructure:"name"`
+	User            string          `mapstructure:"user"`
+	Password        string          `mapstructure:"password"`
+	DBPrefix        string          `mapstructure:"db_prefix"`
+	MaxOpenConns    int             `mapstructure:"max_open_conns"`
+	MaxIdleConns    int             `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration   `mapstructure:"conn_max_lifetime"`
+	Replicas        []ReplicaConfig `mapstructure:"replicas"`
+
+	// Options are taken from the mysql section
+	Options MySQLOptions `mapstructure:"-"`
+}
+
+// ReplicaConfig holds the address of one server of a backend. A unix
+// socket is used instead of host and port when set.
+type ReplicaConfig struct {
+	Host   string `mapstructure:"host"`
+	Port   int    `mapstructure:"port"`
+	Socket string `mapstructure:"socket"`
 }
 
 // ExecutorConfig holds query execution configuration
 type ExecutorConfig struct {
+	QueryTimeout        time.Duration `mapstructure:"query_timeout"`
+	DBPrefix            string        `mapstructure:"db_prefix"`
+	JSONStyle           string        `mapstructure:"json_style"`
+	MaxValueLength      int           `mapstructure:"max_value_length"`
+	DefaultBackend      string        `mapstructure:"default_backend"`
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
+
+	// BreakerThreshold is the number of consecutive connection failures
+	// after which a backend fails fast for BreakerCooldown, 0 = disabled
+	BreakerThreshold int           `mapstructure:"breaker_threshold"`
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown"`
+
+	// MaxConcurrent caps the executions running at once across the server;
+	// further executions wait for a slot, 0 = unlimited
+	MaxConcurrent int `mapstructure:"max_concurrent"`
+
+	// InstanceID is added to the sandbox names of this server, so that
+	// servers sharing MySQL only clean up their own orphans. It must be
+	// unique among them and stable across restarts.
+	InstanceID string `mapstructure:"instance_id"`
+}
+
+// BatchConfig holds the limits of batch executions
+type BatchConfig struct {
+	// MaxItems caps the number of scripts of a batch
+	MaxItems int `mapstructure:"max_items"`
+
+	// MaxParallel is the number of items of a batch executed at once
+	MaxParallel int `mapstructure:"max_parallel"`
+
+	// ItemCost is the rate limit cost of an item; a batch costs the
+	// rounded-up sum, at least 1 and at most the rate limit burst
+	ItemCost float64 `mapstructure:"item_cost"`
+}
+
+// JobsConfig holds asynchronous job execution
+type JobsConfig struct {
+	// Workers is the number of jobs executed at once
+	Workers int `mapstructure:"workers"`
+
+	// QueueSize caps the jobs waiting for a worker
+	QueueSize int `mapstructure:"queue_size"`
+
+	// QueryTimeout replaces executor.query_timeout for jobs, 0 = the same
 	QueryTimeout time.Duration `mapstructure:"query_timeout"`
-	DBPrefix     string        `mapstructure:"db_prefix"`
+
+	// ResultTTL is how long finished jobs are kept
+	ResultTTL time.Duration `mapstructure:"result_ttl"`
+
+	// Store keeps jobs in "memory" or on "disk" in Dir, where finished jobs
+	// survive a restart
+	Store string `mapstructure:"store"`
+	Dir   string `mapstructure:"dir"`
+
+	// CallbackURL receives finished jobs that asked for a callback,
+	// empty = callbacks disabled
+	CallbackURL string `mapstructure:"callback_url"`
+
+	// CallbackToken signs callbacks with HMAC-SHA256 in X-Signature
+	CallbackToken   string        `mapstructure:"callback_token"`
+	CallbackTimeout time.Duration `mapstructure:"callback_timeout"`
+}
+
+// StartupConfig holds how long the server waits for MySQL at startup. The
+// HTTP server runs meanwhile and reports not ready.
+type StartupConfig struct {
+	// MaxWait is how long unreachable backends are retried before the
+	// server exits, 0 = forever
+	MaxWait        time.Duration `mapstructure:"max_wait"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+}
+
+// QuotaConfig holds sandbox storage limits
+type QuotaConfig struct {
+	// MaxBytes caps the data and index length of a sandbox, 0 = unlimited
+	MaxBytes int64 `mapstructure:"max_bytes"`
+
+	// CheckInterval is how often live sandboxes are measured while
+	// statements run, 0 = only after each statement
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+
+	// BlockedEngines lists storage engines that cannot be used, e.g. MEMORY
+	BlockedEngines []string `mapstructure:"blocked_engines"`
+
+	// MaxHeapTableSize is applied to max_heap_table_size and tmp_table_size
+	// of every sandbox connection, 0 = server default
+	MaxHeapTableSize int64 `mapstructure:"max_heap_table_size"`
+}
+
+// ReadinessConfig holds the thresholds of the readiness checks
+type ReadinessConfig struct {
+	// Timeout bounds every check
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// ProbeSandbox creates and drops a sandbox on every backend
+	ProbeSandbox bool `mapstructure:"probe_sandbox"`
+
+	// MaxPoolUsage is the share of open connections in use above which a
+	// backend is saturated, 0 = not checked
+	MaxPoolUsage float64 `mapstructure:"max_pool_usage"`
+
+	// MaxCleanupBacklog is the number of sandboxes that could not be
+	// dropped above which the server is not ready, 0 = not checked
+	MaxCleanupBacklog int `mapstructure:"max_cleanup_backlog"`
 }
 
 // SecurityConfig holds security-related configuration
 type SecurityConfig struct {
 	RateLimitPerSecond int `mapstructure:"rate_limit_per_second"`
 	RateLimitBurst     int `mapstructure:"rate_limit_burst"`
+
+	// BlockedCommands are case-insensitive regular expressions blocked in
+	// addition to the built-in dangerous commands
+	BlockedCommands []string `mapstructure:"blocked_commands"`
 }
 
 // LoggingConfig holds logging configuration
@@ -52,51 +267,406 @@ type LoggingConfig struct {
 	Format string `mapstructure:"format"`
 }
 
-// Load loads configuration from file and environment variables
+// AuditConfig holds audit log configuration
+type AuditConfig struct {
+	Enabled         bool             `mapstructure:"enabled"`
+	StoreQuery      string           `mapstructure:"store_query"`
+	PrincipalHeader string           `mapstructure:"principal_header"`
+	File            AuditFileConfig  `mapstructure:"file"`
+	MySQL           AuditMySQLConfig `mapstructure:"mysql"`
+}
+
+// AuditFileConfig holds JSONL audit file configuration
+type AuditFileConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+}
+
+// AuditMySQLConfig holds MySQL audit table configuration. The table is
+// written with its own account, which sandboxes must not be able to use:
+// it must differ from the accounts of the backends. Host, port and socket
+// default to the mysql section.
+type AuditMySQLConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Database string `mapstructure:"database"`
+	Table    string `mapstructure:"table"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Socket   string `mapstructure:"socket"`
+}
+
+// AdminConfig holds admin API configuration
+type AdminConfig struct {
+	Token string `mapstructure:"token"`
+
+	// OrphanMinAge protects databases whose tables changed more recently
+	// from the orphan cleanup
+	OrphanMinAge time.Duration `mapstructure:"orphan_min_age"`
+}
+
+// SessionsConfig holds persistent session configuration
+type SessionsConfig struct {
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+	MaxSessions int           `mapstructure:"max_sessions"`
+}
+
+// SnapshotsConfig holds snapshots of session sandboxes
+type SnapshotsConfig struct {
+	// MaxBytes caps the size of the dump of a snapshot, 0 = unlimited
+	MaxBytes int64 `mapstructure:"max_bytes"`
+
+	// MaxPerOwner caps the snapshots kept per owner, 0 = unlimited
+	MaxPerOwner int `mapstructure:"max_per_owner"`
+
+	// TTL is how long snapshots are kept, 0 = until they are deleted
+	TTL time.Duration `mapstructure:"ttl"`
+
+	// Store keeps snapshots in "memory" or on "disk" in Dir, where they
+	// survive a restart
+	Store string `mapstructure:"store"`
+	Dir   string `mapstructure:"dir"`
+}
+
+// FixturesConfig holds fixture configuration
+type FixturesConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// EnginesConfig holds the configuration of the engines besides MySQL
+type EnginesConfig struct {
+	SQLite   SQLiteConfig   `mapstructure:"sqlite"`
+	Postgres PostgresConfig `mapstructure:"postgres"`
+}
+
+// SQLiteConfig holds embedded SQLite engine configuration
+type SQLiteConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// PostgresConfig holds PostgreSQL engine configuration
+type PostgresConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Host            string        `mapstructure:"host"`
+	Port            int           `mapstructure:"port"`
+	User            string        `mapstructure:"user"`
+	Password        string        `mapstructure:"password"`
+	Database        string        `mapstructure:"database"`
+	SSLMode         string        `mapstructure:"sslmode"`
+	SchemaPrefix    string        `mapstructure:"schema_prefix"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+}
+
+// EnvPrefix prefixes the environment variables that override configuration
+// keys, e.g. MYSQL_TUI_MYSQL_PASSWORD overrides mysql.password. Keys of the
+// mysql section can be set without the prefix as well, e.g. MYSQL_PASSWORD.
+const EnvPrefix = "MYSQL_TUI"
+
+// fileEnvSuffix marks environment variables naming a file that holds the
+// value, e.g. MYSQL_TUI_MYSQL_PASSWORD_FILE=/run/secrets/mysql_password
+const fileEnvSuffix = "_FILE"
+
+// Load loads configuration from file and environment variables and
+// validates it
 func Load(configPath string) (*Config, error) {
-	viper.SetConfigFile(configPath)
-	viper.SetConfigType("yaml")
+	cfg, err := Read(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Read loads configuration from file and environment variables without
+// validating it
+func Read(configPath string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	v.SetConfigType("yaml")
 
 	// Set defaults
-	setDefaults()
+	setDefaults(v)
 
 	// Read config file
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		return nil, err
 	}
 
 	// Allow environment variable overrides
-	viper.AutomaticEnv()
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	for _, key := range v.AllKeys() {
+		if names := envNames(key); len(names) > 1 {
+			v.BindEnv(append([]string{key}, names...)...)
+		}
+	}
+
+	if err := readFileEnv(v); err != nil {
+		return nil, err
+	}
 
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, err
 	}
 
 	return &cfg, nil
 }
 
+// readFileEnv sets the keys whose value is given in a file by a *_FILE
+// environment variable, the convention of Docker secrets. Trailing newlines
+// of the file are removed.
+func readFileEnv(v *viper.Viper) error {
+	var errs []error
+	for _, key := range v.AllKeys() {
+		// The first variable that is set in either form decides
+		for _, env := range envNames(key) {
+			path, fromFile := os.LookupEnv(env + fileEnvSuffix)
+			_, direct := os.LookupEnv(env)
+			if !fromFile {
+				if direct {
+					break
+				}
+				continue
+			}
+
+			if direct {
+				errs = append(errs, fmt.Errorf("%s and %s%s are both set", env, env, fileEnvSuffix))
+				break
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: %w", env, fileEnvSuffix, err))
+				break
+			}
+			v.Set(key, strings.TrimRight(string(data), "\r\n"))
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// envNames returns the environment variables that override a key, in order
+// of precedence. Keys of the mysql section may also be given without the
+// prefix, the names used by MySQL tooling.
+func envNames(key string) []string {
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	names := []string{EnvPrefix + "_" + name}
+	if strings.HasPrefix(key, "mysql.") {
+		names = append(names, name)
+	}
+	return names
+}
+
+// MySQLBackends returns the configured MySQL backends. Without a backends
+// list the mysql section is the only backend, named "default". Fields left
+// empty in a backend are taken from the mysql and executor sections.
+func (c *Config) MySQLBackends() []BackendConfig {
+	if len(c.Backends) == 0 {
+		return []BackendConfig{c.backendDefaults(BackendConfig{Name: "default"})}
+	}
+
+	backends := make([]BackendConfig, len(c.Backends))
+	for i, backend := range c.Backends {
+		backends[i] = c.backendDefaults(backend)
+	}
+	return backends
+}
+
+// backendDefaults fills the empty fields of a backend
+func (c *Config) backendDefaults(backend BackendConfig) BackendConfig {
+	if backend.User == "" {
+		backend.User = c.MySQL.User
+		if backend.Password == "" {
+			backend.Password = c.MySQL.Password
+		}
+	}
+	if backend.DBPrefix == "" {
+		backend.DBPrefix = c.Executor.DBPrefix
+	}
+	if backend.MaxOpenConns == 0 {
+		backend.MaxOpenConns = c.MySQL.MaxOpenConns
+	}
+	if backend.MaxIdleConns == 0 {
+		backend.MaxIdleConns = c.MySQL.MaxIdleConns
+	}
+	if backend.ConnMaxLifetime == 0 {
+		backend.ConnMaxLifetime = c.MySQL.ConnMaxLifetime
+	}
+	if len(backend.Replicas) == 0 {
+		backend.Replicas = []ReplicaConfig{{Host: c.MySQL.Host, Port: c.MySQL.Port, Socket: c.MySQL.Socket}}
+	}
+	backend.Options = c.MySQL.MySQLOptions
+	for i := range backend.Replicas {
+		if backend.Replicas[i].Port == 0 {
+			backend.Replicas[i].Port = 3306
+		}
+	}
+	return backend
+}
+
+// AuditBackend returns the connection settings of the MySQL audit sink
+// in the form of a backend with a single replica
+func (c *Config) AuditBackend() BackendConfig {
+	audit := c.Audit.MySQL
+	replica := ReplicaConfig{Host: audit.Host, Port: audit.Port, Socket: audit.Socket}
+	if replica.Host == "" && replica.Socket == "" {
+		replica = ReplicaConfig{Host: c.MySQL.Host, Port: c.MySQL.Port, Socket: c.MySQL.Socket}
+	}
+	if replica.Port == 0 {
+		replica.Port = 3306
+	}
+
+	// Timestamps are written and read as UTC time.Time values whatever the
+	// sandbox settings are
+	options := c.MySQL.MySQLOptions
+	options.ParseTime = true
+	options.Loc = "UTC"
+
+	return BackendConfig{
+		Name:         "audit",
+		User:         audit.User,
+		Password:     audit.Password,
+		MaxOpenConns: 2,
+		MaxIdleConns: 2,
+		Replicas:     []ReplicaConfig{replica},
+		Options:      options,
+	}
+}
+
 // setDefaults sets default configuration values
-func setDefaults() {
-	viper.SetDefault("server.port", 8080)
-	viper.SetDefault("server.read_timeout", "35s")
-	viper.SetDefault("server.write_timeout", "35s")
-	viper.SetDefault("server.shutdown_timeout", "5s")
-
-	viper.SetDefault("mysql.host", "localhost")
-	viper.SetDefault("mysql.port", 3306)
-	viper.SetDefault("mysql.user", "root")
-	viper.SetDefault("mysql.password", "rootpassword")
-	viper.SetDefault("mysql.max_open_conns", 25)
-	viper.SetDefault("mysql.max_idle_conns", 10)
-	viper.SetDefault("mysql.conn_max_lifetime", "5m")
-
-	viper.SetDefault("executor.query_timeout", "30s")
-	viper.SetDefault("executor.db_prefix", "student_db_")
-
-	viper.SetDefault("security.rate_limit_per_second", 10)
-	viper.SetDefault("security.rate_limit_burst", 20)
-
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("logging.format", "json")
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.read_timeout", "35s")
+	v.SetDefault("server.write_timeout", "35s")
+	v.SetDefault("server.shutdown_timeout", "5s")
+	v.SetDefault("server.shutdown_delay", "0s")
+	v.SetDefault("server.tls.enabled", false)
+	v.SetDefault("server.tls.cert_file", "")
+	v.SetDefault("server.tls.key_file", "")
+	v.SetDefault("server.tls.min_version", "1.2")
+	v.SetDefault("server.tls.client_ca_file", "")
+	v.SetDefault("server.tls.redirect_port", 0)
+
+	v.SetDefault("mysql.host", "localhost")
+	v.SetDefault("mysql.port", 3306)
+	v.SetDefault("mysql.user", "root")
+	v.SetDefault("mysql.password", "rootpassword")
+	v.SetDefault("mysql.max_open_conns", 25)
+	v.SetDefault("mysql.max_idle_conns", 10)
+	v.SetDefault("mysql.conn_max_lifetime", "5m")
+	v.SetDefault("mysql.socket", "")
+	v.SetDefault("mysql.charset", "")
+	v.SetDefault("mysql.collation", "")
+	v.SetDefault("mysql.parse_time", false)
+	v.SetDefault("mysql.loc", "UTC")
+	v.SetDefault("mysql.timeout", "0s")
+	v.SetDefault("mysql.read_timeout", "0s")
+	v.SetDefault("mysql.write_timeout", "0s")
+	v.SetDefault("mysql.interpolate_params", false)
+	v.SetDefault("mysql.connection_attributes", map[string]string{})
+	v.SetDefault("mysql.tls.mode", "disabled")
+	v.SetDefault("mysql.tls.ca_file", "")
+	v.SetDefault("mysql.tls.cert_file", "")
+	v.SetDefault("mysql.tls.key_file", "")
+	v.SetDefault("mysql.tls.server_name", "")
+
+	v.SetDefault("executor.query_timeout", "30s")
+	v.SetDefault("executor.db_prefix", "student_db_")
+	v.SetDefault("executor.json_style", "compact")
+	v.SetDefault("executor.max_value_length", 0)
+	v.SetDefault("executor.default_backend", "")
+	v.SetDefault("executor.health_check_interval", "10s")
+	v.SetDefault("executor.breaker_threshold", 3)
+	v.SetDefault("executor.breaker_cooldown", "5s")
+	v.SetDefault("executor.max_concurrent", 32)
+	v.SetDefault("executor.instance_id", "")
+
+	v.SetDefault("startup.max_wait", "2m")
+	v.SetDefault("startup.initial_backoff", "500ms")
+	v.SetDefault("startup.max_backoff", "10s")
+
+	v.SetDefault("batch.max_items", 200)
+	v.SetDefault("batch.max_parallel", 4)
+	v.SetDefault("batch.item_cost", 0.1)
+
+	v.SetDefault("jobs.workers", 4)
+	v.SetDefault("jobs.queue_size", 100)
+	v.SetDefault("jobs.query_timeout", "10m")
+	v.SetDefault("jobs.result_ttl", "1h")
+	v.SetDefault("jobs.store", "memory")
+	v.SetDefault("jobs.dir", "./data/jobs")
+	v.SetDefault("jobs.callback_url", "")
+	v.SetDefault("jobs.callback_token", "")
+	v.SetDefault("jobs.callback_timeout", "5s")
+
+	v.SetDefault("quota.max_bytes", 64*1024*1024)
+	v.SetDefault("quota.check_interval", "5s")
+	v.SetDefault("quota.blocked_engines", []string{})
+	v.SetDefault("quota.max_heap_table_size", 0)
+
+	v.SetDefault("readiness.timeout", "2s")
+	v.SetDefault("readiness.probe_sandbox", true)
+	v.SetDefault("readiness.max_pool_usage", 0.9)
+	v.SetDefault("readiness.max_cleanup_backlog", 10)
+
+	v.SetDefault("security.rate_limit_per_second", 10)
+	v.SetDefault("security.rate_limit_burst", 20)
+	v.SetDefault("security.blocked_commands", []string{})
+
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.format", "json")
+
+	v.SetDefault("audit.enabled", true)
+	v.SetDefault("audit.store_query", "full")
+	v.SetDefault("audit.principal_header", "X-User-ID")
+	v.SetDefault("audit.file.enabled", true)
+	v.SetDefault("audit.file.path", "./logs/audit.jsonl")
+	v.SetDefault("audit.file.max_size_mb", 50)
+	v.SetDefault("audit.file.max_backups", 10)
+	v.SetDefault("audit.mysql.enabled", false)
+	v.SetDefault("audit.mysql.database", "mysql_tui_audit")
+	v.SetDefault("audit.mysql.table", "query_audit")
+	v.SetDefault("audit.mysql.user", "")
+	v.SetDefault("audit.mysql.password", "")
+	v.SetDefault("audit.mysql.host", "")
+	v.SetDefault("audit.mysql.port", 0)
+	v.SetDefault("audit.mysql.socket", "")
+
+	v.SetDefault("admin.token", "")
+	v.SetDefault("admin.orphan_min_age", "1h")
+
+	v.SetDefault("sessions.idle_timeout", "30m")
+	v.SetDefault("sessions.max_sessions", 100)
+
+	v.SetDefault("snapshots.max_bytes", 16*1024*1024)
+	v.SetDefault("snapshots.max_per_owner", 10)
+	v.SetDefault("snapshots.ttl", "168h")
+	v.SetDefault("snapshots.store", "memory")
+	v.SetDefault("snapshots.dir", "./data/snapshots")
+
+	v.SetDefault("fixtures.dir", "./fixtures")
+
+	v.SetDefault("engines.sqlite.enabled", true)
+	v.SetDefault("engines.postgres.enabled", false)
+	v.SetDefault("engines.postgres.host", "localhost")
+	v.SetDefault("engines.postgres.port", 5432)
+	v.SetDefault("engines.postgres.user", "postgres")
+	v.SetDefault("engines.postgres.password", "")
+	v.SetDefault("engines.postgres.database", "postgres")
+	v.SetDefault("engines.postgres.sslmode", "disable")
+	v.SetDefault("engines.postgres.schema_prefix", "student_db_")
+	v.SetDefault("engines.postgres.max_open_conns", 25)
+	v.SetDefault("engines.postgres.max_idle_conns", 10)
+	v.SetDefault("engines.postgres.conn_max_lifetime", "5m")
 }
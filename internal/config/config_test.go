@@ -0,0 +1,176 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadEnv(t *testing.T) {
+	path := writeConfig(t, "mysql:\n  host: mysql\n  password: from-file\n")
+
+	secret := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secret, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("MYSQL_TUI_MYSQL_PASSWORD", "from-env")
+	t.Setenv("MYSQL_TUI_SERVER_PORT", "9090")
+	t.Setenv("MYSQL_TUI_ADMIN_TOKEN_FILE", secret)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.MySQL.Host != "mysql" {
+		t.Errorf("Expected host from file, got %q", cfg.MySQL.Host)
+	}
+	if cfg.MySQL.Password != "from-env" {
+		t.Errorf("Expected password from env, got %q", cfg.MySQL.Password)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Errorf("Expected port 9090, got %d", cfg.Server.Port)
+	}
+	if cfg.Admin.Token != "s3cret" {
+		t.Errorf("Expected token from secret file, got %q", cfg.Admin.Token)
+	}
+}
+
+func TestLoadUnprefixedEnv(t *testing.T) {
+	path := writeConfig(t, "mysql:\n  host: mysql\n")
+
+	secret := filepath.Join(t.TempDir(), "user")
+	if err := os.WriteFile(secret, []byte("student\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("MYSQL_PASSWORD", "plain")
+	t.Setenv("MYSQL_USER_FILE", secret)
+	t.Setenv("MYSQL_HOST", "unprefixed")
+	t.Setenv("MYSQL_TUI_MYSQL_HOST", "prefixed")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.MySQL.Password != "plain" {
+		t.Errorf("Expected password from MYSQL_PASSWORD, got %q", cfg.MySQL.Password)
+	}
+	if cfg.MySQL.User != "student" {
+		t.Errorf("Expected user from MYSQL_USER_FILE, got %q", cfg.MySQL.User)
+	}
+	if cfg.MySQL.Host != "prefixed" {
+		t.Errorf("Expected the prefixed variable to win, got %q", cfg.MySQL.Host)
+	}
+}
+
+func TestLoadFileEnvConflict(t *testing.T) {
+	path := writeConfig(t, "mysql:\n  host: mysql\n")
+
+	t.Setenv("MYSQL_TUI_MYSQL_PASSWORD", "x")
+	t.Setenv("MYSQL_TUI_MYSQL_PASSWORD_FILE", "/nonexistent")
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "both set") {
+		t.Errorf("Expected conflict error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	path := writeConfig(t, `
+server:
+  port: 0
+mysql:
+  max_open_conns: 5
+  max_idle_conns: 10
+  connection_attributes:
+    app: "a,b"
+executor:
+  json_style: fancy
+  default_backend: missing
+readiness:
+  max_pool_usage: 2
+audit:
+  mysql:
+    enabled: true
+    user: root
+`)
+
+	cfg, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+
+	// Every problem is reported at once
+	for _, key := range []string{
+		"server.port",
+		"mysql.max_idle_conns",
+		"mysql.connection_attributes",
+		"executor.json_style",
+		"executor.default_backend",
+		"readiness.max_pool_usage",
+		"audit.mysql.user",
+	} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Expected error for %s, got: %v", key, err)
+		}
+	}
+
+	// The defaults are valid
+	if _, err := Load(writeConfig(t, "{}\n")); err != nil {
+		t.Errorf("Unexpected error for defaults: %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	current := &Config{
+		Server:   ServerConfig{Port: 8080},
+		MySQL:    MySQLConfig{Host: "mysql", Password: "old"},
+		Security: SecurityConfig{RateLimitPerSecond: 10},
+		Executor: ExecutorConfig{QueryTimeout: 30 * time.Second, DBPrefix: "student_db_"},
+		Backends: []BackendConfig{{Name: "a", Replicas: []ReplicaConfig{{Host: "a1"}}}},
+	}
+
+	next := *current
+	next.Server.Port = 9090
+	next.MySQL.Password = "new"
+	next.Security.RateLimitPerSecond = 50
+	next.Executor.QueryTimeout = 5 * time.Second
+	next.Backends = []BackendConfig{{Name: "a", Replicas: []ReplicaConfig{{Host: "a1"}, {Host: "a2"}}}}
+
+	merged, restart := current.Reload(&next)
+
+	if merged.Security.RateLimitPerSecond != 50 || merged.Executor.QueryTimeout != 5*time.Second {
+		t.Errorf("Reloadable settings not applied: %+v %+v", merged.Security, merged.Executor)
+	}
+	if merged.Server.Port != 8080 || merged.MySQL.Password != "old" || len(merged.Backends[0].Replicas) != 1 {
+		t.Errorf("Restart-only settings changed: %+v %+v %+v", merged.Server, merged.MySQL, merged.Backends)
+	}
+
+	want := []string{"backends[0].replicas[1].host", "backends[0].replicas[1].port", "backends[0].replicas[1].socket", "mysql.password", "server.port"}
+	if strings.Join(restart, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected restart keys %v, got %v", want, restart)
+	}
+
+	// The current configuration is not modified
+	if current.Security.RateLimitPerSecond != 10 {
+		t.Errorf("Current configuration modified: %+v", current.Security)
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redactedValue replaces secrets in redacted configurations
+const redactedValue = "[REDACTED]"
+
+// secretKeys are the configuration keys holding secrets
+var secretKeys = map[string]bool{
+	"password":       true,
+	"token":          true,
+	"callback_token": true,
+}
+
+// Redacted returns the configuration as a map keyed like the config file,
+// with secrets replaced. Durations are rendered as strings.
+func (c *Config) Redacted() map[string]interface{} {
+	return redactValue(reflect.ValueOf(*c)).(map[string]interface{})
+}
+
+// redactValue converts a configuration value for display
+func redactValue(v reflect.Value) interface{} {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			key, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if opts == "squash" {
+				for k, value := range redactValue(v.Field(i)).(map[string]interface{}) {
+					out[k] = value
+				}
+				continue
+			}
+			if key == "" || key == "-" {
+				continue
+			}
+
+			if secretKeys[key] && !v.Field(i).IsZero() {
+				out[key] = redactedValue
+				continue
+			}
+			out[key] = redactValue(v.Field(i))
+		}
+		return out
+
+	case reflect.Slice:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = redactValue(v.Index(i))
+		}
+		return out
+
+	default:
+		return v.Interface()
+	}
+}
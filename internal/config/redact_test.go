@@ -0,0 +1,38 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedacted(t *testing.T) {
+	cfg := &Config{
+		MySQL:    MySQLConfig{Host: "mysql", Password: "12345", ConnMaxLifetime: 5 * time.Minute},
+		Admin:    AdminConfig{Token: "secret"},
+		Backends: []BackendConfig{{Name: "mysql57", Password: "hunter2"}},
+	}
+
+	redacted := cfg.Redacted()
+
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"12345", "secret", "hunter2"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Redacted config contains secret %q: %s", secret, data)
+		}
+	}
+
+	mysql := redacted["mysql"].(map[string]interface{})
+	if mysql["host"] != "mysql" || mysql["password"] != redactedValue || mysql["conn_max_lifetime"] != "5m0s" {
+		t.Errorf("Unexpected mysql section: %v", mysql)
+	}
+
+	// Empty secrets are shown as empty, e.g. a disabled admin API
+	if postgres := redacted["engines"].(map[string]interface{})["postgres"].(map[string]interface{}); postgres["password"] != "" {
+		t.Errorf("Expected empty postgres password, got %v", postgres["password"])
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Reload returns the configuration that results from applying the settings
+// of next that can change at runtime: rate limits and blocked commands,
+// query timeout and value rendering, storage quotas, the logging level,
+// readiness thresholds and batch limits. The keys of the other settings
+// that differ in next are returned as well; they keep their current value
+// until a restart.
+func (c *Config) Reload(next *Config) (*Config, []string) {
+	merged := *c
+
+	merged.Security = next.Security
+	merged.Executor.QueryTimeout = next.Executor.QueryTimeout
+	merged.Executor.JSONStyle = next.Executor.JSONStyle
+	merged.Executor.MaxValueLength = next.Executor.MaxValueLength
+	merged.Quota.MaxBytes = next.Quota.MaxBytes
+	merged.Quota.BlockedEngines = next.Quota.BlockedEngines
+	merged.Quota.MaxHeapTableSize = next.Quota.MaxHeapTableSize
+	merged.Logging.Level = next.Logging.Level
+	merged.Readiness = next.Readiness
+	merged.Batch = next.Batch
+
+	return &merged, changedKeys(&merged, next)
+}
+
+// changedKeys returns the sorted keys whose values differ between a and b
+func changedKeys(a, b *Config) []string {
+	valuesA := make(map[string]string)
+	valuesB := make(map[string]string)
+	flatten("", reflect.ValueOf(*a), valuesA)
+	flatten("", reflect.ValueOf(*b), valuesB)
+
+	var keys []string
+	for key, value := range valuesA {
+		if other, ok := valuesB[key]; !ok || other != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range valuesB {
+		if _, ok := valuesA[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// flatten records the leaf values of a configuration by their dotted key,
+// e.g. "mysql.host" or "backends[0].replicas[1].port"
+func flatten(prefix string, v reflect.Value, out map[string]string) {
+	switch {
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			key, opts, _ := strings.Cut(v.Type().Field(i).Tag.Get("mapstructure"), ",")
+			if opts == "squash" {
+				flatten(prefix, v.Field(i), out)
+				continue
+			}
+			if key == "" || key == "-" {
+				continue
+			}
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(key, v.Field(i), out)
+		}
+
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		for i := 0; i < v.Len(); i++ {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), v.Index(i), out)
+		}
+
+	default:
+		out[prefix] = fmt.Sprint(v.Interface())
+	}
+}
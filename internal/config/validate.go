@@ -0,0 +1,316 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// identifierPattern matches prefixes that are safe in database names
+var identifierPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Validate checks the configuration and reports every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	// Server
+	checkPort(add, "server.port", c.Server.Port)
+	checkNonNegative(add, "server.read_timeout", c.Server.ReadTimeout)
+	checkNonNegative(add, "server.write_timeout", c.Server.WriteTimeout)
+	checkNonNegative(add, "server.shutdown_timeout", c.Server.ShutdownTimeout)
+	checkNonNegative(add, "server.shutdown_delay", c.Server.ShutdownDelay)
+	if tls := c.Server.TLS; tls.Enabled {
+		if tls.CertFile == "" || tls.KeyFile == "" {
+			add("server.tls: cert_file and key_file are required")
+		}
+		if tls.MinVersion != "1.2" && tls.MinVersion != "1.3" {
+			add("server.tls.min_version: must be 1.2 or 1.3, got %q", tls.MinVersion)
+		}
+		if tls.RedirectPort != 0 {
+			checkPort(add, "server.tls.redirect_port", tls.RedirectPort)
+			if tls.RedirectPort == c.Server.Port {
+				add("server.tls.redirect_port: must differ from server.port")
+			}
+		}
+	}
+
+	// MySQL backends; the mysql section is validated as the default backend
+	names := make(map[string]bool)
+	for i, backend := range c.MySQLBackends() {
+		section := "mysql"
+		if len(c.Backends) > 0 {
+			section = fmt.Sprintf("backends[%d]", i)
+		}
+
+		if backend.Name == "" {
+			add("%s.name: must not be empty", section)
+		} else if names[backend.Name] {
+			add("%s.name: duplicate backend %q", section, backend.Name)
+		}
+		names[backend.Name] = true
+
+		if backend.User == "" {
+			add("%s.user: must not be empty", section)
+		}
+		if !identifierPattern.MatchString(backend.DBPrefix) {
+			add("%s.db_prefix: must consist of letters, digits and underscores, got %q", section, backend.DBPrefix)
+		}
+		checkPool(add, section, backend.MaxOpenConns, backend.MaxIdleConns, backend.ConnMaxLifetime)
+
+		for j, replica := range backend.Replicas {
+			replicaSection := section
+			if len(c.Backends) > 0 {
+				replicaSection = fmt.Sprintf("%s.replicas[%d]", section, j)
+			}
+			if replica.Socket != "" {
+				continue
+			}
+			if replica.Host == "" {
+				add("%s.host: must not be empty", replicaSection)
+			}
+			checkPort(add, replicaSection+".port", replica.Port)
+		}
+	}
+
+	// MySQL driver options
+	options := c.MySQL.MySQLOptions
+	checkNonNegative(add, "mysql.timeout", options.Timeout)
+	checkNonNegative(add, "mysql.read_timeout", options.ReadTimeout)
+	checkNonNegative(add, "mysql.write_timeout", options.WriteTimeout)
+	if _, err := time.LoadLocation(options.Loc); err != nil {
+		add("mysql.loc: %v", err)
+	}
+	switch options.TLS.Mode {
+	case "disabled", "preferred", "required", "verify_ca", "verify_identity":
+	default:
+		add("mysql.tls.mode: must be disabled, preferred, required, verify_ca or verify_identity, got %q", options.TLS.Mode)
+	}
+	for key, value := range options.ConnectionAttributes {
+		// The driver separates attributes by commas and keys by colons
+		if strings.ContainsAny(key, ",:") || strings.ContainsAny(value, ",:") {
+			add("mysql.connection_attributes: %q must not contain ',' or ':' in its name or value", key)
+		}
+	}
+	if (options.TLS.CertFile == "") != (options.TLS.KeyFile == "") {
+		add("mysql.tls: cert_file and key_file must be set together")
+	}
+
+	// Executor
+	if c.Executor.QueryTimeout <= 0 {
+		add("executor.query_timeout: must be positive, got %s", c.Executor.QueryTimeout)
+	}
+	if c.Executor.JSONStyle != "compact" && c.Executor.JSONStyle != "pretty" {
+		add("executor.json_style: must be compact or pretty, got %q", c.Executor.JSONStyle)
+	}
+	if c.Executor.MaxValueLength < 0 {
+		add("executor.max_value_length: must not be negative, got %d", c.Executor.MaxValueLength)
+	}
+	if c.Executor.DefaultBackend != "" && !names[c.Executor.DefaultBackend] {
+		add("executor.default_backend: unknown backend %q", c.Executor.DefaultBackend)
+	}
+	checkNonNegative(add, "executor.health_check_interval", c.Executor.HealthCheckInterval)
+	if c.Executor.BreakerThreshold < 0 {
+		add("executor.breaker_threshold: must not be negative, got %d", c.Executor.BreakerThreshold)
+	}
+	checkNonNegative(add, "executor.breaker_cooldown", c.Executor.BreakerCooldown)
+	if c.Executor.InstanceID != "" && (!identifierPattern.MatchString(c.Executor.InstanceID) || len(c.Executor.InstanceID) > 16) {
+		add("executor.instance_id: must be at most 16 letters, digits and underscores, got %q", c.Executor.InstanceID)
+	}
+	if c.Executor.MaxConcurrent < 0 {
+		add("executor.max_concurrent: must not be negative, got %d", c.Executor.MaxConcurrent)
+	}
+
+	// Batch
+	if c.Batch.MaxItems <= 0 {
+		add("batch.max_items: must be positive, got %d", c.Batch.MaxItems)
+	}
+	if c.Batch.MaxParallel <= 0 {
+		add("batch.max_parallel: must be positive, got %d", c.Batch.MaxParallel)
+	}
+	if c.Batch.ItemCost < 0 {
+		add("batch.item_cost: must not be negative, got %g", c.Batch.ItemCost)
+	}
+
+	// Jobs
+	if c.Jobs.Workers <= 0 {
+		add("jobs.workers: must be positive, got %d", c.Jobs.Workers)
+	}
+	if c.Jobs.QueueSize <= 0 {
+		add("jobs.queue_size: must be positive, got %d", c.Jobs.QueueSize)
+	}
+	checkNonNegative(add, "jobs.query_timeout", c.Jobs.QueryTimeout)
+	if c.Jobs.ResultTTL <= 0 {
+		add("jobs.result_ttl: must be positive, got %s", c.Jobs.ResultTTL)
+	}
+	switch c.Jobs.Store {
+	case "memory":
+	case "disk":
+		if c.Jobs.Dir == "" {
+			add("jobs.dir: must not be empty with the disk store")
+		}
+	default:
+		add("jobs.store: must be memory or disk, got %q", c.Jobs.Store)
+	}
+	if c.Jobs.CallbackURL != "" {
+		if u, err := url.Parse(c.Jobs.CallbackURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("jobs.callback_url: must be an http or https URL, got %q", c.Jobs.CallbackURL)
+		}
+	}
+	if c.Jobs.CallbackTimeout <= 0 {
+		add("jobs.callback_timeout: must be positive, got %s", c.Jobs.CallbackTimeout)
+	}
+
+	// Startup
+	checkNonNegative(add, "startup.max_wait", c.Startup.MaxWait)
+	if c.Startup.InitialBackoff <= 0 || c.Startup.MaxBackoff < c.Startup.InitialBackoff {
+		add("startup: initial_backoff must be positive and not exceed max_backoff")
+	}
+
+	// Quota
+	if c.Quota.MaxBytes < 0 {
+		add("quota.max_bytes: must not be negative, got %d", c.Quota.MaxBytes)
+	}
+	checkNonNegative(add, "quota.check_interval", c.Quota.CheckInterval)
+	if c.Quota.MaxHeapTableSize < 0 {
+		add("quota.max_heap_table_size: must not be negative, got %d", c.Quota.MaxHeapTableSize)
+	}
+
+	// Readiness
+	checkNonNegative(add, "readiness.timeout", c.Readiness.Timeout)
+	if c.Readiness.MaxPoolUsage < 0 || c.Readiness.MaxPoolUsage > 1 {
+		add("readiness.max_pool_usage: must be between 0 and 1, got %g", c.Readiness.MaxPoolUsage)
+	}
+	if c.Readiness.MaxCleanupBacklog < 0 {
+		add("readiness.max_cleanup_backlog: must not be negative, got %d", c.Readiness.MaxCleanupBacklog)
+	}
+
+	// Security
+	if c.Security.RateLimitPerSecond <= 0 {
+		add("security.rate_limit_per_second: must be positive, got %d", c.Security.RateLimitPerSecond)
+	}
+	if c.Security.RateLimitBurst <= 0 {
+		add("security.rate_limit_burst: must be positive, got %d", c.Security.RateLimitBurst)
+	}
+	for i, cmd := range c.Security.BlockedCommands {
+		if _, err := regexp.Compile(`(?i)` + cmd); err != nil {
+			add("security.blocked_commands[%d]: %v", i, err)
+		}
+	}
+
+	// Logging
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		add("logging.level: must be debug, info, warn or error, got %q", c.Logging.Level)
+	}
+
+	// Audit
+	if c.Audit.Enabled {
+		if c.Audit.StoreQuery != "full" && c.Audit.StoreQuery != "hash" {
+			add("audit.store_query: must be full or hash, got %q", c.Audit.StoreQuery)
+		}
+		if c.Audit.File.Enabled && c.Audit.File.Path == "" {
+			add("audit.file.path: must not be empty")
+		}
+		if c.Audit.File.MaxSizeMB < 0 || c.Audit.File.MaxBackups < 0 {
+			add("audit.file: max_size_mb and max_backups must not be negative")
+		}
+		if c.Audit.MySQL.Enabled {
+			if c.Audit.MySQL.Database == "" || c.Audit.MySQL.Table == "" {
+				add("audit.mysql: database and table must not be empty")
+			}
+			if c.Audit.MySQL.User == "" {
+				add("audit.mysql.user: must not be empty")
+			}
+			for _, backend := range c.MySQLBackends() {
+				if c.Audit.MySQL.User != "" && backend.User == c.Audit.MySQL.User {
+					add("audit.mysql.user: must differ from the account of backend %q, whose sandboxes could read the audit table", backend.Name)
+				}
+			}
+		}
+	}
+
+	// Admin
+	checkNonNegative(add, "admin.orphan_min_age", c.Admin.OrphanMinAge)
+
+	// Sessions
+	checkNonNegative(add, "sessions.idle_timeout", c.Sessions.IdleTimeout)
+	if c.Sessions.MaxSessions < 0 {
+		add("sessions.max_sessions: must not be negative, got %d", c.Sessions.MaxSessions)
+	}
+
+	// Snapshots
+	if c.Snapshots.MaxBytes < 0 {
+		add("snapshots.max_bytes: must not be negative, got %d", c.Snapshots.MaxBytes)
+	}
+	if c.Snapshots.MaxPerOwner < 0 {
+		add("snapshots.max_per_owner: must not be negative, got %d", c.Snapshots.MaxPerOwner)
+	}
+	checkNonNegative(add, "snapshots.ttl", c.Snapshots.TTL)
+	switch c.Snapshots.Store {
+	case "memory":
+	case "disk":
+		if c.Snapshots.Dir == "" {
+			add("snapshots.dir: must not be empty with the disk store")
+		}
+	default:
+		add("snapshots.store: must be memory or disk, got %q", c.Snapshots.Store)
+	}
+
+	// Engines
+	if postgres := c.Engines.Postgres; postgres.Enabled {
+		if postgres.Host == "" {
+			add("engines.postgres.host: must not be empty")
+		}
+		checkPort(add, "engines.postgres.port", postgres.Port)
+		if !identifierPattern.MatchString(postgres.SchemaPrefix) {
+			add("engines.postgres.schema_prefix: must consist of letters, digits and underscores, got %q", postgres.SchemaPrefix)
+		}
+		switch postgres.SSLMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			add("engines.postgres.sslmode: unknown mode %q", postgres.SSLMode)
+		}
+		checkPool(add, "engines.postgres", postgres.MaxOpenConns, postgres.MaxIdleConns, postgres.ConnMaxLifetime)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+}
+
+// checkPort reports ports outside of the TCP range
+func checkPort(add func(string, ...interface{}), key string, port int) {
+	if port < 1 || port > 65535 {
+		add("%s: must be between 1 and 65535, got %d", key, port)
+	}
+}
+
+// checkNonNegative reports negative durations
+func checkNonNegative(add func(string, ...interface{}), key string, d time.Duration) {
+	if d < 0 {
+		add("%s: must not be negative, got %s", key, d)
+	}
+}
+
+// checkPool reports inconsistent connection pool settings. A max_open_conns
+// of 0 means unlimited.
+func checkPool(add func(string, ...interface{}), section string, maxOpen, maxIdle int, lifetime time.Duration) {
+	if maxOpen < 0 {
+		add("%s.max_open_conns: must not be negative, got %d", section, maxOpen)
+	}
+	if maxIdle < 0 {
+		add("%s.max_idle_conns: must not be negative, got %d", section, maxIdle)
+	}
+	if maxOpen > 0 && maxIdle > maxOpen {
+		add("%s.max_idle_conns: %d exceeds max_open_conns %d", section, maxIdle, maxOpen)
+	}
+	checkNonNegative(add, section+".conn_max_lifetime", lifetime)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// SandboxInfo describes a live MySQL sandbox for operators
+type SandboxInfo struct {
+	Name         string    `json:"name"`
+	Backend      string    `json:"backend"`
+	Replica      string    `json:"replica"`
+	Owner        string    `json:"owner,omitempty"`
+	Session      string    `json:"session,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	AgeSeconds   int64     `json:"age_seconds"`
+	LastActivity time.Time `json:"last_activity"`
+
+	// SizeBytes is the data and index length of the sandbox tables
+	SizeBytes int64 `json:"size_bytes"`
+
+	// ConnectionID is the MySQL connection the sandbox is pinned to
+	ConnectionID int64 `json:"connection_id"`
+
+	// CurrentStatement is the statement running on the connection according
+	// to the processlist, together with its running time
+	CurrentStatement string `json:"current_statement,omitempty"`
+	StatementSeconds int64  `json:"statement_seconds,omitempty"`
+}
+
+// OrphanSchema is a database with a sandbox prefix that belongs to no live
+// sandbox, e.g. left behind by a crash
+type OrphanSchema struct {
+	Name      string `json:"name"`
+	Backend   string `json:"backend"`
+	Replica   string `json:"replica"`
+	SizeBytes int64  `json:"size_bytes"`
+
+	// IdleSeconds is the time since its tables were last created or
+	// changed; it is absent for databases without tables
+	IdleSeconds *int64 `json:"idle_seconds,omitempty"`
+
+	Dropped bool   `json:"dropped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
@@ -0,0 +1,65 @@
+package domain
+
+// BatchRequest is a list of independent scripts executed in one request
+type BatchRequest struct {
+	// Items are the scripts, each executed in its own sandbox
+	Items []BatchItem `json:"items" binding:"required"`
+}
+
+// BatchItem is one script of a batch. It accepts every field of an
+// execution request.
+type BatchItem struct {
+	// ID identifies the item in the results, e.g. a submission. It defaults
+	// to the 1-based position of the item.
+	ID string `json:"id"`
+
+	ExecuteRequest
+}
+
+// BatchItemResult is the outcome of one item of a batch
+type BatchItemResult struct {
+	// ID is the identifier of the item
+	ID string `json:"id"`
+
+	// Index is the 1-based position of the item in the request
+	Index int `json:"index"`
+
+	// Status is the HTTP status the item would have had on /execute
+	Status int `json:"status"`
+
+	*ExecuteResponse
+}
+
+// BatchSummary sums up the results of a batch
+type BatchSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+
+	// ErrorCodes counts the failed items by error code
+	ErrorCodes map[ErrorCode]int `json:"error_codes,omitempty"`
+
+	// ExecutionTimeMs is the wall time of the whole batch in milliseconds
+	ExecutionTimeMs int64 `json:"execution_time_ms"`
+}
+
+// Add counts the result of an item
+func (s *BatchSummary) Add(result *BatchItemResult) {
+	s.Total++
+	if result.Success {
+		s.Succeeded++
+		return
+	}
+
+	s.Failed++
+	if s.ErrorCodes == nil {
+		s.ErrorCodes = make(map[ErrorCode]int)
+	}
+	s.ErrorCodes[result.ErrorCode]++
+}
+
+// BatchResponse is the response of a batch in request order
+type BatchResponse struct {
+	Results []*BatchItemResult `json:"results"`
+	Summary BatchSummary       `json:"summary"`
+}
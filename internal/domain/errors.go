@@ -1,24 +1,166 @@
 package domain
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorCode is the stable, machine-readable class of an error
+type ErrorCode string
+
+// Error codes
+const (
+	// CodeValidation reports a malformed or invalid request
+	CodeValidation ErrorCode = "validation"
+
+	// CodePolicyViolation reports SQL rejected by the security or quota rules
+	CodePolicyViolation ErrorCode = "policy_violation"
+
+	// CodeSQLError reports SQL that the database failed to execute
+	CodeSQLError ErrorCode = "sql_error"
+
+	// CodeTimeout reports an execution that exceeded the query timeout
+	CodeTimeout ErrorCode = "timeout"
+
+	// CodeCancelled reports an execution cancelled by the client
+	CodeCancelled ErrorCode = "cancelled"
+
+	// CodeRateLimited reports a client that exceeded the rate limit
+	CodeRateLimited ErrorCode = "rate_limited"
+
+	// CodeUnavailable reports a server or database that cannot take the
+	// request right now
+	CodeUnavailable ErrorCode = "unavailable"
+
+	// CodeNotFound reports an unknown session, fixture or route
+	CodeNotFound ErrorCode = "not_found"
+
+	// CodeUnauthorized reports a missing or invalid admin token
+	CodeUnauthorized ErrorCode = "unauthorized"
+
+	// CodeForbidden reports a resource that belongs to someone else
+	CodeForbidden ErrorCode = "forbidden"
+
+	// CodeNotImplemented reports a feature the configuration does not support
+	CodeNotImplemented ErrorCode = "not_implemented"
+
+	// CodeInternal reports any other server failure
+	CodeInternal ErrorCode = "internal"
+)
+
+// StatusClientClosedRequest is the non-standard status used for requests
+// the client cancelled
+const StatusClientClosedRequest = 499
+
+// httpStatuses maps error codes to HTTP statuses
+var httpStatuses = map[ErrorCode]int{
+	CodeValidation:      http.StatusBadRequest,
+	CodePolicyViolation: http.StatusForbidden,
+	CodeSQLError:        http.StatusUnprocessableEntity,
+	CodeTimeout:         http.StatusGatewayTimeout,
+	CodeCancelled:       StatusClientClosedRequest,
+	CodeRateLimited:     http.StatusTooManyRequests,
+	CodeUnavailable:     http.StatusServiceUnavailable,
+	CodeNotFound:        http.StatusNotFound,
+	CodeUnauthorized:    http.StatusUnauthorized,
+	CodeForbidden:       http.StatusForbidden,
+	CodeNotImplemented:  http.StatusNotImplemented,
+	CodeInternal:        http.StatusInternalServerError,
+}
+
+// HTTPStatus returns the HTTP status of an error code. Unknown codes are
+// internal errors.
+func (c ErrorCode) HTTPStatus() int {
+	if status, ok := httpStatuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error is an error with a code. Sentinel errors are *Error values, so they
+// work with errors.Is and keep their code when wrapped.
+type Error struct {
+	Code ErrorCode
+	Err  error
+}
+
+// NewError creates an error with a code and a message
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Err: errors.New(message)}
+}
+
+// Errorf creates an error with a code and a formatted message. The %w verb
+// wraps an error like fmt.Errorf.
+func Errorf(code ErrorCode, format string, args ...interface{}) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// WithCode returns err classified with the code, overriding the code of the
+// errors it wraps. A nil error stays nil.
+func WithCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Error returns the message of the error
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf returns the code of an error: the code of the outermost *Error in
+// its chain, timeout or cancelled for context errors, internal otherwise.
+// A nil error has no code.
+func CodeOf(err error) ErrorCode {
+	var coded *Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &coded):
+		return coded.Code
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeCancelled
+	default:
+		return CodeInternal
+	}
+}
 
 // Common domain errors
 var (
 	// Request validation errors
-	ErrEmptyQuery   = errors.New("query cannot be empty")
-	ErrQueryTooLong = errors.New("query exceeds maximum allowed length")
+	ErrEmptyQuery   = NewError(CodeValidation, "query cannot be empty")
+	ErrQueryTooLong = NewError(CodeValidation, "query exceeds maximum allowed length")
 
 	// Security errors
-	ErrDangerousCommand = errors.New("query contains dangerous commands that are not allowed")
-	ErrDropDatabase     = errors.New("DROP DATABASE command is not allowed")
+	ErrDangerousCommand = NewError(CodePolicyViolation, "query contains dangerous commands that are not allowed")
+	ErrDropDatabase     = NewError(CodePolicyViolation, "DROP DATABASE command is not allowed")
+	ErrEventNotAllowed  = NewError(CodePolicyViolation, "scheduled events are not allowed")
+	ErrDefinerClause    = NewError(CodePolicyViolation, "DEFINER clauses are not allowed; routines run as the current user")
 
 	// Execution errors
-	ErrExecutionTimeout = errors.New("query execution timeout exceeded")
-	ErrDatabaseCreation = errors.New("failed to create temporary database")
-	ErrDatabaseCleanup  = errors.New("failed to cleanup temporary database")
+	ErrExecutionTimeout = NewError(CodeTimeout, "query execution timeout exceeded")
+	ErrDatabaseCreation = NewError(CodeInternal, "failed to create temporary database")
+	ErrDatabaseCleanup  = NewError(CodeInternal, "failed to cleanup temporary database")
+
+	// Quota errors
+	ErrQuotaExceeded        = NewError(CodePolicyViolation, "sandbox storage quota exceeded")
+	ErrStorageEngineBlocked = NewError(CodePolicyViolation, "storage engine is not allowed in sandboxes")
+	ErrQuotaVariable        = NewError(CodePolicyViolation, "this variable cannot be changed in sandboxes")
+	ErrForeignSchema        = NewError(CodePolicyViolation, "databases and tables outside the sandbox cannot be created")
 
 	// Connection errors
-	ErrDatabaseConnection = errors.New("failed to connect to MySQL server")
+	ErrDatabaseConnection  = NewError(CodeUnavailable, "failed to connect to MySQL server")
+	ErrDatabaseUnavailable = NewError(CodeUnavailable, "database is temporarily unavailable, try again later")
 )
 
 // Constants
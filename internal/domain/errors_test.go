@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code ErrorCode
+	}{
+		{"nil", nil, ""},
+		{"sentinel", ErrEmptyQuery, CodeValidation},
+		{"wrapped sentinel", fmt.Errorf("%w: 12 bytes", ErrQuotaExceeded), CodePolicyViolation},
+		{"reclassified", WithCode(CodeValidation, ErrDatabaseUnavailable), CodeValidation},
+		{"errorf", Errorf(CodeSQLError, "failed: %w", errors.New("syntax")), CodeSQLError},
+		{"deadline", fmt.Errorf("ping: %w", context.DeadlineExceeded), CodeTimeout},
+		{"cancelled", context.Canceled, CodeCancelled},
+		{"plain", errors.New("boom"), CodeInternal},
+	}
+
+	for _, c := range cases {
+		if got := CodeOf(c.err); got != c.code {
+			t.Errorf("%s: CodeOf = %q, want %q", c.name, got, c.code)
+		}
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	err := Errorf(CodeUnavailable, "Failed to create session: %w", ErrDatabaseUnavailable)
+	if !errors.Is(err, ErrDatabaseUnavailable) {
+		t.Errorf("errors.Is(%v, ErrDatabaseUnavailable) = false", err)
+	}
+	if err.Error() != "Failed to create session: "+ErrDatabaseUnavailable.Error() {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	cases := map[ErrorCode]int{
+		CodeValidation:      http.StatusBadRequest,
+		CodePolicyViolation: http.StatusForbidden,
+		CodeSQLError:        http.StatusUnprocessableEntity,
+		CodeTimeout:         http.StatusGatewayTimeout,
+		CodeCancelled:       StatusClientClosedRequest,
+		CodeRateLimited:     http.StatusTooManyRequests,
+		CodeUnavailable:     http.StatusServiceUnavailable,
+		CodeInternal:        http.StatusInternalServerError,
+		"unknown":           http.StatusInternalServerError,
+	}
+
+	for code, status := range cases {
+		if got := code.HTTPStatus(); got != status {
+			t.Errorf("%q.HTTPStatus() = %d, want %d", code, got, status)
+		}
+	}
+}
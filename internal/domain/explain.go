@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"encoding/json"
+
+	"mysql-tui-editor/server/internal/explain"
+)
+
+// ExplainRequest represents a query plan request
+type ExplainRequest struct {
+	// Setup contains statements (CREATE TABLE, INSERT, ...) executed in the
+	// sandbox before the query is explained
+	Setup string `json:"setup"`
+
+	// Query is the single statement to explain
+	Query string `json:"query" binding:"required"`
+
+	// Analyze additionally runs EXPLAIN ANALYZE (MySQL 8.0.18+), which
+	// executes the query and reports actual row counts and timings
+	Analyze bool `json:"analyze"`
+
+	// Backend selects a named MySQL backend
+	Backend string `json:"backend,omitempty"`
+
+	// Owner is the principal that sent the request. It is set by the server
+	// for bookkeeping.
+	Owner string `json:"-"`
+}
+
+// Validate performs basic validation on the request
+func (r *ExplainRequest) Validate() error {
+	if r.Query == "" {
+		return ErrEmptyQuery
+	}
+
+	if len(r.Setup)+len(r.Query) > MaxQueryLength {
+		return ErrQueryTooLong
+	}
+
+	return nil
+}
+
+// ExplainResponse represents a query plan
+type ExplainResponse struct {
+	// Success indicates if the plan was produced without errors
+	Success bool `json:"success"`
+
+	// Plan is the parsed plan tree
+	Plan *explain.Node `json:"plan,omitempty"`
+
+	// Tree is an ASCII rendering of the plan for terminal clients
+	Tree string `json:"tree,omitempty"`
+
+	// Issues lists the distinct problems found in the plan,
+	// such as full table scans and filesorts
+	Issues []string `json:"issues,omitempty"`
+
+	// Raw is the unmodified EXPLAIN FORMAT=JSON document
+	Raw json.RawMessage `json:"raw,omitempty"`
+
+	// Analyze contains the EXPLAIN ANALYZE output when requested
+	Analyze string `json:"analyze,omitempty"`
+
+	// AnalyzeError explains why EXPLAIN ANALYZE output is missing
+	AnalyzeError string `json:"analyze_error,omitempty"`
+
+	// ServerVersion is the MySQL server version that produced the plan
+	ServerVersion string `json:"server_version,omitempty"`
+
+	// ExecutionTimeMs is the time taken to produce the plan in milliseconds
+	ExecutionTimeMs int64 `json:"execution_time_ms"`
+
+	// Error contains the error message if the plan could not be produced
+	Error string `json:"error"`
+
+	// ErrorCode classifies the error if the plan could not be produced
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+
+	// Sandbox is the name of the temporary database used
+	Sandbox string `json:"-"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+// BackendHealth describes the availability of a MySQL backend. A backend is
+// healthy while at least one of its replicas is.
+type BackendHealth struct {
+	Name     string          `json:"name"`
+	Healthy  bool            `json:"healthy"`
+	Circuit  string          `json:"circuit"`
+	Replicas []ReplicaHealth `json:"replicas"`
+}
+
+// ReplicaHealth describes the availability of one server of a backend
+type ReplicaHealth struct {
+	Address string `json:"address"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CheckResult is the outcome of one liveness or readiness check
+type CheckResult struct {
+	Name       string      `json:"name"`
+	Healthy    bool        `json:"healthy"`
+	DurationMs int64       `json:"duration_ms"`
+	Error      string      `json:"error,omitempty"`
+	Details    interface{} `json:"details,omitempty"`
+}
+
+// PoolStats describes the connection pools of a backend summed over its
+// replicas
+type PoolStats struct {
+	Backend   string  `json:"backend"`
+	Open      int     `json:"open"`
+	InUse     int     `json:"in_use"`
+	MaxOpen   int     `json:"max_open"`
+	WaitCount int64   `json:"wait_count"`
+	Usage     float64 `json:"usage"`
+}
@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// JobRequest submits an execution request as an asynchronous job
+type JobRequest struct {
+	ExecuteRequest
+
+	// Callback posts the finished job to the configured callback URL
+	Callback bool `json:"callback,omitempty"`
+}
+
+// JobStatus is the state of a job
+type JobStatus string
+
+// Job states. Succeeded, failed and cancelled jobs are finished.
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Finished reports whether a job in this state is done
+func (s JobStatus) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// Job is an execution that runs in the background
+type Job struct {
+	ID     string    `json:"id"`
+	Status JobStatus `json:"status"`
+
+	// Owner is the principal that submitted the job
+	Owner string `json:"owner,omitempty"`
+
+	// RequestID is the ID of the request that submitted the job
+	RequestID string `json:"request_id,omitempty"`
+
+	// Callback is set when the finished job is posted to the callback URL
+	Callback bool `json:"callback,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// ExpiresAt is when a finished job is removed
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	Progress JobProgress `json:"progress"`
+
+	// Result is the response of the execution, like /execute would return it
+	Result *ExecuteResponse `json:"result,omitempty"`
+
+	// Error describes why a job without a result failed
+	Error     string    `json:"error,omitempty"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+}
+
+// JobProgress counts the finished statements of a job
+type JobProgress struct {
+	StatementsDone  int `json:"statements_done"`
+	StatementsTotal int `json:"statements_total"`
+}
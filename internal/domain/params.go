@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Params are the values bound to the placeholders of a query. A JSON array
+// binds ? placeholders in order; a JSON object binds :name placeholders.
+// Values must be strings, numbers, booleans or null.
+type Params struct {
+	// Positional holds the values of ? placeholders
+	Positional []interface{}
+
+	// Named holds the values of :name placeholders by name
+	Named map[string]interface{}
+}
+
+// UnmarshalJSON decodes an array of positional or an object of named values.
+// Integers are decoded as int64 and other numbers as float64.
+func (p *Params) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+
+	switch values := raw.(type) {
+	case nil:
+		*p = Params{}
+	case []interface{}:
+		for i, value := range values {
+			converted, err := paramValue(value)
+			if err != nil {
+				return fmt.Errorf("params[%d]: %w", i, err)
+			}
+			values[i] = converted
+		}
+		*p = Params{Positional: values}
+	case map[string]interface{}:
+		for name, value := range values {
+			converted, err := paramValue(value)
+			if err != nil {
+				return fmt.Errorf("params.%s: %w", name, err)
+			}
+			values[name] = converted
+		}
+		*p = Params{Named: values}
+	default:
+		return fmt.Errorf("params must be an array or an object")
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the params like they were received
+func (p Params) MarshalJSON() ([]byte, error) {
+	if p.Named != nil {
+		return json.Marshal(p.Named)
+	}
+	return json.Marshal(p.Positional)
+}
+
+// Len returns the number of values
+func (p *Params) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.Positional) + len(p.Named)
+}
+
+// paramValue converts a decoded JSON value to a driver value
+func paramValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, string, bool:
+		return v, nil
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i, nil
+		}
+		return strconv.ParseFloat(string(v), 64)
+	default:
+		return nil, fmt.Errorf("value must be a string, number, boolean or null")
+	}
+}
+
+// ParamType returns the type name of a bound value reported in results
+func ParamType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case int64:
+		return "integer"
+	case float64:
+		return "double"
+	case bool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// PreparedStatement describes a statement executed as a server-side
+// prepared statement, or a PREPARE, EXECUTE or DEALLOCATE PREPARE statement
+type PreparedStatement struct {
+	// Name is the name of a statement prepared with PREPARE
+	Name string `json:"name,omitempty"`
+
+	// ParamCount is the number of ? placeholders of the prepared statement,
+	// or -1 when it is unknown
+	ParamCount int `json:"param_count"`
+
+	// Params describes the bound values in placeholder order
+	Params []BoundParam `json:"params,omitempty"`
+}
+
+// BoundParam describes a value bound to a placeholder
+type BoundParam struct {
+	// Position is the 1-based position of the placeholder in the statement
+	Position int `json:"position"`
+
+	// Name is the name of a :name placeholder or the user variable of
+	// EXECUTE ... USING
+	Name string `json:"name,omitempty"`
+
+	// Type is the type of the value: string, integer, double, boolean or null
+	Type string `json:"type,omitempty"`
+
+	// Value is the bound value. It is null for user variables.
+	Value interface{} `json:"value"`
+}
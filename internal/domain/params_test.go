@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParamsUnmarshal(t *testing.T) {
+	var req ExecuteRequest
+	if err := json.Unmarshal([]byte(`{"query": "SELECT ?", "params": [1, 2.5, "x", true, null]}`), &req); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	want := []interface{}{int64(1), 2.5, "x", true, nil}
+	if !reflect.DeepEqual(req.Params.Positional, want) {
+		t.Errorf("Positional = %#v, want %#v", req.Params.Positional, want)
+	}
+
+	req = ExecuteRequest{}
+	if err := json.Unmarshal([]byte(`{"query": "SELECT :id", "params": {"id": 9007199254740993}}`), &req); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if req.Params.Named["id"] != int64(9007199254740993) {
+		t.Errorf("Named = %#v", req.Params.Named)
+	}
+
+	for _, invalid := range []string{`"x"`, `[[1]]`, `{"a": {"b": 1}}`} {
+		var params Params
+		if err := json.Unmarshal([]byte(invalid), &params); err == nil {
+			t.Errorf("Unmarshal(%s) expected error", invalid)
+		}
+	}
+}
@@ -1,12 +1,54 @@
 package domain
 
+import "time"
+
 // ExecuteRequest represents a SQL query execution request from the client
 type ExecuteRequest struct {
 	// Query contains the SQL code to execute
 	// Can contain multiple statements separated by semicolons
 	Query string `json:"query" binding:"required"`
+
+	// OutputMode selects how result sets are rendered:
+	// table (default), vertical, batch, html or markdown.
+	// A statement terminated by \G is always rendered vertically.
+	OutputMode string `json:"output_mode,omitempty"`
+
+	// Fixture names a fixture script that prepares the sandbox
+	// before the query runs
+	Fixture string `json:"fixture,omitempty"`
+
+	// Transactional runs the whole script in a transaction that is always
+	// rolled back, for "what-if" experiments. Statements that would end the
+	// transaction are rejected.
+	Transactional bool `json:"transactional,omitempty"`
+
+	// Engine selects the database engine: mysql (default), sqlite or postgres
+	Engine string `json:"engine,omitempty"`
+
+	// Backend selects a named MySQL backend, e.g. a server version. By
+	// default the backend of the fixture or the default backend is used.
+	Backend string `json:"backend,omitempty"`
+
+	// Params binds values to the ? or :name placeholders of the query. The
+	// statements with placeholders run as server-side prepared statements.
+	Params *Params `json:"params,omitempty"`
+
+	// Owner is the principal that sent the request. It is set by the server
+	// for bookkeeping.
+	Owner string `json:"-"`
+
+	// Timeout replaces the configured query timeout, e.g. for jobs. It is
+	// set by the server.
+	Timeout time.Duration `json:"-"`
 }
 
+// Database engines
+const (
+	EngineMySQL    = "mysql"
+	EngineSQLite   = "sqlite"
+	EnginePostgres = "postgres"
+)
+
 // Validate performs basic validation on the request
 func (r *ExecuteRequest) Validate() error {
 	if r.Query == "" {
@@ -13,6 +13,102 @@ type ExecuteResponse struct {
 
 	// Error contains the error message if execution failed
 	Error string `json:"error"`
+
+	// ErrorCode classifies the error if execution failed
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+
+	// Engine is the database engine that executed the query
+	Engine string `json:"engine,omitempty"`
+
+	// Backend is the named MySQL backend that executed the query
+	Backend string `json:"backend,omitempty"`
+
+	// Statements contains the outcome of every statement that was attempted
+	Statements []StatementResult `json:"statements,omitempty"`
+
+	// Transaction is the transaction state of the sandbox after execution
+	Transaction *TransactionState `json:"transaction,omitempty"`
+
+	// Sandbox is the name of the temporary database used for execution.
+	// It is kept for auditing and never sent to clients.
+	Sandbox string `json:"-"`
+}
+
+// StatementResult describes the outcome of a single statement of a script
+type StatementResult struct {
+	// Index is the 1-based position of the statement in the script
+	Index int `json:"index"`
+
+	// Statement is the SQL text of the statement
+	Statement string `json:"statement"`
+
+	// Success indicates if the statement executed without errors
+	Success bool `json:"success"`
+
+	// RowsAffected is the number of rows changed by a non-SELECT statement
+	RowsAffected int64 `json:"rows_affected"`
+
+	// LastInsertID is the AUTO_INCREMENT value generated by an INSERT, if any
+	LastInsertID int64 `json:"last_insert_id,omitempty"`
+
+	// RowsReturned is the number of rows in the result sets of a SELECT-like
+	// statement or a procedure call
+	RowsReturned int64 `json:"rows_returned"`
+
+	// ResultSets holds the rows returned by a SELECT-like statement or a
+	// procedure call for exports. A CALL may return several result sets.
+	ResultSets []*ResultSet `json:"-"`
+
+	// ExecutionTimeMs is the time taken to execute the statement in milliseconds
+	ExecutionTimeMs int64 `json:"execution_time_ms"`
+
+	// WarningCount is the number of warnings raised by the statement
+	WarningCount int `json:"warning_count"`
+
+	// Warnings contains the warnings reported by SHOW WARNINGS
+	Warnings []Warning `json:"warnings,omitempty"`
+
+	// InTransaction reports whether a transaction was open after the statement
+	InTransaction bool `json:"in_transaction"`
+
+	// Prepared describes the prepared statement the statement ran as, or the
+	// statement it prepared, executed or deallocated
+	Prepared *PreparedStatement `json:"prepared,omitempty"`
+
+	// Error contains the error message if the statement failed
+	Error string `json:"error,omitempty"`
+}
+
+// Reasons for an automatic rollback
+const (
+	RollbackError         = "error"
+	RollbackEnd           = "end_of_execution"
+	RollbackTransactional = "transactional"
+	RollbackRestore       = "restore"
+)
+
+// TransactionState describes the transaction state of a sandbox connection
+type TransactionState struct {
+	// InTransaction reports whether a transaction is open
+	InTransaction bool `json:"in_transaction"`
+
+	// Autocommit is the value of the autocommit session variable
+	Autocommit bool `json:"autocommit"`
+
+	// RolledBack reports whether the server rolled back an open transaction
+	// automatically during the request
+	RolledBack bool `json:"rolled_back,omitempty"`
+
+	// RollbackReason is one of RollbackError, RollbackEnd,
+	// RollbackTransactional or RollbackRestore
+	RollbackReason string `json:"rollback_reason,omitempty"`
+}
+
+// Warning is a single MySQL warning or note raised by a statement
+type Warning struct {
+	Level   string `json:"level"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
 }
 
 // NewSuccessResponse creates a successful response
@@ -26,11 +122,34 @@ func NewSuccessResponse(output string, executionTimeMs int64) *ExecuteResponse {
 }
 
 // NewErrorResponse creates an error response
-func NewErrorResponse(errorMsg string) *ExecuteResponse {
+func NewErrorResponse(code ErrorCode, errorMsg string) *ExecuteResponse {
 	return &ExecuteResponse{
 		Success:         false,
 		Output:          "",
 		ExecutionTimeMs: 0,
 		Error:           errorMsg,
+		ErrorCode:       code,
+	}
+}
+
+// ErrorEnvelope is the body of every error response that carries no
+// execution result
+type ErrorEnvelope struct {
+	// Success is always false
+	Success bool `json:"success"`
+
+	// Error is the human-readable error message
+	Error string `json:"error"`
+
+	// ErrorCode is the stable class of the error
+	ErrorCode ErrorCode `json:"error_code"`
+}
+
+// NewErrorEnvelope creates the error envelope of an error
+func NewErrorEnvelope(err error) *ErrorEnvelope {
+	return &ErrorEnvelope{
+		Success:   false,
+		Error:     err.Error(),
+		ErrorCode: CodeOf(err),
 	}
 }
@@ -0,0 +1,85 @@
+package domain
+
+// Column describes a result set column
+type Column struct {
+	// Name is the column label
+	Name string `json:"name"`
+
+	// Type is the database type name reported by the driver (e.g. "VARCHAR")
+	Type string `json:"type"`
+
+	// Numeric marks columns holding numbers, which are right-aligned in text
+	// output and written as numbers in structured exports
+	Numeric bool `json:"numeric"`
+
+	// Scale is the number of fractional digits of DECIMAL and temporal columns
+	Scale int `json:"scale,omitempty"`
+}
+
+// Value is a single rendered cell
+type Value struct {
+	Text string
+	Null bool
+}
+
+// String returns the cell text, or NULL for SQL NULL
+func (v Value) String() string {
+	if v.Null {
+		return "NULL"
+	}
+	return v.Text
+}
+
+// ResultSet is a fully read result set
+type ResultSet struct {
+	Columns []Column
+	Rows    [][]Value
+}
+
+// RowIterator iterates over the rows of a result set. It is the common
+// abstraction consumed by text formatters and exporters.
+type RowIterator interface {
+	// Columns returns the result set columns
+	Columns() []Column
+
+	// Next advances to the next row and reports whether one is available
+	Next() bool
+
+	// Row returns the current row
+	Row() []Value
+
+	// Err returns the error, if any, that stopped the iteration
+	Err() error
+}
+
+// Iterator returns a RowIterator over the rows of the result set
+func (rs *ResultSet) Iterator() RowIterator {
+	return &resultSetIterator{rs: rs, pos: -1}
+}
+
+// resultSetIterator iterates over an in-memory result set
+type resultSetIterator struct {
+	rs  *ResultSet
+	pos int
+}
+
+func (it *resultSetIterator) Columns() []Column { return it.rs.Columns }
+func (it *resultSetIterator) Row() []Value      { return it.rs.Rows[it.pos] }
+func (it *resultSetIterator) Err() error        { return nil }
+
+func (it *resultSetIterator) Next() bool {
+	if it.pos+1 >= len(it.rs.Rows) {
+		return false
+	}
+	it.pos++
+	return true
+}
+
+// CollectRows reads all remaining rows of an iterator into a result set
+func CollectRows(it RowIterator) (*ResultSet, error) {
+	rs := &ResultSet{Columns: it.Columns()}
+	for it.Next() {
+		rs.Rows = append(rs.Rows, it.Row())
+	}
+	return rs, it.Err()
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Schema describes the objects of a sandbox database for editor autocomplete
+type Schema struct {
+	// Database is the sandbox database name
+	Database string `json:"database,omitempty"`
+
+	Tables   []TableInfo   `json:"tables"`
+	Views    []ViewInfo    `json:"views"`
+	Routines []RoutineInfo `json:"routines"`
+
+	// ETag changes whenever any of the objects above changes
+	ETag string `json:"etag"`
+}
+
+// TableInfo describes a base table
+type TableInfo struct {
+	Name        string           `json:"name"`
+	Engine      string           `json:"engine,omitempty"`
+	Comment     string           `json:"comment,omitempty"`
+	Columns     []ColumnInfo     `json:"columns"`
+	Indexes     []IndexInfo      `json:"indexes"`
+	ForeignKeys []ForeignKeyInfo `json:"foreign_keys"`
+}
+
+// ColumnInfo describes a table or view column
+type ColumnInfo struct {
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Nullable bool    `json:"nullable"`
+	Default  *string `json:"default"`
+	Key      string  `json:"key,omitempty"`
+	Extra    string  `json:"extra,omitempty"`
+	Comment  string  `json:"comment,omitempty"`
+}
+
+// IndexInfo describes an index with its columns in index order
+type IndexInfo struct {
+	Name    string   `json:"name"`
+	Unique  bool     `json:"unique"`
+	Type    string   `json:"type,omitempty"`
+	Columns []string `json:"columns"`
+}
+
+// ForeignKeyInfo describes a foreign key constraint
+type ForeignKeyInfo struct {
+	Name              string   `json:"name"`
+	Columns           []string `json:"columns"`
+	ReferencedTable   string   `json:"referenced_table"`
+	ReferencedColumns []string `json:"referenced_columns"`
+	OnUpdate          string   `json:"on_update,omitempty"`
+	OnDelete          string   `json:"on_delete,omitempty"`
+}
+
+// ViewInfo describes a view
+type ViewInfo struct {
+	Name    string       `json:"name"`
+	Columns []ColumnInfo `json:"columns"`
+}
+
+// RoutineInfo describes a stored procedure or function
+type RoutineInfo struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	ReturnType string `json:"return_type,omitempty"`
+}
+
+// ComputeETag sets ETag to a hash of the schema contents. The database name
+// is excluded so that fixture schemas keep their ETag across sandboxes.
+func (s *Schema) ComputeETag() {
+	contents, _ := json.Marshal(struct {
+		Tables   []TableInfo
+		Views    []ViewInfo
+		Routines []RoutineInfo
+	}{s.Tables, s.Views, s.Routines})
+
+	sum := sha256.Sum256(contents)
+	s.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+}
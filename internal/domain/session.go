@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// CreateSessionRequest represents a request to open a persistent session
+type CreateSessionRequest struct {
+	// Fixture names a fixture script that prepares the session sandbox
+	Fixture string `json:"fixture,omitempty"`
+
+	// Backend selects a named MySQL backend for the session sandbox
+	Backend string `json:"backend,omitempty"`
+
+	// Snapshot restores a snapshot into the session sandbox instead of a
+	// fixture. The session is created on the backend of the snapshot by
+	// default.
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+// SessionInfo describes a persistent session
+type SessionInfo struct {
+	ID           string    `json:"id"`
+	Owner        string    `json:"owner,omitempty"`
+	Fixture      string    `json:"fixture,omitempty"`
+	Backend      string    `json:"backend"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActivity time.Time `json:"last_activity"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+
+	// Sandbox is the name of the session database. It is only reported to
+	// operators.
+	Sandbox string `json:"sandbox,omitempty"`
+}
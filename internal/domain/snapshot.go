@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// CreateSnapshotRequest represents a request to snapshot a session sandbox
+type CreateSnapshotRequest struct {
+	// Name describes the snapshot, e.g. "before DELETE"
+	Name string `json:"name,omitempty"`
+}
+
+// RestoreSnapshotRequest selects the snapshot restored into a session
+type RestoreSnapshotRequest struct {
+	Snapshot string `json:"snapshot" binding:"required"`
+}
+
+// SnapshotInfo describes a saved state of a session sandbox
+type SnapshotInfo struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Owner string `json:"owner,omitempty"`
+
+	// SessionID is the session the snapshot was taken of
+	SessionID string `json:"session_id"`
+
+	// Backend is the backend of that session
+	Backend string `json:"backend"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	Tables   int   `json:"tables"`
+	Views    int   `json:"views"`
+	Routines int   `json:"routines"`
+	Triggers int   `json:"triggers"`
+	Rows     int64 `json:"rows"`
+
+	// SizeBytes is the size of the SQL dump
+	SizeBytes int64 `json:"size_bytes"`
+}
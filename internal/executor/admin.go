@@ -0,0 +1,400 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// tempSizeQuery sums the session temporary tablespaces that hold the
+// temporary tables created by users, which information_schema.TABLES does
+// not list. The space is released when the connection closes.
+const tempSizeQuery = `SELECT ID, COALESCE(SUM(SIZE), 0)
+	FROM information_schema.INNODB_SESSION_TEMP_TABLESPACES
+	WHERE PURPOSE = 'USER'`
+
+// reserveSandbox records the name of a sandbox that is being created
+func (e *MySQLExecutor) reserveSandbox(name string) {
+	e.sandboxesMu.Lock()
+	defer e.sandboxesMu.Unlock()
+	e.sandboxes[name] = nil
+}
+
+// registerSandbox records a created sandbox
+func (e *MySQLExecutor) registerSandbox(sandbox *Sandbox) {
+	e.sandboxesMu.Lock()
+	defer e.sandboxesMu.Unlock()
+	e.sandboxes[sandbox.dbName] = sandbox
+}
+
+// unregisterSandbox forgets a sandbox
+func (e *MySQLExecutor) unregisterSandbox(name string) {
+	e.sandboxesMu.Lock()
+	defer e.sandboxesMu.Unlock()
+	delete(e.sandboxes, name)
+}
+
+// liveSandboxes returns the created sandboxes
+func (e *MySQLExecutor) liveSandboxes() []*Sandbox {
+	e.sandboxesMu.Lock()
+	defer e.sandboxesMu.Unlock()
+
+	sandboxes := make([]*Sandbox, 0, len(e.sandboxes))
+	for _, sandbox := range e.sandboxes {
+		if sandbox != nil {
+			sandboxes = append(sandboxes, sandbox)
+		}
+	}
+	return sandboxes
+}
+
+// isLive reports whether a sandbox with the given name exists or is being created
+func (e *MySQLExecutor) isLive(name string) bool {
+	e.sandboxesMu.Lock()
+	defer e.sandboxesMu.Unlock()
+	_, ok := e.sandboxes[name]
+	return ok
+}
+
+// SandboxCount returns the number of live sandboxes
+func (e *MySQLExecutor) SandboxCount() int {
+	e.sandboxesMu.Lock()
+	defer e.sandboxesMu.Unlock()
+	return len(e.sandboxes)
+}
+
+// Sandboxes describes the live sandboxes of the backend, including their
+// size and the statement currently running on their connection
+func (e *MySQLExecutor) Sandboxes(ctx context.Context) []domain.SandboxInfo {
+	byReplica := make(map[*replica][]*Sandbox)
+	for _, sandbox := range e.liveSandboxes() {
+		byReplica[sandbox.replica] = append(byReplica[sandbox.replica], sandbox)
+	}
+
+	now := time.Now()
+	var infos []domain.SandboxInfo
+	for r, sandboxes := range byReplica {
+		// Details are best effort, the list itself comes from memory
+		sizes, err := r.schemaSizes(ctx, e.dbPrefix)
+		if err != nil {
+			fmt.Printf("WARNING: Failed to read sandbox sizes on %s: %v\n", r.address, err)
+		}
+		tempSizes, err := r.tempSizes(ctx)
+		if err != nil {
+			fmt.Printf("WARNING: Failed to read temporary table sizes on %s: %v\n", r.address, err)
+		}
+		processes, err := r.processes(ctx)
+		if err != nil {
+			fmt.Printf("WARNING: Failed to read processlist on %s: %v\n", r.address, err)
+		}
+
+		for _, sandbox := range sandboxes {
+			info := domain.SandboxInfo{
+				Name:         sandbox.dbName,
+				Backend:      e.name,
+				Replica:      r.address,
+				Owner:        sandbox.owner,
+				CreatedAt:    sandbox.createdAt,
+				AgeSeconds:   int64(now.Sub(sandbox.createdAt).Seconds()),
+				LastActivity: time.Unix(0, sandbox.lastActivity.Load()).UTC(),
+				SizeBytes:    sizes[sandbox.dbName] + tempSizes[sandbox.connectionID],
+				ConnectionID: sandbox.connectionID,
+			}
+			if p, ok := processes[sandbox.connectionID]; ok && p.info != "" {
+				info.CurrentStatement = p.info
+				info.StatementSeconds = p.seconds
+			}
+			infos = append(infos, info)
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+// KillSandbox aborts the statement running in the named sandbox
+func (e *MySQLExecutor) KillSandbox(ctx context.Context, name string) error {
+	e.sandboxesMu.Lock()
+	sandbox := e.sandboxes[name]
+	e.sandboxesMu.Unlock()
+
+	if sandbox == nil {
+		return nil
+	}
+
+	_, err := sandbox.replica.db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", sandbox.connectionID))
+	return err
+}
+
+// orphans returns the databases with the sandbox prefix of the backend for
+// which isLive returns false and whose tables did not change within minAge.
+// Replicas that cannot be queried are skipped.
+func (e *MySQLExecutor) orphans(ctx context.Context, isLive func(string) bool, minAge time.Duration) []domain.OrphanSchema {
+	var orphans []domain.OrphanSchema
+	for _, r := range e.replicas {
+		found, err := r.orphans(ctx, e.dbPrefix, isLive, minAge)
+		if err != nil {
+			fmt.Printf("WARNING: Failed to list orphan schemas on %s: %v\n", r.address, err)
+			continue
+		}
+
+		for i := range found {
+			found[i].Backend = e.name
+		}
+		orphans = append(orphans, found...)
+	}
+	return orphans
+}
+
+// orphans returns the databases with the given prefix on the replica for
+// which isLive returns false and whose tables did not change within minAge.
+// Databases without tables have no recorded age and are always returned.
+func (r *replica) orphans(ctx context.Context, prefix string, isLive func(string) bool, minAge time.Duration) ([]domain.OrphanSchema, error) {
+	sizes, err := r.schemaSizes(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	disableStatsCache(ctx, conn)
+
+	// The idle time is computed by the server, so that it does not depend
+	// on the time zone and parse_time settings of the connection
+	rows, err := conn.QueryContext(ctx, `
+		SELECT s.SCHEMA_NAME,
+			TIMESTAMPDIFF(SECOND, MAX(GREATEST(t.CREATE_TIME, COALESCE(t.UPDATE_TIME, t.CREATE_TIME))), NOW())
+		FROM information_schema.SCHEMATA s
+		LEFT JOIN information_schema.TABLES t ON t.TABLE_SCHEMA = s.SCHEMA_NAME
+		WHERE s.SCHEMA_NAME LIKE ?
+		GROUP BY s.SCHEMA_NAME
+		ORDER BY s.SCHEMA_NAME`, likePrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orphans []domain.OrphanSchema
+	for rows.Next() {
+		var name string
+		var idle sql.NullInt64
+		if err := rows.Scan(&name, &idle); err != nil {
+			return nil, err
+		}
+		if isLive(name) {
+			continue
+		}
+
+		orphan := domain.OrphanSchema{
+			Name:      name,
+			Replica:   r.address,
+			SizeBytes: sizes[name],
+		}
+		if idle.Valid {
+			if time.Duration(idle.Int64)*time.Second < minAge {
+				continue
+			}
+			orphan.IdleSeconds = &idle.Int64
+		}
+		orphans = append(orphans, orphan)
+	}
+	return orphans, rows.Err()
+}
+
+// dropOrphan drops an orphan database on its replica
+func (e *MySQLExecutor) dropOrphan(ctx context.Context, orphan *domain.OrphanSchema) {
+	for _, r := range e.replicas {
+		if r.address != orphan.Replica {
+			continue
+		}
+
+		if _, err := r.db.ExecContext(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", orphan.Name)); err != nil {
+			orphan.Error = err.Error()
+			return
+		}
+		orphan.Dropped = true
+		return
+	}
+}
+
+// process is a row of the server processlist
+type process struct {
+	info    string
+	seconds int64
+}
+
+// processes returns the processlist of the replica by connection id. Without
+// the PROCESS privilege only the connections of the server user are listed,
+// which includes all sandbox connections.
+func (r *replica) processes(ctx context.Context) (map[int64]process, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT ID, COALESCE(INFO, ''), COALESCE(TIME, 0) FROM information_schema.PROCESSLIST`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	processes := make(map[int64]process)
+	for rows.Next() {
+		var id int64
+		var p process
+		if err := rows.Scan(&id, &p.info, &p.seconds); err != nil {
+			return nil, err
+		}
+		processes[id] = p
+	}
+	return processes, rows.Err()
+}
+
+// schemaSizes returns the data and index length of the databases with the
+// given prefix by database name
+func (r *replica) schemaSizes(ctx context.Context, prefix string) (map[string]int64, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	disableStatsCache(ctx, conn)
+
+	rows, err := conn.QueryContext(ctx, `
+		SELECT TABLE_SCHEMA, COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0)
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA LIKE ?
+		GROUP BY TABLE_SCHEMA`, likePrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sizes := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var size int64
+		if err := rows.Scan(&name, &size); err != nil {
+			return nil, err
+		}
+		sizes[name] = size
+	}
+	return sizes, rows.Err()
+}
+
+// tempSizes returns the size of the temporary tables of every connection by
+// connection ID. Servers before MySQL 8.0.13 and MariaDB have no session
+// temporary tablespaces and report no sizes.
+func (r *replica) tempSizes(ctx context.Context) (map[int64]int64, error) {
+	rows, err := r.db.QueryContext(ctx, tempSizeQuery+" GROUP BY ID")
+	if isUnknownTable(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sizes := make(map[int64]int64)
+	for rows.Next() {
+		var id, size int64
+		if err := rows.Scan(&id, &size); err != nil {
+			return nil, err
+		}
+		sizes[id] = size
+	}
+	return sizes, rows.Err()
+}
+
+// isUnknownTable reports whether err is the error of a server that does not
+// know an information_schema table
+func isUnknownTable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1109
+}
+
+// likePrefix returns a LIKE pattern matching strings that start with prefix
+func likePrefix(prefix string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return escaper.Replace(prefix) + "%"
+}
+
+// Sandboxes describes the live sandboxes of every backend
+func (b *Backends) Sandboxes(ctx context.Context) []domain.SandboxInfo {
+	infos := []domain.SandboxInfo{}
+	for _, name := range b.names {
+		infos = append(infos, b.backends[name].Sandboxes(ctx)...)
+	}
+	return infos
+}
+
+// SandboxCount returns the number of live sandboxes of every backend
+func (b *Backends) SandboxCount() int {
+	count := 0
+	for _, exec := range b.backends {
+		count += exec.SandboxCount()
+	}
+	return count
+}
+
+// Orphans lists the databases that carry a sandbox prefix but belong to no
+// live sandbox, e.g. after a crash. Backends may share servers and
+// prefixes, so a database is only an orphan if no backend knows it. Other
+// servers sharing MySQL are told apart by executor.instance_id; databases
+// changed within admin.orphan_min_age are left alone in any case.
+func (b *Backends) Orphans(ctx context.Context) []domain.OrphanSchema {
+	orphans := []domain.OrphanSchema{}
+	seen := make(map[string]bool)
+	for _, name := range b.names {
+		// Report a database once when several backends share a server
+		for _, orphan := range b.backends[name].orphans(ctx, b.isLive, b.orphanMinAge) {
+			key := orphan.Replica + "/" + orphan.Name
+			if !seen[key] {
+				seen[key] = true
+				orphans = append(orphans, orphan)
+			}
+		}
+	}
+	return orphans
+}
+
+// DropOrphans drops the databases reported by Orphans
+func (b *Backends) DropOrphans(ctx context.Context) []domain.OrphanSchema {
+	orphans := b.Orphans(ctx)
+
+	for i := range orphans {
+		// Sandbox names are random, but check again to be safe
+		if b.isLive(orphans[i].Name) {
+			continue
+		}
+		b.backends[orphans[i].Backend].dropOrphan(ctx, &orphans[i])
+
+		// Any backend on the server may have failed to drop it
+		if orphans[i].Dropped {
+			for _, exec := range b.backends {
+				exec.forgetFailedDrop(orphans[i].Name)
+			}
+		}
+	}
+	return orphans
+}
+
+// isLive reports whether any backend knows a sandbox with the given name
+func (b *Backends) isLive(name string) bool {
+	for _, exec := range b.backends {
+		if exec.isLive(name) {
+			return true
+		}
+	}
+	return false
+}
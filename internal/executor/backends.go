@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/fixture"
+)
+
+// ErrUnknownBackend is returned for backends that are not configured
+var ErrUnknownBackend = domain.NewError(domain.CodeValidation, "unknown backend")
+
+// fixtureBackendKey is the fixture metadata key naming the backend the
+// fixture needs, e.g. "-- @backend: mysql57"
+const fixtureBackendKey = "backend"
+
+// Backends routes MySQL executions to named backends, e.g. one per server
+// version. It is the executor of the mysql engine.
+type Backends struct {
+	backends    map[string]*MySQLExecutor
+	names       []string
+	defaultName string
+	fixtures    *fixture.Store
+
+	// orphanMinAge protects recently changed databases from the orphan
+	// cleanup, e.g. sandboxes of other servers sharing MySQL
+	orphanMinAge time.Duration
+}
+
+// NewBackends creates an executor for every configured backend
+func NewBackends(cfg *config.Config, fixtures *fixture.Store) (*Backends, error) {
+	b := &Backends{
+		backends:     make(map[string]*MySQLExecutor),
+		fixtures:     fixtures,
+		orphanMinAge: cfg.Admin.OrphanMinAge,
+	}
+
+	for _, backend := range cfg.MySQLBackends() {
+		if _, ok := b.backends[backend.Name]; ok || backend.Name == "" {
+			b.Close()
+			return nil, fmt.Errorf("backend names must be unique and non-empty: %q", backend.Name)
+		}
+
+		exec, err := NewMySQLExecutor(cfg, backend, fixtures)
+		if err != nil {
+			b.Close()
+			return nil, fmt.Errorf("backend %s: %w", backend.Name, err)
+		}
+
+		b.backends[backend.Name] = exec
+		b.names = append(b.names, backend.Name)
+	}
+
+	b.defaultName = cfg.Executor.DefaultBackend
+	if b.defaultName == "" {
+		b.defaultName = b.names[0]
+	}
+	if _, ok := b.backends[b.defaultName]; !ok {
+		b.Close()
+		return nil, fmt.Errorf("%w: default backend %q", ErrUnknownBackend, b.defaultName)
+	}
+
+	return b, nil
+}
+
+// Engine returns the engine name
+func (b *Backends) Engine() string {
+	return domain.EngineMySQL
+}
+
+// Get returns the executor of the named backend. An empty name selects the
+// default backend.
+func (b *Backends) Get(name string) (*MySQLExecutor, error) {
+	if name == "" {
+		name = b.defaultName
+	}
+
+	exec, ok := b.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %v)", ErrUnknownBackend, name, b.names)
+	}
+	return exec, nil
+}
+
+// Default returns the executor of the default backend
+func (b *Backends) Default() *MySQLExecutor {
+	return b.backends[b.defaultName]
+}
+
+// Resolve returns the executor for a request: the requested backend, else
+// the backend named in the fixture metadata, else the default backend
+func (b *Backends) Resolve(backend string, fixtureName string) (*MySQLExecutor, error) {
+	if backend == "" && fixtureName != "" {
+		if f, err := b.fixtures.Get(fixtureName); err == nil {
+			backend = f.Metadata[fixtureBackendKey]
+		}
+	}
+	return b.Get(backend)
+}
+
+// Fixtures returns the fixture store
+func (b *Backends) Fixtures() *fixture.Store {
+	return b.fixtures
+}
+
+// Execute executes the request on the backend it resolves to
+func (b *Backends) Execute(ctx context.Context, req *domain.ExecuteRequest) (*domain.ExecuteResponse, error) {
+	exec, err := b.Resolve(req.Backend, req.Fixture)
+	if err != nil {
+		return domain.NewErrorResponse(domain.CodeOf(err), err.Error()), nil
+	}
+	return exec.Execute(ctx, req)
+}
+
+// FixtureSchema returns the schema of a fixture loaded on its backend
+func (b *Backends) FixtureSchema(ctx context.Context, name string) (*domain.Schema, error) {
+	exec, err := b.Resolve("", name)
+	if err != nil {
+		return nil, err
+	}
+	return exec.FixtureSchema(ctx, name)
+}
+
+// Ping checks every backend and returns the first error
+func (b *Backends) Ping(ctx context.Context) error {
+	for _, name := range b.names {
+		if err := b.backends[name].Ping(ctx); err != nil {
+			return fmt.Errorf("backend %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Health checks every backend independently
+func (b *Backends) Health(ctx context.Context) []domain.BackendHealth {
+	health := make([]domain.BackendHealth, 0, len(b.names))
+	for _, name := range b.names {
+		health = append(health, b.backends[name].Health(ctx))
+	}
+	return health
+}
+
+// SetValidator sets the validator that checks the text of statements
+// prepared with PREPARE on every backend. It must be called before the
+// backends are used.
+func (b *Backends) SetValidator(validator StatementValidator) {
+	for _, exec := range b.backends {
+		exec.validator = validator
+	}
+}
+
+// Close closes every backend and returns the first error
+func (b *Backends) Close() error {
+	var firstErr error
+	for _, exec := range b.backends {
+		if err := exec.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mysql-tui-editor/server/internal/fixture"
+)
+
+func TestCandidates(t *testing.T) {
+	a := &replica{address: "a"}
+	b := &replica{address: "b"}
+	c := &replica{address: "c"}
+	a.healthy.Store(true)
+	c.healthy.Store(true)
+
+	exec := &MySQLExecutor{replicas: []*replica{a, b, c}}
+
+	// Healthy replicas take turns, the unhealthy one is tried last
+	want := [][]string{
+		{"a", "c", "b"},
+		{"c", "a", "b"},
+		{"a", "c", "b"},
+		{"c", "a", "b"},
+	}
+	for i, expected := range want {
+		var got []string
+		for _, r := range exec.candidates() {
+			got = append(got, r.address)
+		}
+		if len(got) != len(expected) {
+			t.Fatalf("Call %d: expected %v, got %v", i, expected, got)
+		}
+		for j := range got {
+			if got[j] != expected[j] {
+				t.Errorf("Call %d: expected %v, got %v", i, expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestBackendsResolve(t *testing.T) {
+	dir := t.TempDir()
+	script := "-- @backend: mysql57\nCREATE TABLE t (id INT);\n"
+	if err := os.WriteFile(filepath.Join(dir, "legacy.sql"), []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plain.sql"), []byte("SELECT 1;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures, err := fixture.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backends := &Backends{
+		backends: map[string]*MySQLExecutor{
+			"mysql80": {name: "mysql80"},
+			"mysql57": {name: "mysql57"},
+		},
+		names:       []string{"mysql80", "mysql57"},
+		defaultName: "mysql80",
+		fixtures:    fixtures,
+	}
+
+	tests := []struct {
+		backend string
+		fixture string
+		want    string
+	}{
+		{"", "", "mysql80"},
+		{"mysql57", "", "mysql57"},
+		{"", "legacy", "mysql57"},
+		{"", "plain", "mysql80"},
+		{"mysql80", "legacy", "mysql80"},
+	}
+
+	for _, tt := range tests {
+		exec, err := backends.Resolve(tt.backend, tt.fixture)
+		if err != nil {
+			t.Errorf("Resolve(%q, %q): unexpected error %v", tt.backend, tt.fixture, err)
+			continue
+		}
+		if exec.Name() != tt.want {
+			t.Errorf("Resolve(%q, %q) = %s, want %s", tt.backend, tt.fixture, exec.Name(), tt.want)
+		}
+	}
+
+	if _, err := backends.Resolve("oracle", ""); !errors.Is(err, ErrUnknownBackend) {
+		t.Errorf("Expected ErrUnknownBackend, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"sync"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// Circuit states reported in backend health
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half_open"
+)
+
+// breaker fails sandbox creation fast while a backend is down, instead of
+// letting every request wait for connection timeouts. It opens after
+// threshold consecutive connection failures or a failed health check, and
+// lets a single trial through once the cooldown has passed.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// newBreaker creates a breaker; a threshold of 0 disables it
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow returns domain.ErrDatabaseUnavailable while the circuit is open
+func (b *breaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	if time.Now().Before(b.openUntil) || b.trial {
+		return domain.ErrDatabaseUnavailable
+	}
+
+	// Half-open: one request tries the backend
+	b.trial = true
+	return nil
+}
+
+// success closes the circuit
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures >= b.threshold && b.threshold > 0 {
+		println("Circuit closed, MySQL is reachable again")
+	}
+	b.failures = 0
+	b.trial = false
+}
+
+// failure records a connection failure and opens the circuit at the
+// threshold
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// release ends a trial without an outcome, e.g. when the request was
+// cancelled, so that the next request may try again
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// trip opens the circuit at once, e.g. when no replica answers a health check
+func (b *breaker) trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		b.failures = b.threshold
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// state returns the circuit state
+func (b *breaker) state() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.threshold <= 0 || b.failures < b.threshold:
+		return circuitClosed
+	case time.Now().Before(b.openUntil) || b.trial:
+		return circuitOpen
+	}
+	return circuitHalfOpen
+}
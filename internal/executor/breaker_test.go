@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+func TestBreaker(t *testing.T) {
+	b := newBreaker(2, time.Hour)
+
+	b.failure()
+	if err := b.allow(); err != nil {
+		t.Fatalf("Expected closed circuit below threshold, got %v", err)
+	}
+
+	b.failure()
+	if err := b.allow(); !errors.Is(err, domain.ErrDatabaseUnavailable) {
+		t.Fatalf("Expected open circuit, got %v", err)
+	}
+	if state := b.state(); state != circuitOpen {
+		t.Errorf("Expected open state, got %s", state)
+	}
+
+	// After the cooldown a single trial is let through
+	b.openUntil = time.Now()
+	if state := b.state(); state != circuitHalfOpen {
+		t.Errorf("Expected half-open state, got %s", state)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("Expected trial, got %v", err)
+	}
+	if err := b.allow(); err == nil {
+		t.Fatal("Expected a single trial")
+	}
+
+	// A trial without outcome lets the next request try
+	b.release()
+	if err := b.allow(); err != nil {
+		t.Fatalf("Expected another trial after release, got %v", err)
+	}
+
+	// A failed trial opens the circuit again, a successful one closes it
+	b.failure()
+	if err := b.allow(); err == nil {
+		t.Fatal("Expected open circuit after failed trial")
+	}
+	b.success()
+	if err := b.allow(); err != nil || b.state() != circuitClosed {
+		t.Errorf("Expected closed circuit, got %v", err)
+	}
+
+	// trip opens at once
+	b.trip()
+	if err := b.allow(); err == nil {
+		t.Error("Expected open circuit after trip")
+	}
+
+	// A zero threshold disables the breaker
+	disabled := newBreaker(0, time.Hour)
+	disabled.trip()
+	if err := disabled.allow(); err != nil {
+		t.Errorf("Expected disabled breaker, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlConfig builds the driver configuration of a replica. Building it
+// with mysql.Config escapes special characters in the password.
+func mysqlConfig(backend config.BackendConfig, replicaCfg config.ReplicaConfig) (*mysql.Config, error) {
+	options := backend.Options
+
+	cfg := mysql.NewConfig()
+	cfg.User = backend.User
+	cfg.Passwd = backend.Password
+	if replicaCfg.Socket != "" {
+		cfg.Net = "unix"
+		cfg.Addr = replicaCfg.Socket
+	} else {
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(replicaCfg.Host, strconv.Itoa(replicaCfg.Port))
+	}
+
+	if options.Charset != "" {
+		cfg.Params = map[string]string{"charset": options.Charset}
+	}
+	cfg.Collation = options.Collation
+	cfg.ParseTime = options.ParseTime
+	if options.Loc != "" {
+		loc, err := time.LoadLocation(options.Loc)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Loc = loc
+	}
+	cfg.Timeout = options.Timeout
+	cfg.ReadTimeout = options.ReadTimeout
+	cfg.WriteTimeout = options.WriteTimeout
+	cfg.InterpolateParams = options.InterpolateParams
+	cfg.ConnectionAttributes = connectionAttributes(options.ConnectionAttributes)
+
+	tlsConfig, err := mysqlTLSConfig(options.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		cfg.TLS = tlsConfig
+		cfg.AllowFallbackToPlaintext = options.TLS.Mode == "preferred"
+	}
+
+	return cfg, nil
+}
+
+// connectionAttributes formats attributes as the driver expects,
+// "key1:value1,key2:value2", in a stable order
+func connectionAttributes(attributes map[string]string) string {
+	pairs := make([]string, 0, len(attributes))
+	for key, value := range attributes {
+		pairs = append(pairs, key+":"+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// mysqlTLSConfig creates the TLS configuration for a mode of --ssl-mode. It
+// returns nil when TLS is disabled.
+func mysqlTLSConfig(cfg config.MySQLTLSConfig) (*tls.Config, error) {
+	if cfg.Mode == "" || cfg.Mode == "disabled" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{ServerName: cfg.ServerName}
+
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MySQL client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.CAFile != "" {
+		data, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MySQL CA file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.New("MySQL CA file contains no PEM certificates")
+		}
+	}
+
+	switch cfg.Mode {
+	case "preferred", "required":
+		tlsConfig.InsecureSkipVerify = true
+	case "verify_ca":
+		// The chain is verified without the host name
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			opts := x509.VerifyOptions{Roots: tlsConfig.RootCAs, Intermediates: x509.NewCertPool()}
+			for _, cert := range state.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := state.PeerCertificates[0].Verify(opts)
+			return err
+		}
+	case "verify_identity":
+	default:
+		return nil, fmt.Errorf("unknown MySQL TLS mode %q", cfg.Mode)
+	}
+
+	return tlsConfig, nil
+}
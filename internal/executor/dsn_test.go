@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestMySQLConfig(t *testing.T) {
+	backend := config.BackendConfig{
+		User:     "root",
+		Password: "p@ss:w/rd?",
+		Options: config.MySQLOptions{
+			Charset:              "utf8mb4",
+			Collation:            "utf8mb4_0900_ai_ci",
+			ParseTime:            true,
+			Loc:                  "Europe/Moscow",
+			Timeout:              3 * time.Second,
+			InterpolateParams:    true,
+			ConnectionAttributes: map[string]string{"program_name": "mysql-tui", "env": "test"},
+		},
+	}
+
+	cfg, err := mysqlConfig(backend, config.ReplicaConfig{Host: "db", Port: 3307})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The password survives a round trip through the DSN
+	parsed, err := mysql.ParseDSN(cfg.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Passwd != backend.Password || parsed.Addr != "db:3307" || parsed.Net != "tcp" {
+		t.Errorf("Unexpected connection: %s@%s(%s)", parsed.Passwd, parsed.Net, parsed.Addr)
+	}
+
+	dsn := cfg.FormatDSN()
+	for _, param := range []string{"charset=utf8mb4", "collation=utf8mb4_0900_ai_ci", "parseTime=true", "loc=Europe%2FMoscow", "timeout=3s", "interpolateParams=true", "connectionAttributes=env%3Atest%2Cprogram_name%3Amysql-tui"} {
+		if !strings.Contains(dsn, param) {
+			t.Errorf("DSN %s lacks %s", dsn, param)
+		}
+	}
+
+	// A socket replaces host and port
+	cfg, err = mysqlConfig(backend, config.ReplicaConfig{Host: "db", Port: 3307, Socket: "/run/mysqld/mysqld.sock"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Net != "unix" || cfg.Addr != "/run/mysqld/mysqld.sock" {
+		t.Errorf("Expected unix socket, got %s(%s)", cfg.Net, cfg.Addr)
+	}
+}
+
+func TestMySQLTLSConfig(t *testing.T) {
+	tests := []struct {
+		mode       string
+		enabled    bool
+		skipVerify bool
+		fallback   bool
+	}{
+		{"disabled", false, false, false},
+		{"preferred", true, true, true},
+		{"required", true, true, false},
+		{"verify_ca", true, true, false},
+		{"verify_identity", true, false, false},
+	}
+
+	for _, tt := range tests {
+		backend := config.BackendConfig{Options: config.MySQLOptions{TLS: config.MySQLTLSConfig{Mode: tt.mode}}}
+		cfg, err := mysqlConfig(backend, config.ReplicaConfig{Host: "db", Port: 3306})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.mode, err)
+		}
+
+		if (cfg.TLS != nil) != tt.enabled {
+			t.Errorf("%s: expected TLS enabled = %v", tt.mode, tt.enabled)
+			continue
+		}
+		if cfg.TLS != nil && cfg.TLS.InsecureSkipVerify != tt.skipVerify {
+			t.Errorf("%s: expected InsecureSkipVerify = %v", tt.mode, tt.skipVerify)
+		}
+		if cfg.AllowFallbackToPlaintext != tt.fallback {
+			t.Errorf("%s: expected fallback = %v", tt.mode, tt.fallback)
+		}
+	}
+
+	if _, err := mysqlTLSConfig(config.MySQLTLSConfig{Mode: "sometimes"}); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
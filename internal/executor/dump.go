@@ -0,0 +1,561 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/formatter"
+)
+
+// ErrDumpTooLarge is returned when a sandbox does not fit into a dump
+var ErrDumpTooLarge = domain.NewError(domain.CodeValidation, "sandbox is too large to snapshot")
+
+// dumpRowsPerInsert is the number of rows of one INSERT statement of a dump
+const dumpRowsPerInsert = 100
+
+// dumpSQLMode is the sql_mode dumps are written and restored in. It keeps
+// explicit zeros in AUTO_INCREMENT columns and the backslash escapes the
+// server uses in SHOW CREATE output.
+const dumpSQLMode = "NO_AUTO_VALUE_ON_ZERO"
+
+// definerPattern matches the DEFINER clause of views, routines and triggers,
+// which names an account that may not exist where the dump is restored
+var definerPattern = regexp.MustCompile("(?i)\\s*DEFINER\\s*=\\s*(?:`[^`]*`|'[^']*'|[^\\s@]+)@(?:`[^`]*`|'[^']*'|\\S+)")
+
+// Dump is a portable SQL script that recreates the objects and data of a
+// sandbox in any database
+type Dump struct {
+	Script   string
+	Tables   int
+	Views    int
+	Routines int
+	Triggers int
+	Rows     int64
+}
+
+// dumpObject is a table, view, routine or trigger of a sandbox
+type dumpObject struct {
+	name string
+	kind string
+}
+
+// Dump writes the tables with their rows, routines, views and triggers of a
+// sandbox as a SQL script. References to the sandbox database and DEFINER
+// clauses are removed, so that the script runs in any database. Dumps
+// larger than maxBytes fail with ErrDumpTooLarge; 0 means no limit.
+func (e *MySQLExecutor) Dump(ctx context.Context, sandbox *Sandbox, maxBytes int64) (*Dump, error) {
+	// Create context with timeout
+	timeout := e.limits.Load().queryTimeout
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dump, err := sandbox.dump(execCtx, maxBytes)
+	if err != nil {
+		return nil, contextError(execCtx, err, timeout)
+	}
+	return dump, nil
+}
+
+// Restore replaces the objects and data of a sandbox with those of a dump.
+// An open transaction is rolled back first.
+func (e *MySQLExecutor) Restore(ctx context.Context, sandbox *Sandbox, script string) error {
+	// Create context with timeout
+	timeout := e.limits.Load().queryTimeout
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := sandbox.restore(execCtx, script); err != nil {
+		if execCtx.Err() != nil {
+			return contextError(execCtx, err, timeout)
+		}
+		code, _ := describeFailure(execCtx, err, timeout)
+		return domain.WithCode(code, err)
+	}
+	return nil
+}
+
+// dump builds the dump of the sandbox
+func (s *Sandbox) dump(ctx context.Context, maxBytes int64) (*Dump, error) {
+	if err := s.use(ctx); err != nil {
+		return nil, err
+	}
+
+	// The definitions are read in the mode the dump is restored in
+	restoreMode, err := s.pinSQLMode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreMode()
+
+	w := &dumpWriter{maxBytes: maxBytes, qualifier: "`" + s.dbName + "`."}
+	d := &Dump{}
+
+	w.printf("-- Sandbox snapshot\n-- Created: %s\n\n", time.Now().UTC().Format(time.RFC3339))
+	w.printf("SET NAMES utf8mb4;\nSET SESSION sql_mode = '%s';\nSET FOREIGN_KEY_CHECKS = 0;\n", dumpSQLMode)
+
+	objects, err := s.dumpObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var views []dumpObject
+	var routines []dumpObject
+	for _, object := range objects {
+		switch object.kind {
+		case "BASE TABLE":
+			rows, err := s.dumpTable(ctx, w, object.name)
+			if err != nil {
+				return nil, err
+			}
+			d.Tables++
+			d.Rows += rows
+		case "VIEW":
+			views = append(views, object)
+		case "PROCEDURE", "FUNCTION":
+			routines = append(routines, object)
+		}
+		if w.err != nil {
+			return nil, w.err
+		}
+	}
+
+	// Routines may be used by views, views only by triggers
+	for _, routine := range routines {
+		definition, err := s.showCreate(ctx, routine.kind, routine.name, 2)
+		if err != nil {
+			return nil, err
+		}
+		w.printf("\nDELIMITER ;;\n%s;;\nDELIMITER ;\n", w.portable(definition))
+		d.Routines++
+	}
+
+	definitions := make(map[string]string, len(views))
+	for _, view := range views {
+		definition, err := s.showCreate(ctx, "VIEW", view.name, 1)
+		if err != nil {
+			return nil, err
+		}
+		definitions[view.name] = w.portable(definition)
+	}
+	for _, view := range orderViews(views, definitions) {
+		w.printf("\n%s;\n", definitions[view.name])
+		d.Views++
+	}
+
+	// Triggers come last, so that they do not fire while rows are inserted
+	triggers, err := s.dumpTriggers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, trigger := range triggers {
+		definition, err := s.showCreate(ctx, "TRIGGER", trigger, 2)
+		if err != nil {
+			return nil, err
+		}
+		w.printf("\nDELIMITER ;;\n%s;;\nDELIMITER ;\n", w.portable(definition))
+		d.Triggers++
+	}
+
+	w.printf("\nSET FOREIGN_KEY_CHECKS = 1;\n")
+	if w.err != nil {
+		return nil, w.err
+	}
+
+	d.Script = w.String()
+	return d, nil
+}
+
+// restore recreates the sandbox database and runs the dump in it. Dumps are
+// written by the server, so their data is not checked against the
+// validator, which would reject values such as 'Grant'.
+func (s *Sandbox) restore(ctx context.Context, script string) error {
+	s.rollback(ctx, domain.RollbackRestore)
+
+	statements := []string{
+		fmt.Sprintf("DROP DATABASE `%s`", s.dbName),
+		fmt.Sprintf("CREATE DATABASE `%s`", s.dbName),
+	}
+	for _, stmt := range statements {
+		if _, err := s.conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to reset sandbox %s: %w", s.dbName, err)
+		}
+	}
+	s.deallocatePrepared(ctx)
+
+	// The session mode of the user must not change how the dump is parsed
+	restoreMode, err := s.pinSQLMode(ctx)
+	if err != nil {
+		return err
+	}
+	defer restoreMode()
+
+	// A failed restore must not leave foreign key checks disabled
+	defer func() {
+		if _, err := s.conn.ExecContext(context.WithoutCancel(ctx), "SET FOREIGN_KEY_CHECKS = 1"); err != nil {
+			fmt.Printf("WARNING: Failed to enable foreign key checks in %s: %v\n", s.dbName, err)
+		}
+	}()
+
+	// The statements of the dump are not part of the progress of a script
+	if _, _, err := s.ExecuteQuery(WithProgress(ctx, nil), script, nil, formatter.ModeBatch); err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	return nil
+}
+
+// pinSQLMode sets the session sql_mode to the one dumps are written and
+// restored in. The returned function restores the previous mode.
+func (s *Sandbox) pinSQLMode(ctx context.Context) (func(), error) {
+	var previous string
+	if err := s.conn.QueryRowContext(ctx, "SELECT @@SESSION.sql_mode").Scan(&previous); err != nil {
+		return nil, fmt.Errorf("failed to read sql_mode in %s: %w", s.dbName, err)
+	}
+	if _, err := s.conn.ExecContext(ctx, "SET SESSION sql_mode = ?", dumpSQLMode); err != nil {
+		return nil, fmt.Errorf("failed to set sql_mode in %s: %w", s.dbName, err)
+	}
+
+	return func() {
+		if _, err := s.conn.ExecContext(context.WithoutCancel(ctx), "SET SESSION sql_mode = ?", previous); err != nil {
+			fmt.Printf("WARNING: Failed to restore sql_mode in %s: %v\n", s.dbName, err)
+		}
+	}, nil
+}
+
+// deallocatePrepared drops the statements prepared on the connection, which
+// refer to objects of the database that was replaced
+func (s *Sandbox) deallocatePrepared(ctx context.Context) {
+	for name := range s.prepared {
+		if _, err := s.conn.ExecContext(ctx, fmt.Sprintf("DEALLOCATE PREPARE `%s`", name)); err != nil {
+			fmt.Printf("WARNING: Failed to deallocate %s in %s: %v\n", name, s.dbName, err)
+		}
+	}
+	s.prepared = nil
+}
+
+// dumpObjects lists the tables and views and then the routines of the
+// sandbox by name
+func (s *Sandbox) dumpObjects(ctx context.Context) ([]dumpObject, error) {
+	queries := []string{
+		`SELECT TABLE_NAME, TABLE_TYPE FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME`,
+		`SELECT ROUTINE_NAME, ROUTINE_TYPE FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = ? ORDER BY ROUTINE_NAME`,
+	}
+
+	var objects []dumpObject
+	for _, query := range queries {
+		rows, err := s.conn.QueryContext(ctx, query, s.dbName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		for rows.Next() {
+			var object dumpObject
+			if err := rows.Scan(&object.name, &object.kind); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan object: %w", err)
+			}
+			objects = append(objects, object)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+	}
+	return objects, nil
+}
+
+// dumpTriggers lists the triggers of the sandbox, the current database
+func (s *Sandbox) dumpTriggers(ctx context.Context) ([]string, error) {
+	rows, err := s.conn.QueryContext(ctx, "SHOW TRIGGERS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list triggers: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list triggers: %w", err)
+	}
+
+	// The first column is the trigger name
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var triggers []string
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		triggers = append(triggers, string(values[0]))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list triggers: %w", err)
+	}
+	return triggers, nil
+}
+
+// showCreate returns the column of a SHOW CREATE statement that holds the
+// definition of an object
+func (s *Sandbox) showCreate(ctx context.Context, kind string, name string, column int) (string, error) {
+	rows, err := s.conn.QueryContext(ctx, fmt.Sprintf("SHOW CREATE %s %s", kind, quoteIdentifier(name)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read definition of %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", fmt.Errorf("failed to read definition of %s: %w", name, err)
+		}
+		return "", fmt.Errorf("no definition of %s", name)
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return "", fmt.Errorf("failed to read definition of %s: %w", name, err)
+	}
+	if column >= len(values) || !values[column].Valid {
+		return "", fmt.Errorf("no definition of %s", name)
+	}
+	return values[column].String, nil
+}
+
+// dumpTable writes the definition and the rows of a table and returns the
+// number of rows. Generated columns are left to the server.
+func (s *Sandbox) dumpTable(ctx context.Context, w *dumpWriter, table string) (int64, error) {
+	definition, err := s.showCreate(ctx, "TABLE", table, 1)
+	if err != nil {
+		return 0, err
+	}
+	w.printf("\n%s;\n", w.portable(definition))
+
+	columns, types, err := s.dumpColumns(ctx, table)
+	if err != nil {
+		return 0, err
+	}
+	if len(columns) == 0 {
+		return 0, nil
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+	}
+	columnList := strings.Join(quoted, ", ")
+
+	rows, err := s.conn.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", columnList, quoteIdentifier(table)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rows of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var count int64
+	literals := make([]string, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return 0, fmt.Errorf("failed to read rows of %s: %w", table, err)
+		}
+		for i, value := range values {
+			literals[i] = sqlLiteral(value, types[i])
+		}
+
+		if count%dumpRowsPerInsert == 0 {
+			if count > 0 {
+				w.printf(";\n")
+			}
+			w.printf("INSERT INTO %s (%s) VALUES\n(%s)", quoteIdentifier(table), columnList, strings.Join(literals, ", "))
+		} else {
+			w.printf(",\n(%s)", strings.Join(literals, ", "))
+		}
+		count++
+
+		if w.err != nil {
+			return 0, w.err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read rows of %s: %w", table, err)
+	}
+	if count > 0 {
+		w.printf(";\n")
+	}
+
+	return count, nil
+}
+
+// dumpColumns returns the names and data types of the stored columns of a
+// table in ordinal order
+func (s *Sandbox) dumpColumns(ctx context.Context, table string) ([]string, []string, error) {
+	rows, err := s.conn.QueryContext(ctx, `
+		SELECT COLUMN_NAME, DATA_TYPE, EXTRA FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, s.dbName, table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns, types []string
+	for rows.Next() {
+		var name, dataType string
+		var extra sql.NullString
+		if err := rows.Scan(&name, &dataType, &extra); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		if strings.Contains(strings.ToUpper(extra.String), "GENERATED") {
+			continue
+		}
+		columns = append(columns, name)
+		types = append(types, strings.ToLower(dataType))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	return columns, types, nil
+}
+
+// orderViews orders views so that every view comes after the views its
+// definition refers to
+func orderViews(views []dumpObject, definitions map[string]string) []dumpObject {
+	ordered := make([]dumpObject, 0, len(views))
+	added := make(map[string]bool, len(views))
+	visiting := make(map[string]bool, len(views))
+
+	var visit func(view dumpObject)
+	visit = func(view dumpObject) {
+		// A cycle cannot be created, but must not recurse forever
+		if added[view.name] || visiting[view.name] {
+			return
+		}
+		visiting[view.name] = true
+		for _, other := range views {
+			if other.name != view.name && refersTo(definitions[view.name], other.name) {
+				visit(other)
+			}
+		}
+		added[view.name] = true
+		ordered = append(ordered, view)
+	}
+
+	for _, view := range views {
+		visit(view)
+	}
+	return ordered
+}
+
+// refersTo reports whether a definition contains name as a whole word,
+// quoted or not
+func refersTo(definition string, name string) bool {
+	for i := 0; ; {
+		j := strings.Index(definition[i:], name)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(name)
+		if (start == 0 || !isWordChar(definition[start-1])) && (end == len(definition) || !isWordChar(definition[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// dumpWriter builds a dump and stops growing it past maxBytes
+type dumpWriter struct {
+	strings.Builder
+	maxBytes  int64
+	qualifier string
+	err       error
+}
+
+// printf appends to the dump unless it is already too large
+func (w *dumpWriter) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	fmt.Fprintf(&w.Builder, format, args...)
+	if w.maxBytes > 0 && int64(w.Len()) > w.maxBytes {
+		w.err = domain.Errorf(domain.CodeValidation, "%w (max %d bytes)", ErrDumpTooLarge, w.maxBytes)
+	}
+}
+
+// portable removes the sandbox database and the DEFINER clause from a
+// definition
+func (w *dumpWriter) portable(definition string) string {
+	definition = strings.ReplaceAll(definition, w.qualifier, "")
+	return definerPattern.ReplaceAllString(definition, "")
+}
+
+// quoteIdentifier quotes a MySQL identifier with backticks
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// sqlLiteral renders a value read from a column of the given data type as a
+// SQL literal. Binary data is written as a hex literal.
+func sqlLiteral(value interface{}, dataType string) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return "'" + v.Format("2006-01-02 15:04:05.999999") + "'"
+	case []byte:
+		switch dataType {
+		case "tinyint", "smallint", "mediumint", "int", "integer", "bigint",
+			"decimal", "numeric", "float", "double", "real", "year":
+			return string(v)
+		case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit",
+			"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring",
+			"multipolygon", "geometrycollection", "geomcollection":
+			if len(v) == 0 {
+				return "''"
+			}
+			return "X'" + hex.EncodeToString(v) + "'"
+		case "json":
+			// JSON columns refuse binary strings, so documents are always text
+			return quoteString(string(v))
+		}
+		return quoteString(string(v))
+	default:
+		return quoteString(fmt.Sprint(v))
+	}
+}
+
+// quoteString quotes a SQL string literal by doubling quotes. Strings with
+// backslashes or NUL bytes are written as utf8mb4 hex literals, since their
+// meaning would depend on NO_BACKSLASH_ESCAPES.
+func quoteString(s string) string {
+	if strings.ContainsAny(s, "\\\x00") {
+		return "_utf8mb4 X'" + hex.EncodeToString([]byte(s)) + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
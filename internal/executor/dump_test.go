@@ -0,0 +1,228 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/security"
+)
+
+func TestSQLLiteral(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		dataType string
+		want     string
+	}{
+		{nil, "int", "NULL"},
+		{[]byte("42"), "int", "42"},
+		{[]byte("-1.50"), "decimal", "-1.50"},
+		{[]byte("it's a\ntest"), "varchar", "'it''s a\ntest'"},
+		{[]byte(`it\'s`), "varchar", "_utf8mb4 X'69745c2773'"},
+		{[]byte("a\x00b"), "text", "_utf8mb4 X'610062'"},
+		{[]byte(`{"path": "C:\\x"}`), "json", "_utf8mb4 X'7b2270617468223a2022433a5c5c78227d'"},
+		{[]byte(`{"a": 1}`), "json", `'{"a": 1}'`},
+		{[]byte("привет"), "text", "'привет'"},
+		{[]byte{0x00, 0xff}, "varbinary", "X'00ff'"},
+		{[]byte{}, "blob", "''"},
+		{[]byte{0x01}, "bit", "X'01'"},
+		{int64(7), "bigint", "7"},
+		{2.5, "double", "2.5"},
+		{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "datetime", "'2024-01-02 03:04:05'"},
+	}
+
+	for _, c := range cases {
+		if got := sqlLiteral(c.value, c.dataType); got != c.want {
+			t.Errorf("sqlLiteral(%v, %s) = %s, want %s", c.value, c.dataType, got, c.want)
+		}
+	}
+}
+
+func TestDumpWriterPortable(t *testing.T) {
+	w := &dumpWriter{qualifier: "`student_db_1`."}
+
+	cases := []struct {
+		definition string
+		want       string
+	}{
+		{
+			"CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v` AS select `student_db_1`.`t`.`id` AS `id` from `student_db_1`.`t`",
+			"CREATE ALGORITHM=UNDEFINED SQL SECURITY DEFINER VIEW `v` AS select `t`.`id` AS `id` from `t`",
+		},
+		{
+			"CREATE DEFINER=`app`@`localhost` PROCEDURE `p`() BEGIN SELECT 1; END",
+			"CREATE PROCEDURE `p`() BEGIN SELECT 1; END",
+		},
+		{
+			"CREATE DEFINER=root@localhost TRIGGER tr AFTER INSERT ON a FOR EACH ROW SET @x = 1",
+			"CREATE TRIGGER tr AFTER INSERT ON a FOR EACH ROW SET @x = 1",
+		},
+	}
+
+	for _, c := range cases {
+		if got := w.portable(c.definition); got != c.want {
+			t.Errorf("portable(%q) = %q, want %q", c.definition, got, c.want)
+		}
+	}
+}
+
+func TestDumpWriterMaxBytes(t *testing.T) {
+	w := &dumpWriter{maxBytes: 10}
+	w.printf("0123456789")
+	if w.err != nil {
+		t.Fatalf("Unexpected error at the limit: %v", w.err)
+	}
+
+	w.printf("x")
+	if !errors.Is(w.err, ErrDumpTooLarge) {
+		t.Errorf("Expected ErrDumpTooLarge, got %v", w.err)
+	}
+}
+
+func TestOrderViews(t *testing.T) {
+	views := []dumpObject{{name: "a", kind: "VIEW"}, {name: "b", kind: "VIEW"}, {name: "c", kind: "VIEW"}}
+	definitions := map[string]string{
+		"a": "CREATE VIEW `a` AS SELECT * FROM `c`",
+		"b": "CREATE VIEW `b` AS SELECT ab FROM t",
+		"c": "CREATE VIEW c AS SELECT * FROM b JOIN t",
+	}
+
+	var got []string
+	for _, view := range orderViews(views, definitions) {
+		got = append(got, view.name)
+	}
+
+	// b is not referenced by a: "ab" is a different word
+	want := []string{"b", "c", "a"}
+	if len(got) != len(want) {
+		t.Fatalf("orderViews = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("orderViews = %v, want %v", got, want)
+		}
+	}
+}
+
+// recordingConnector opens connections that accept every statement and
+// record it. The session queries of a sandbox are answered with fixed values.
+type recordingConnector struct {
+	statements []string
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{connector: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver { return nil }
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query, args)
+	switch {
+	case strings.HasPrefix(query, "SELECT @@SESSION.sql_mode"):
+		return &recordingRows{columns: []string{"sql_mode"}, values: []driver.Value{"ANSI_QUOTES"}}, nil
+	case strings.HasPrefix(query, "SELECT ROW_COUNT()"):
+		return &recordingRows{columns: []string{"rows", "id", "warnings"}, values: []driver.Value{int64(0), int64(0), int64(0)}}, nil
+	case query == "SELECT @@autocommit":
+		return &recordingRows{columns: []string{"autocommit"}, values: []driver.Value{true}}, nil
+	}
+	return &recordingRows{}, nil
+}
+
+func (c *recordingConn) record(query string, args []driver.NamedValue) {
+	for _, arg := range args {
+		query += fmt.Sprintf(" [%v]", arg.Value)
+	}
+	c.connector.statements = append(c.connector.statements, query)
+}
+
+// recordingRows holds at most one row
+type recordingRows struct {
+	columns []string
+	values  []driver.Value
+	read    bool
+}
+
+func (r *recordingRows) Columns() []string { return r.columns }
+
+func (r *recordingRows) Close() error { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if r.read || r.values == nil {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.values)
+	return nil
+}
+
+func TestSandboxRestore(t *testing.T) {
+	ctx := context.Background()
+	connector := &recordingConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to open connection: %v", err)
+	}
+	defer conn.Close()
+
+	exec := &MySQLExecutor{validator: security.NewValidator()}
+	exec.limits.Store(&limits{quota: newQuota(config.QuotaConfig{})})
+	sandbox := &Sandbox{executor: exec, conn: conn, dbName: "student_db_1", prepared: map[string]int{"s": 0}}
+
+	// Data of a snapshot may contain words the validator blocks in queries
+	insert := "INSERT INTO `films` (`id`, `title`) VALUES\n(1, 'Kill Bill'),\n(2, 'Grant')"
+	script := "SET NAMES utf8mb4;\n" + insert + ";\n"
+	if err := sandbox.restore(ctx, script); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	want := []string{
+		"DROP DATABASE `student_db_1`",
+		"CREATE DATABASE `student_db_1`",
+		"DEALLOCATE PREPARE `s`",
+		"SET SESSION sql_mode = ? [" + dumpSQLMode + "]",
+		insert,
+		"SET SESSION sql_mode = ? [ANSI_QUOTES]",
+	}
+	next := 0
+	for _, stmt := range connector.statements {
+		if next < len(want) && stmt == want[next] {
+			next++
+		}
+	}
+	if next < len(want) {
+		t.Errorf("Expected %q in order, got %q", want[next], connector.statements)
+	}
+	if sandbox.prepared != nil {
+		t.Errorf("Expected prepared statements to be forgotten, got %v", sandbox.prepared)
+	}
+}
@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// ErrUnknownEngine is returned for engines that are not configured
+var ErrUnknownEngine = domain.NewError(domain.CodeValidation, "unknown or disabled engine")
+
+// Executor executes scripts in isolated sandboxes of one database engine
+type Executor interface {
+	// Engine returns the engine name, e.g. domain.EngineMySQL
+	Engine() string
+
+	// Execute runs the request in a new sandbox that is dropped afterwards
+	Execute(ctx context.Context, req *domain.ExecuteRequest) (*domain.ExecuteResponse, error)
+
+	// Ping checks that the engine is reachable
+	Ping(ctx context.Context) error
+
+	// Close releases the resources of the engine
+	Close() error
+}
+
+// Engines holds the executors of all configured engines
+type Engines struct {
+	executors map[string]Executor
+}
+
+// NewEngines creates a registry of executors. MySQL is the default engine
+// and must be among them.
+func NewEngines(executors ...Executor) *Engines {
+	engines := &Engines{executors: make(map[string]Executor, len(executors))}
+	for _, exec := range executors {
+		engines.executors[exec.Engine()] = exec
+	}
+	return engines
+}
+
+// Get returns the executor of the engine. An empty name selects MySQL.
+func (e *Engines) Get(name string) (Executor, error) {
+	if name == "" {
+		name = domain.EngineMySQL
+	}
+
+	exec, ok := e.executors[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %v)", ErrUnknownEngine, name, e.Names())
+	}
+	return exec, nil
+}
+
+// Names returns the configured engine names in sorted order
+func (e *Engines) Names() []string {
+	names := make([]string, 0, len(e.executors))
+	for name := range e.executors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close closes every executor and returns the first error
+func (e *Engines) Close() error {
+	var firstErr error
+	for _, exec := range e.executors {
+		if err := exec.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
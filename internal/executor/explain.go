@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/explain"
+	"mysql-tui-editor/server/internal/formatter"
+)
+
+var (
+	// versionPattern extracts the numeric part of a server version string
+	versionPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)`)
+
+	// mariaDBPattern detects MariaDB servers
+	mariaDBPattern = regexp.MustCompile(`(?i)mariadb`)
+)
+
+// Explain runs the setup statements in a sandbox and returns the plan of the
+// query, optionally together with EXPLAIN ANALYZE output
+func (e *MySQLExecutor) Explain(ctx context.Context, req *domain.ExplainRequest) (*domain.ExplainResponse, error) {
+	startTime := time.Now()
+
+	statements := splitSQLStatements(req.Query)
+	if len(statements) != 1 {
+		return &domain.ExplainResponse{Error: "Exactly one statement can be explained", ErrorCode: domain.CodeValidation}, nil
+	}
+	query := statements[0].text
+
+	// Create context with timeout
+	execCtx, cancel := context.WithTimeout(ctx, e.limits.Load().queryTimeout)
+	defer cancel()
+
+	// Create sandbox
+	sandbox, err := newSandbox(execCtx, e, e.dbPrefix, req.Owner)
+	if errors.Is(err, domain.ErrDatabaseUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		response := &domain.ExplainResponse{Error: fmt.Sprintf("Failed to create sandbox: %v", err), ErrorCode: domain.CodeOf(err)}
+		if execCtx.Err() != nil {
+			response.ErrorCode, response.Error = describeFailure(execCtx, err, e.limits.Load().queryTimeout)
+		}
+		return response, nil
+	}
+
+	// Ensure cleanup
+	defer func() {
+		if cleanupErr := sandbox.Cleanup(context.Background()); cleanupErr != nil {
+			fmt.Printf("WARNING: Failed to cleanup sandbox %s: %v\n", sandbox.dbName, cleanupErr)
+		}
+	}()
+
+	response, err := sandbox.Explain(execCtx, req.Setup, query, req.Analyze)
+	response.Sandbox = sandbox.Name()
+	response.ExecutionTimeMs = time.Since(startTime).Milliseconds()
+
+	if err != nil {
+		response.ErrorCode, response.Error = describeFailure(execCtx, err, e.limits.Load().queryTimeout)
+		if response.ErrorCode != domain.CodeTimeout && response.ErrorCode != domain.CodeCancelled {
+			response.Error = err.Error()
+		}
+		return response, nil
+	}
+
+	response.Success = true
+	return response, nil
+}
+
+// Explain executes the setup script and explains the query on the pinned
+// connection
+func (s *Sandbox) Explain(ctx context.Context, setup string, query string, analyze bool) (*domain.ExplainResponse, error) {
+	response := &domain.ExplainResponse{}
+
+	if len(splitSQLStatements(setup)) > 0 {
+		if _, _, err := s.ExecuteQuery(ctx, setup, nil, formatter.ModeTable); err != nil {
+			return response, fmt.Errorf("setup failed: %w", err)
+		}
+	} else if err := s.use(ctx); err != nil {
+		return response, err
+	}
+
+	if err := s.conn.QueryRowContext(ctx, "SELECT VERSION()").Scan(&response.ServerVersion); err != nil {
+		return response, fmt.Errorf("failed to read server version: %w", err)
+	}
+
+	var planJSON string
+	if err := s.conn.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+query).Scan(&planJSON); err != nil {
+		return response, fmt.Errorf("EXPLAIN failed: %w", err)
+	}
+
+	plan, err := explain.Parse([]byte(planJSON))
+	if err != nil {
+		return response, err
+	}
+
+	response.Plan = plan
+	response.Tree = explain.RenderTree(plan)
+	response.Issues = plan.AllIssues()
+	response.Raw = []byte(planJSON)
+
+	if analyze {
+		if !supportsExplainAnalyze(response.ServerVersion) {
+			response.AnalyzeError = fmt.Sprintf("EXPLAIN ANALYZE requires MySQL 8.0.18 or later (server is %s)", response.ServerVersion)
+		} else if err := s.conn.QueryRowContext(ctx, "EXPLAIN ANALYZE "+query).Scan(&response.Analyze); err != nil {
+			// The plan is still useful when the query cannot be analyzed
+			response.AnalyzeError = err.Error()
+		}
+	}
+
+	return response, nil
+}
+
+// supportsExplainAnalyze reports whether the server version supports
+// EXPLAIN ANALYZE. MariaDB uses ANALYZE instead and is not supported.
+func supportsExplainAnalyze(version string) bool {
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil || mariaDBPattern.MatchString(version) {
+		return false
+	}
+
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	patch, _ := strconv.Atoi(match[3])
+
+	switch {
+	case major != 8:
+		return major > 8
+	case minor > 0:
+		return true
+	default:
+		return patch >= 18
+	}
+}
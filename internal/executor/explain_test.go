@@ -0,0 +1,25 @@
+package executor
+
+import "testing"
+
+func TestSupportsExplainAnalyze(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"8.0.18", true},
+		{"8.0.35-0ubuntu0.22.04.1", true},
+		{"8.4.0", true},
+		{"9.1.0", true},
+		{"8.0.17", false},
+		{"5.7.44-log", false},
+		{"10.11.6-MariaDB", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := supportsExplainAnalyze(tt.version); got != tt.want {
+			t.Errorf("supportsExplainAnalyze(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
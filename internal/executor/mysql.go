@@ -3,80 +3,235 @@ package executor
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"mysql-tui-editor/server/internal/config"
 	"mysql-tui-editor/server/internal/domain"
-
-	_ "github.com/go-sql-driver/mysql"
+	"mysql-tui-editor/server/internal/fixture"
+	"mysql-tui-editor/server/internal/formatter"
 )
 
-// MySQLExecutor handles MySQL query execution
+// MySQLExecutor handles query execution on one named MySQL backend. A
+// backend consists of one or more replicas running the same server version;
+// sandboxes are created on them in turn, skipping replicas that are down.
 type MySQLExecutor struct {
-	db           *sql.DB
-	queryTimeout time.Duration
-	dbPrefix     string
-}
-
-// NewMySQLExecutor creates a new MySQL executor
-func NewMySQLExecutor(cfg *config.Config) (*MySQLExecutor, error) {
-	// Build DSN (Data Source Name)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/",
-		cfg.MySQL.User,
-		cfg.MySQL.Password,
-		cfg.MySQL.Host,
-		cfg.MySQL.Port,
-	)
-
-	// Open connection
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open MySQL connection: %w", err)
+	name     string
+	replicas []*replica
+	next     atomic.Uint64
+	dbPrefix string
+	fixtures *fixture.Store
+
+	// limits holds the settings that change on configuration reload
+	limits atomic.Pointer[limits]
+
+	breaker *breaker
+
+	// validator checks the text of statements prepared with PREPARE
+	validator StatementValidator
+
+	// sandboxes holds the live sandboxes by name; a nil value reserves the
+	// name of a sandbox that is being created
+	sandboxesMu sync.Mutex
+	sandboxes   map[string]*Sandbox
+
+	// failedDrops holds the sandboxes whose database could not be dropped
+	// until the orphan cleanup drops them
+	failedDrops map[string]struct{}
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewMySQLExecutor creates an executor for a backend and starts checking the
+// health of its replicas. Replicas that are not reachable yet are used once
+// they are; until then sandbox creation fails fast. Fixtures are looked up in
+// the given store.
+func NewMySQLExecutor(cfg *config.Config, backend config.BackendConfig, fixtures *fixture.Store) (*MySQLExecutor, error) {
+	e := &MySQLExecutor{
+		name:        backend.Name,
+		dbPrefix:    sandboxPrefix(backend.DBPrefix, cfg.Executor.InstanceID),
+		fixtures:    fixtures,
+		breaker:     newBreaker(cfg.Executor.BreakerThreshold, cfg.Executor.BreakerCooldown),
+		sandboxes:   make(map[string]*Sandbox),
+		failedDrops: make(map[string]struct{}),
+		stop:        make(chan struct{}),
 	}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(cfg.MySQL.MaxOpenConns)
-	db.SetMaxIdleConns(cfg.MySQL.MaxIdleConns)
-	db.SetConnMaxLifetime(cfg.MySQL.ConnMaxLifetime)
+	e.Reload(cfg)
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	for _, replicaCfg := range backend.Replicas {
+		r, err := openReplica(backend, replicaCfg)
+		if err != nil {
+			e.closeReplicas()
+			return nil, err
+		}
+		e.replicas = append(e.replicas, r)
+	}
+
+	// Test connection; the server may still be starting
+	if err := e.Ping(context.Background()); err != nil {
+		fmt.Printf("WARNING: MySQL backend %s is not reachable yet: %v\n", e.name, err)
+	}
+
+	e.wg.Add(2)
+	go e.healthLoop(cfg.Executor.HealthCheckInterval)
+	go e.quotaLoop(cfg.Quota.CheckInterval)
+	return e, nil
+}
+
+// sandboxPrefix returns the prefix of the sandbox names of this server
+func sandboxPrefix(dbPrefix string, instanceID string) string {
+	if instanceID == "" {
+		return dbPrefix
+	}
+	return dbPrefix + instanceID + "_"
+}
+
+// Engine returns the engine name
+func (e *MySQLExecutor) Engine() string {
+	return domain.EngineMySQL
+}
+
+// Name returns the backend name
+func (e *MySQLExecutor) Name() string {
+	return e.name
+}
+
+// Ping checks every replica and succeeds when at least one is reachable.
+// The result opens or closes the circuit breaker.
+func (e *MySQLExecutor) Ping(ctx context.Context) error {
+	var firstErr error
+	healthy := false
+	for _, r := range e.replicas {
+		if err := r.check(ctx); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", r.address, err)
+			}
+			continue
+		}
+		healthy = true
+	}
+
+	if healthy {
+		e.breaker.success()
+		return nil
+	}
+	e.breaker.trip()
+	return firstErr
+}
+
+// Health checks every replica and reports their state
+func (e *MySQLExecutor) Health(ctx context.Context) domain.BackendHealth {
+	e.Ping(ctx)
+
+	health := domain.BackendHealth{Name: e.name, Circuit: e.breaker.state()}
+	for _, r := range e.replicas {
+		replicaHealth := r.health()
+		health.Healthy = health.Healthy || replicaHealth.Healthy
+		health.Replicas = append(health.Replicas, replicaHealth)
+	}
+	return health
+}
+
+// healthLoop periodically checks the replicas so that sandboxes are not
+// created on servers that are down
+func (e *MySQLExecutor) healthLoop(interval time.Duration) {
+	defer e.wg.Done()
 
-	if err := db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping MySQL: %w", err)
+	if interval <= 0 {
+		<-e.stop
+		return
 	}
 
-	return &MySQLExecutor{
-		db:           db,
-		queryTimeout: cfg.Executor.QueryTimeout,
-		dbPrefix:     cfg.Executor.DBPrefix,
-	}, nil
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			e.Ping(context.Background())
+		}
+	}
 }
 
-// Close closes the MySQL connection
+// candidates returns the replicas in the order sandboxes should be created
+// on them: healthy replicas in round-robin order, then unhealthy ones as a
+// last resort
+func (e *MySQLExecutor) candidates() []*replica {
+	var healthy, unhealthy []*replica
+	for _, r := range e.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r)
+		} else {
+			unhealthy = append(unhealthy, r)
+		}
+	}
+
+	if len(healthy) > 1 {
+		start := int(e.next.Add(1)-1) % len(healthy)
+		healthy = append(healthy[start:], healthy[:start]...)
+	}
+	return append(healthy, unhealthy...)
+}
+
+// Close stops the background checks and closes the connection pools
 func (e *MySQLExecutor) Close() error {
-	return e.db.Close()
+	var err error
+	e.closeOnce.Do(func() {
+		close(e.stop)
+		e.wg.Wait()
+		err = e.closeReplicas()
+	})
+	return err
+}
+
+// closeReplicas closes the connection pools and returns the first error
+func (e *MySQLExecutor) closeReplicas() error {
+	var firstErr error
+	for _, r := range e.replicas {
+		if err := r.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
 }
 
-// GetDB returns the underlying database connection
+// GetDB returns the connection pool of the first replica
 func (e *MySQLExecutor) GetDB() *sql.DB {
-	return e.db
+	return e.replicas[0].db
 }
 
 // Execute executes SQL query in a sandboxed temporary database
-func (e *MySQLExecutor) Execute(ctx context.Context, query string) (*domain.ExecuteResponse, error) {
+func (e *MySQLExecutor) Execute(ctx context.Context, req *domain.ExecuteRequest) (*domain.ExecuteResponse, error) {
 	startTime := time.Now()
 
+	mode, err := formatter.ParseMode(req.OutputMode)
+	if err != nil {
+		return domain.NewErrorResponse(domain.CodeValidation, err.Error()), nil
+	}
+
 	// Create context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, e.queryTimeout)
+	timeout := e.limits.Load().timeout(req)
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Create sandbox
-	sandbox, err := NewSandbox(e, e.dbPrefix)
+	sandbox, err := newSandbox(execCtx, e, e.dbPrefix, req.Owner)
+	if errors.Is(err, domain.ErrDatabaseUnavailable) {
+		return nil, err
+	}
 	if err != nil {
-		return domain.NewErrorResponse(fmt.Sprintf("Failed to create sandbox: %v", err)), nil
+		if execCtx.Err() != nil {
+			return failureResponse(execCtx, err, timeout), nil
+		}
+		return domain.NewErrorResponse(domain.CodeOf(err), fmt.Sprintf("Failed to create sandbox: %v", err)), nil
 	}
 
 	// Ensure cleanup
@@ -87,17 +242,202 @@ func (e *MySQLExecutor) Execute(ctx context.Context, query string) (*domain.Exec
 		}
 	}()
 
+	// Prepare sandbox from fixture
+	if req.Fixture != "" {
+		if err := sandbox.LoadFixture(execCtx, req.Fixture); err != nil {
+			response := failureResponse(execCtx, err, timeout)
+			response.Sandbox = sandbox.Name()
+			return response, nil
+		}
+	}
+
+	response := e.run(execCtx, sandbox, req, mode, startTime)
+
+	// The sandbox is dropped with the request, so an open transaction ends here
+	sandbox.rollback(execCtx, domain.RollbackEnd)
+	response.Transaction = transactionState(sandbox)
+
+	return response, nil
+}
+
+// ExecuteIn executes SQL query in an existing sandbox, e.g. the sandbox of a
+// persistent session. The sandbox is not cleaned up afterwards.
+func (e *MySQLExecutor) ExecuteIn(ctx context.Context, sandbox *Sandbox, req *domain.ExecuteRequest) (*domain.ExecuteResponse, error) {
+	startTime := time.Now()
+
+	mode, err := formatter.ParseMode(req.OutputMode)
+	if err != nil {
+		return domain.NewErrorResponse(domain.CodeValidation, err.Error()), nil
+	}
+
+	// Create context with timeout
+	execCtx, cancel := context.WithTimeout(ctx, e.limits.Load().timeout(req))
+	defer cancel()
+
+	return e.run(execCtx, sandbox, req, mode, startTime), nil
+}
+
+// run executes the query in the sandbox and builds the response. A
+// transactional request is wrapped in a transaction that is always rolled back.
+func (e *MySQLExecutor) run(ctx context.Context, sandbox *Sandbox, req *domain.ExecuteRequest, mode formatter.Mode, startTime time.Time) *domain.ExecuteResponse {
+	sandbox.resetRollback()
+	timeout := e.limits.Load().timeout(req)
+
+	if req.Transactional {
+		if err := checkTransactional(splitSQLStatements(req.Query)); err != nil {
+			return transactionFailure(ctx, sandbox, err, timeout)
+		}
+		if err := sandbox.begin(ctx); err != nil {
+			return transactionFailure(ctx, sandbox, err, timeout)
+		}
+	}
+
 	// Execute query in sandbox
-	output, err := sandbox.ExecuteQuery(execCtx, query)
+	output, statements, err := sandbox.ExecuteQuery(ctx, req.Query, req.Params, mode)
+
+	if req.Transactional {
+		sandbox.rollback(ctx, domain.RollbackTransactional)
+	}
 	executionTime := time.Since(startTime).Milliseconds()
 
+	var response *domain.ExecuteResponse
+	if err != nil {
+		response = failureResponse(ctx, err, timeout)
+	} else {
+		response = domain.NewSuccessResponse(output, executionTime)
+	}
+
+	response.Engine = domain.EngineMySQL
+	response.Backend = e.name
+	response.Statements = statements
+	response.Transaction = transactionState(sandbox)
+	response.Sandbox = sandbox.Name()
+
+	return response
+}
+
+// transactionFailure builds the response for a transactional execution that
+// could not be started
+func transactionFailure(ctx context.Context, sandbox *Sandbox, err error, timeout time.Duration) *domain.ExecuteResponse {
+	response := failureResponse(ctx, err, timeout)
+	response.Transaction = transactionState(sandbox)
+	response.Sandbox = sandbox.Name()
+	return response
+}
+
+// transactionState returns a copy of the sandbox transaction state for a response
+func transactionState(sandbox *Sandbox) *domain.TransactionState {
+	state := sandbox.Transaction()
+	return &state
+}
+
+// failureResponse builds the response for a failed execution with the given
+// timeout
+func failureResponse(ctx context.Context, err error, timeout time.Duration) *domain.ExecuteResponse {
+	code, message := describeFailure(ctx, err, timeout)
+	return domain.NewErrorResponse(code, message)
+}
+
+// describeFailure classifies a failed execution and builds its message. An
+// expired or cancelled execution context is reported as a timeout or a
+// cancellation, errors with a domain code keep it and anything else is an
+// error of the SQL itself.
+func describeFailure(ctx context.Context, err error, timeout time.Duration) (domain.ErrorCode, string) {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return domain.CodeTimeout, fmt.Sprintf("Query execution timeout exceeded (%v)", timeout)
+	case context.Canceled:
+		return domain.CodeCancelled, "Query execution cancelled"
+	}
+
+	code := domain.CodeSQLError
+	var coded *domain.Error
+	if errors.As(err, &coded) {
+		code = coded.Code
+	}
+	return code, fmt.Sprintf("Query execution failed: %v", err)
+}
+
+// contextError returns the error of an execution whose context expired or
+// was cancelled. Timeouts wrap domain.ErrExecutionTimeout. Other errors are
+// returned unchanged.
+func contextError(ctx context.Context, err error, timeout time.Duration) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("%w (%v)", domain.ErrExecutionTimeout, timeout)
+	case context.Canceled:
+		return domain.Errorf(domain.CodeCancelled, "query execution cancelled: %w", err)
+	}
+	return err
+}
+
+// Fixtures returns the fixture store
+func (e *MySQLExecutor) Fixtures() *fixture.Store {
+	return e.fixtures
+}
+
+// ValueOptions returns the options used to render values
+func (e *MySQLExecutor) ValueOptions() formatter.ValueOptions {
+	return e.limits.Load().valueOptions
+}
+
+// FixtureSchema loads a fixture into a temporary sandbox and returns its schema
+func (e *MySQLExecutor) FixtureSchema(ctx context.Context, name string) (*domain.Schema, error) {
+	// Create context with timeout
+	execCtx, cancel := context.WithTimeout(ctx, e.limits.Load().queryTimeout)
+	defer cancel()
+
+	sandbox, err := newSandbox(execCtx, e, e.dbPrefix, "")
 	if err != nil {
-		// Check if it was a timeout
-		if execCtx.Err() == context.DeadlineExceeded {
-			return domain.NewErrorResponse(fmt.Sprintf("Query execution timeout exceeded (%v)", e.queryTimeout)), nil
+		return nil, fmt.Errorf("failed to create sandbox: %w", err)
+	}
+
+	defer func() {
+		if cleanupErr := sandbox.Cleanup(context.Background()); cleanupErr != nil {
+			fmt.Printf("WARNING: Failed to cleanup sandbox %s: %v\n", sandbox.dbName, cleanupErr)
 		}
-		return domain.NewErrorResponse(fmt.Sprintf("Query execution failed: %v", err)), nil
+	}()
+
+	if err := sandbox.LoadFixture(execCtx, name); err != nil {
+		return nil, err
 	}
 
-	return domain.NewSuccessResponse(output, executionTime), nil
+	return sandbox.Schema(execCtx)
+}
+
+// CreateSandbox creates a sandbox of owner that outlives the request,
+// optionally prepared from a fixture. The caller must clean it up.
+func (e *MySQLExecutor) CreateSandbox(ctx context.Context, fixtureName string, owner string) (*Sandbox, error) {
+	// Create context with timeout
+	execCtx, cancel := context.WithTimeout(ctx, e.limits.Load().queryTimeout)
+	defer cancel()
+
+	sandbox, err := newSandbox(execCtx, e, e.dbPrefix, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox: %w", err)
+	}
+
+	if fixtureName != "" {
+		err = sandbox.LoadFixture(execCtx, fixtureName)
+	} else {
+		err = sandbox.use(execCtx)
+	}
+
+	if err != nil {
+		if cleanupErr := sandbox.Cleanup(context.Background()); cleanupErr != nil {
+			fmt.Printf("WARNING: Failed to cleanup sandbox %s: %v\n", sandbox.dbName, cleanupErr)
+		}
+		return nil, err
+	}
+
+	return sandbox, nil
+}
+
+// Schema returns the schema of an existing sandbox
+func (e *MySQLExecutor) Schema(ctx context.Context, sandbox *Sandbox) (*domain.Schema, error) {
+	// Create context with timeout
+	execCtx, cancel := context.WithTimeout(ctx, e.limits.Load().queryTimeout)
+	defer cancel()
+
+	return sandbox.Schema(execCtx)
 }
@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// placeholder is a ? or :name placeholder of a statement
+type placeholder struct {
+	// start and end delimit the placeholder in the statement
+	start, end int
+
+	// name is the name of a :name placeholder, empty for ?
+	name string
+}
+
+// findPlaceholders returns the placeholders of a statement that are outside
+// quoted strings, identifiers and comments. :name placeholders are only
+// recognised when named is set; := and :: are operators, not placeholders.
+func findPlaceholders(stmt string, named bool) []placeholder {
+	var placeholders []placeholder
+
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(stmt, i)
+
+		case c == '#' || isDashComment(stmt, i):
+			i = skipLineComment(stmt, i)
+
+		case c == '/' && strings.HasPrefix(stmt[i:], "/*"):
+			i = skipBlockComment(stmt, i)
+
+		case c == '?':
+			placeholders = append(placeholders, placeholder{start: i, end: i + 1})
+
+		case c == ':' && named && (i == 0 || stmt[i-1] != ':') && i+1 < len(stmt) && isWordChar(stmt[i+1]):
+			end := i + 1
+			for end < len(stmt) && isWordChar(stmt[end]) {
+				end++
+			}
+			placeholders = append(placeholders, placeholder{start: i, end: end, name: stmt[i+1 : end]})
+			i = end - 1
+		}
+	}
+
+	return placeholders
+}
+
+// boundStatement is a statement whose placeholders are bound to values
+type boundStatement struct {
+	// text is the statement with :name placeholders replaced by ?
+	text string
+
+	// args are the values in placeholder order
+	args []interface{}
+
+	// params describes the values for the statement result
+	params []domain.BoundParam
+}
+
+// bindParams binds the params to the placeholders of the statements. The
+// result has an entry per statement, nil for statements without
+// placeholders. Without params nothing is bound and ? is left to the server.
+// Every placeholder must have a value and every value must be used.
+func bindParams(statements []sqlStatement, params *domain.Params) ([]*boundStatement, error) {
+	bound := make([]*boundStatement, len(statements))
+	if params == nil {
+		return bound, nil
+	}
+
+	named := params.Named != nil
+	used := make(map[string]bool)
+	next := 0
+
+	for i, stmt := range statements {
+		placeholders := findPlaceholders(stmt.text, named)
+		if len(placeholders) == 0 {
+			continue
+		}
+
+		b := &boundStatement{}
+		var text strings.Builder
+		last := 0
+		for position, p := range placeholders {
+			param := domain.BoundParam{Position: position + 1, Name: p.name}
+
+			switch {
+			case named && p.name == "":
+				return nil, domain.Errorf(domain.CodeValidation, "statement %d: ? placeholders cannot be mixed with named params", i+1)
+			case named:
+				value, ok := params.Named[p.name]
+				if !ok {
+					return nil, domain.Errorf(domain.CodeValidation, "statement %d: no value for :%s", i+1, p.name)
+				}
+				used[p.name] = true
+				param.Value = value
+			default:
+				if next >= len(params.Positional) {
+					return nil, domain.Errorf(domain.CodeValidation, "the query has more placeholders than the %d params given", len(params.Positional))
+				}
+				param.Value = params.Positional[next]
+				next++
+			}
+
+			param.Type = domain.ParamType(param.Value)
+			b.args = append(b.args, param.Value)
+			b.params = append(b.params, param)
+
+			text.WriteString(stmt.text[last:p.start])
+			text.WriteString("?")
+			last = p.end
+		}
+		text.WriteString(stmt.text[last:])
+		b.text = text.String()
+
+		bound[i] = b
+	}
+
+	if next < len(params.Positional) {
+		return nil, domain.Errorf(domain.CodeValidation, "the query has %d placeholders but %d params were given", next, len(params.Positional))
+	}
+	for _, name := range slices.Sorted(maps.Keys(params.Named)) {
+		if !used[name] {
+			return nil, domain.Errorf(domain.CodeValidation, "param %q is not used by the query", name)
+		}
+	}
+
+	return bound, nil
+}
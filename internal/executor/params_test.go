@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+func TestFindPlaceholders(t *testing.T) {
+	cases := []struct {
+		stmt  string
+		named bool
+		want  []string
+	}{
+		{"SELECT ?, ?", false, []string{"", ""}},
+		{"SELECT '?', `?`, \"?\" -- ?\n, ? /* ? */", false, []string{""}},
+		{"SELECT :id, :name", false, nil},
+		{"SELECT * FROM t WHERE id = :id AND name = :name", true, []string{"id", "name"}},
+		{"SET @a := 1; SELECT '10:30', x::int", true, nil},
+	}
+
+	for _, c := range cases {
+		var got []string
+		for _, p := range findPlaceholders(c.stmt, c.named) {
+			got = append(got, p.name)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("findPlaceholders(%q, %v) = %q, want %q", c.stmt, c.named, got, c.want)
+		}
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	statements := splitSQLStatements("INSERT INTO t VALUES (?, ?); SELECT 1; SELECT * FROM t WHERE id = ?")
+	bound, err := bindParams(statements, &domain.Params{Positional: []interface{}{int64(1), "a", int64(1)}})
+	if err != nil {
+		t.Fatalf("bindParams error = %v", err)
+	}
+	if bound[1] != nil {
+		t.Errorf("statement without placeholders was bound: %+v", bound[1])
+	}
+	if !reflect.DeepEqual(bound[0].args, []interface{}{int64(1), "a"}) || !reflect.DeepEqual(bound[2].args, []interface{}{int64(1)}) {
+		t.Errorf("args = %v, %v", bound[0].args, bound[2].args)
+	}
+	if bound[0].params[1].Type != "string" || bound[0].params[1].Position != 2 {
+		t.Errorf("params = %+v", bound[0].params)
+	}
+
+	named := splitSQLStatements("SELECT * FROM t WHERE id = :id OR parent = :id")
+	bound, err = bindParams(named, &domain.Params{Named: map[string]interface{}{"id": int64(7)}})
+	if err != nil {
+		t.Fatalf("bindParams(named) error = %v", err)
+	}
+	if bound[0].text != "SELECT * FROM t WHERE id = ? OR parent = ?" || len(bound[0].args) != 2 {
+		t.Errorf("named binding = %q %v", bound[0].text, bound[0].args)
+	}
+
+	invalid := []struct {
+		query  string
+		params *domain.Params
+	}{
+		{"SELECT ?", &domain.Params{Positional: []interface{}{}}},
+		{"SELECT ?", &domain.Params{Positional: []interface{}{int64(1), int64(2)}}},
+		{"SELECT :a", &domain.Params{Named: map[string]interface{}{"b": int64(1)}}},
+		{"SELECT :a", &domain.Params{Named: map[string]interface{}{"a": int64(1), "b": int64(2)}}},
+		{"SELECT :a, ?", &domain.Params{Named: map[string]interface{}{"a": int64(1)}}},
+	}
+	for _, c := range invalid {
+		_, err := bindParams(splitSQLStatements(c.query), c.params)
+		if domain.CodeOf(err) != domain.CodeValidation {
+			t.Errorf("bindParams(%q, %+v) error = %v, want a validation error", c.query, c.params, err)
+		}
+	}
+
+	if bound, err := bindParams(splitSQLStatements("SELECT ?"), nil); err != nil || bound[0] != nil {
+		t.Errorf("bindParams without params = %v, %v", bound, err)
+	}
+}
+
+func TestUnquoteString(t *testing.T) {
+	cases := map[string]string{
+		`'SELECT ?'`:         "SELECT ?",
+		`'it''s'`:            "it's",
+		`"a\"b\n"`:           "a\"b\n",
+		`'DROP DATA\BASE x'`: "DROP DATABASE x",
+	}
+
+	for literal, want := range cases {
+		if got := unquoteString(literal); got != want {
+			t.Errorf("unquoteString(%s) = %q, want %q", literal, got, want)
+		}
+	}
+}
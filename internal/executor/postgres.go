@@ -0,0 +1,282 @@
+package executor
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/formatter"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// postgresTypeNames maps PostgreSQL type names to the MySQL type names the
+// formatter understands
+var postgresTypeNames = map[string]string{
+	"int2":    "SMALLINT",
+	"int4":    "INT",
+	"int8":    "BIGINT",
+	"numeric": "DECIMAL",
+	"float4":  "FLOAT",
+	"float8":  "DOUBLE",
+	"json":    "JSON",
+	"jsonb":   "JSON",
+}
+
+// PostgresProvider creates a PostgreSQL schema per sandbox. Each schema is
+// owned by a login role of its own, and the sandbox connects as that role,
+// so that it has no privileges on the schemas of other sandboxes and no way
+// back to the configured account. That account needs the CREATEROLE
+// privilege, and pg_hba.conf must accept password logins of the sandbox
+// roles.
+type PostgresProvider struct {
+	db           *sql.DB
+	connConfig   *pgx.ConnConfig
+	schemaPrefix string
+}
+
+// NewPostgresProvider connects to PostgreSQL
+func NewPostgresProvider(cfg config.PostgresConfig) (*PostgresProvider, error) {
+	// Build DSN; url.URL escapes special characters in the credentials
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     cfg.Database,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}
+
+	connConfig, err := pgx.ParseConfig(dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL configuration: %w", err)
+	}
+
+	// Open connection
+	db := stdlib.OpenDB(*connConfig)
+
+	// Configure connection pool
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
+	}
+
+	return &PostgresProvider{
+		db:           db,
+		connConfig:   connConfig,
+		schemaPrefix: cfg.SchemaPrefix,
+	}, nil
+}
+
+// Engine returns the engine name
+func (p *PostgresProvider) Engine() string {
+	return domain.EnginePostgres
+}
+
+// Create creates a login role and a schema owned by it, and pins a
+// connection of the role whose search_path points to the schema. The
+// connections of the pool never change their role.
+func (p *PostgresProvider) Create(ctx context.Context) (EngineSandbox, error) {
+	sandbox := &postgresSandbox{
+		provider: p,
+		name:     p.schemaPrefix + generateShortUUID(),
+	}
+	identifier := pgx.Identifier{sandbox.name}.Sanitize()
+
+	password, err := generatePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	// The role has the name of the schema and no privileges elsewhere
+	if _, err := p.db.ExecContext(ctx, "CREATE ROLE "+identifier+" LOGIN PASSWORD '"+password+"'"); err != nil {
+		return nil, fmt.Errorf("failed to create role %s: %w", sandbox.name, err)
+	}
+	sandbox.role = true
+
+	setup := []string{
+		"GRANT " + identifier + " TO CURRENT_USER",
+		"CREATE SCHEMA " + identifier + " AUTHORIZATION " + identifier,
+	}
+	for _, stmt := range setup {
+		if _, err := p.db.ExecContext(ctx, stmt); err != nil {
+			sandbox.Drop(ctx)
+			return nil, fmt.Errorf("failed to create schema %s: %w", sandbox.name, err)
+		}
+	}
+
+	connConfig := p.connConfig.Copy()
+	connConfig.User = sandbox.name
+	connConfig.Password = password
+	if connConfig.RuntimeParams == nil {
+		connConfig.RuntimeParams = make(map[string]string)
+	}
+	connConfig.RuntimeParams["search_path"] = identifier
+
+	sandbox.db = stdlib.OpenDB(*connConfig)
+	sandbox.db.SetMaxOpenConns(1)
+
+	conn, err := sandbox.db.Conn(ctx)
+	if err != nil {
+		sandbox.Drop(ctx)
+		return nil, fmt.Errorf("failed to connect as role %s: %w", sandbox.name, err)
+	}
+	sandbox.conn = conn
+
+	return sandbox, nil
+}
+
+// generatePassword returns a random password of hex digits, which need no
+// quoting
+func generatePassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Ping checks the PostgreSQL connection
+func (p *PostgresProvider) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+// Close closes the PostgreSQL connection
+func (p *PostgresProvider) Close() error {
+	return p.db.Close()
+}
+
+// postgresSandbox is a PostgreSQL schema and login role with a pinned
+// connection of the role
+type postgresSandbox struct {
+	provider *PostgresProvider
+	name     string
+	db       *sql.DB
+	conn     *sql.Conn
+
+	// role is set once the sandbox role exists
+	role bool
+}
+
+// Name returns the schema name
+func (s *postgresSandbox) Name() string {
+	return s.name
+}
+
+// Run executes a single statement through the pgx connection so that the
+// command tag with the affected row count is available. The simple protocol
+// returns every value in text format, which is rendered like MySQL values.
+func (s *postgresSandbox) Run(ctx context.Context, stmt string, opts formatter.ValueOptions) (*StatementOutcome, error) {
+	outcome := &StatementOutcome{}
+
+	err := s.conn.Raw(func(driverConn any) error {
+		conn := driverConn.(*stdlib.Conn).Conn()
+
+		rows, err := conn.Query(ctx, stmt, pgx.QueryExecModeSimpleProtocol)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		fields := rows.FieldDescriptions()
+		resultSet := &domain.ResultSet{Columns: make([]domain.Column, len(fields))}
+		for i, field := range fields {
+			typeName := "UNKNOWN"
+			if t, ok := conn.TypeMap().TypeForOID(field.DataTypeOID); ok {
+				typeName = t.Name
+			}
+			if mapped, ok := postgresTypeNames[typeName]; ok {
+				typeName = mapped
+			}
+
+			resultSet.Columns[i] = domain.Column{
+				Name:    field.Name,
+				Type:    strings.ToUpper(typeName),
+				Numeric: formatter.IsNumericType(typeName),
+			}
+		}
+
+		for rows.Next() {
+			raw := rows.RawValues()
+			row := make([]domain.Value, len(raw))
+			for i, value := range raw {
+				// A nil []byte is SQL NULL
+				var cell interface{}
+				if value != nil {
+					cell = string(value)
+				}
+				row[i] = formatter.RenderValue(resultSet.Columns[i], cell, opts)
+			}
+			resultSet.Rows = append(resultSet.Rows, row)
+		}
+
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(fields) > 0 {
+			outcome.ResultSets = []*domain.ResultSet{resultSet}
+		}
+
+		// SELECT tags count returned rows, not affected ones
+		tag := rows.CommandTag()
+		if tag.Insert() || tag.Update() || tag.Delete() || strings.HasPrefix(tag.String(), "MERGE") {
+			outcome.RowsAffected = tag.RowsAffected()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outcome, nil
+}
+
+// Drop closes the connection of the sandbox role and drops the schema, the
+// objects the role owns elsewhere and the role through the pool
+func (s *postgresSandbox) Drop(ctx context.Context) error {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	if s.db != nil {
+		s.db.Close()
+		s.db = nil
+	}
+
+	identifier := pgx.Identifier{s.name}.Sanitize()
+	query := "DROP SCHEMA IF EXISTS " + identifier + " CASCADE"
+	if _, err := s.provider.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to drop schema %s: %w", s.name, err)
+	}
+
+	if s.role {
+		// e.g. tables in the public schema of servers before PostgreSQL 15
+		if _, err := s.provider.db.ExecContext(ctx, "DROP OWNED BY "+identifier+" CASCADE"); err != nil {
+			return fmt.Errorf("failed to drop objects of role %s: %w", s.name, err)
+		}
+		if _, err := s.provider.db.ExecContext(ctx, "DROP ROLE IF EXISTS "+identifier); err != nil {
+			return fmt.Errorf("failed to drop role %s: %w", s.name, err)
+		}
+		s.role = false
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// StatementValidator checks SQL text against the security rules
+type StatementValidator interface {
+	Validate(query string) error
+}
+
+// Patterns of the SQL statements that manage prepared statements
+var (
+	preparePattern    = regexp.MustCompile("(?is)^PREPARE\\s+(`[^`]+`|\\w+)\\s+FROM\\s+(.+)$")
+	executePattern    = regexp.MustCompile("(?is)^EXECUTE\\s+(`[^`]+`|\\w+)(?:\\s+USING\\s+(.+))?$")
+	deallocatePattern = regexp.MustCompile("(?is)^(?:DEALLOCATE|DROP)\\s+PREPARE\\s+(`[^`]+`|\\w+)$")
+
+	// userVariablePattern matches a single unquoted user variable
+	userVariablePattern = regexp.MustCompile(`^@[A-Za-z0-9_$.]+$`)
+
+	// dynamicSQLPattern matches PREPARE and EXECUTE statements in the body of
+	// a stored program
+	dynamicSQLPattern = regexp.MustCompile("(?i)\\b(PREPARE\\s+(`[^`]+`|\\w+)\\s+FROM|EXECUTE\\s+(`[^`]+`|\\w+))")
+)
+
+// ErrPrepareSource is returned for PREPARE statements whose text does not
+// come from a single string literal or user variable
+var ErrPrepareSource = domain.NewError(domain.CodeValidation, "PREPARE must take its text from a single string literal or user variable")
+
+// ErrProgramDynamicSQL is returned for stored programs that prepare or execute
+// statements, whose text is built at run time and never checked
+var ErrProgramDynamicSQL = domain.NewError(domain.CodePolicyViolation, "stored programs may not use PREPARE or EXECUTE")
+
+// preparedCommand is a PREPARE, EXECUTE or DEALLOCATE PREPARE statement
+type preparedCommand struct {
+	keyword string
+	name    string
+	info    *domain.PreparedStatement
+}
+
+// describePrepared parses a statement that manages a prepared statement and
+// returns nil for other statements. The text of a PREPARE statement, taken
+// from a string literal or a user variable, is checked against the security
+// and quota rules, because it is not visible when the script is validated.
+// Stored programs that prepare statements themselves are rejected.
+func (s *Sandbox) describePrepared(ctx context.Context, stmt string) (*preparedCommand, error) {
+	if err := checkProgramDynamicSQL(stmt); err != nil {
+		return nil, err
+	}
+
+	words := leadingKeywords(stmt, 1)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	stmt = trimLeadingComments(stmt)
+
+	switch words[0] {
+	case "PREPARE":
+		match := preparePattern.FindStringSubmatch(stmt)
+		if match == nil {
+			// The server rejects the statement
+			return nil, nil
+		}
+
+		name := preparedName(match[1])
+		text, err := s.preparedText(ctx, strings.TrimSpace(match[2]))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkPreparedText(text); err != nil {
+			return nil, fmt.Errorf("prepared statement %s: %w", name, err)
+		}
+
+		return &preparedCommand{
+			keyword: words[0],
+			name:    name,
+			info: &domain.PreparedStatement{
+				Name:       name,
+				ParamCount: len(findPlaceholders(text, false)),
+			},
+		}, nil
+
+	case "EXECUTE":
+		match := executePattern.FindStringSubmatch(stmt)
+		if match == nil {
+			return nil, nil
+		}
+
+		name := preparedName(match[1])
+		info := &domain.PreparedStatement{Name: name, ParamCount: s.preparedParamCount(name)}
+		if match[2] != "" {
+			for i, variable := range strings.Split(match[2], ",") {
+				info.Params = append(info.Params, domain.BoundParam{
+					Position: i + 1,
+					Name:     strings.TrimSpace(variable),
+				})
+			}
+		}
+		return &preparedCommand{keyword: words[0], name: name, info: info}, nil
+
+	case "DEALLOCATE", "DROP":
+		match := deallocatePattern.FindStringSubmatch(stmt)
+		if match == nil {
+			return nil, nil
+		}
+
+		name := preparedName(match[1])
+		return &preparedCommand{
+			keyword: "DEALLOCATE",
+			name:    name,
+			info:    &domain.PreparedStatement{Name: name, ParamCount: s.preparedParamCount(name)},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// checkProgramDynamicSQL rejects stored programs with PREPARE or EXECUTE
+// statements outside string literals and comments in their body
+func checkProgramDynamicSQL(stmt string) error {
+	text := quotaText(stmt)
+	if isStoredProgram(leadingKeywords(text, 4)) && dynamicSQLPattern.MatchString(text) {
+		return ErrProgramDynamicSQL
+	}
+	return nil
+}
+
+// trackPrepared records the prepared statements of the connection after a
+// statement ran. MySQL deallocates a statement of the same name before
+// preparing, even when preparing fails.
+func (s *Sandbox) trackPrepared(cmd *preparedCommand, err error) {
+	if cmd == nil {
+		return
+	}
+
+	switch {
+	case cmd.keyword == "PREPARE" && err == nil:
+		if s.prepared == nil {
+			s.prepared = make(map[string]int)
+		}
+		s.prepared[cmd.name] = cmd.info.ParamCount
+	case cmd.keyword == "PREPARE", cmd.keyword == "DEALLOCATE" && err == nil:
+		delete(s.prepared, cmd.name)
+	}
+}
+
+// preparedParamCount returns the parameter count of a statement prepared on
+// the connection, or -1 when it is unknown
+func (s *Sandbox) preparedParamCount(name string) int {
+	if count, ok := s.prepared[name]; ok {
+		return count
+	}
+	return -1
+}
+
+// preparedText returns the SQL text of a PREPARE statement from its string
+// literal or user variable. Other sources, e.g. expressions, literals with a
+// character set introducer or several literals, cannot be checked and are
+// rejected.
+func (s *Sandbox) preparedText(ctx context.Context, source string) (string, error) {
+	switch {
+	case source != "" && (source[0] == '\'' || source[0] == '"') && skipQuoted(source, 0) == len(source)-1:
+		return unquoteString(source), nil
+	case userVariablePattern.MatchString(source):
+		var text sql.NullString
+		if err := s.conn.QueryRowContext(ctx, "SELECT "+source).Scan(&text); err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		return text.String, nil
+	}
+	return "", ErrPrepareSource
+}
+
+// checkPreparedText checks the text of a prepared statement against the
+// security and quota rules
+func (s *Sandbox) checkPreparedText(text string) error {
+	if s.executor.validator != nil {
+		if err := s.executor.validator.Validate(text); err != nil {
+			return err
+		}
+	}
+	q := s.executor.limits.Load().quota
+	if err := q.checkStatement(text); err != nil {
+		return err
+	}
+	return q.checkSchema(text, s.dbName)
+}
+
+// preparedName normalises the name of a prepared statement, which is
+// case-insensitive
+func preparedName(name string) string {
+	return strings.ToLower(strings.Trim(name, "`"))
+}
+
+// trimLeadingComments removes the whitespace and comments before the first
+// keyword of a statement
+func trimLeadingComments(stmt string) string {
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || isDashComment(stmt, i):
+			i = skipLineComment(stmt, i) + 1
+		case c == '/' && i+1 < len(stmt) && stmt[i+1] == '*':
+			i = skipBlockComment(stmt, i) + 1
+		default:
+			return stmt[i:]
+		}
+	}
+	return ""
+}
+
+// unquoteString returns the value of a quoted SQL string literal. Doubled
+// quotes and backslash escapes are resolved.
+func unquoteString(literal string) string {
+	quote := literal[0]
+	end := skipQuoted(literal, 0)
+
+	var value strings.Builder
+	for i := 1; i < end; i++ {
+		c := literal[i]
+		switch {
+		case c == '\\' && i+1 < end:
+			i++
+			value.WriteByte(unescapeByte(literal[i]))
+		case c == quote && i+1 < end && literal[i+1] == quote:
+			i++
+			value.WriteByte(quote)
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return value.String()
+}
+
+// unescapeByte returns the character of a backslash escape sequence
+func unescapeByte(c byte) byte {
+	switch c {
+	case '0':
+		return 0
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'Z':
+		return 26
+	}
+	return c
+}
@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPreparedTextSource(t *testing.T) {
+	sandbox := &Sandbox{}
+
+	text, err := sandbox.preparedText(context.Background(), `'SELECT ''a'''`)
+	if err != nil || text != "SELECT 'a'" {
+		t.Errorf("preparedText(literal) = %q, %v", text, err)
+	}
+
+	for _, source := range []string{
+		"@x, (SELECT 1)",
+		"@x FROM t",
+		"_utf8mb4'SELECT 1'",
+		"'SELECT 1' ' FROM t'",
+		"CONCAT('SELECT ', 1)",
+		"@`x`",
+	} {
+		if _, err := sandbox.preparedText(context.Background(), source); !errors.Is(err, ErrPrepareSource) {
+			t.Errorf("preparedText(%q) error = %v, want ErrPrepareSource", source, err)
+		}
+	}
+}
+
+func TestDescribePreparedProgram(t *testing.T) {
+	sandbox := &Sandbox{}
+
+	tests := []struct {
+		stmt string
+		want error
+	}{
+		{"CREATE PROCEDURE p() BEGIN SET @q = CONCAT('DROP DATA', 'BASE other'); PREPARE s FROM @q; EXECUTE s; END", ErrProgramDynamicSQL},
+		{"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW EXECUTE `s`", ErrProgramDynamicSQL},
+		{"/*!CREATE FUNCTION f() RETURNS INT BEGIN PREPARE s FROM 'SELECT 1'; RETURN 1; END */", ErrProgramDynamicSQL},
+		{"CREATE PROCEDURE p() BEGIN SELECT 'PREPARE s FROM @q'; END", nil},
+		{"CREATE PROCEDURE p() BEGIN -- EXECUTE s\nSELECT 1; END", nil},
+		{"CREATE TABLE t (`execute` INT)", nil},
+	}
+
+	for _, tt := range tests {
+		if _, err := sandbox.describePrepared(context.Background(), tt.stmt); !errors.Is(err, tt.want) {
+			t.Errorf("describePrepared(%q) error = %v, want %v", tt.stmt, err, tt.want)
+		}
+	}
+}
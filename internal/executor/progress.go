@@ -0,0 +1,24 @@
+package executor
+
+import "context"
+
+// ProgressFunc is called with the number of finished statements of a script
+// and the number of its statements
+type ProgressFunc func(done, total int)
+
+// progressKey is the context key of the progress function
+type progressKey struct{}
+
+// WithProgress returns a context whose executions report their progress to
+// fn. A nil fn stops reporting, e.g. for the statements of a fixture.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress reports the progress of a script to the function of ctx, if
+// any
+func ReportProgress(ctx context.Context, done, total int) {
+	if fn, _ := ctx.Value(progressKey{}).(ProgressFunc); fn != nil {
+		fn(done, total)
+	}
+}
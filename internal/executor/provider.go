@@ -0,0 +1,214 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/formatter"
+)
+
+// SandboxProvider creates isolated sandboxes for an engine other than MySQL
+type SandboxProvider interface {
+	// Engine returns the engine name, e.g. domain.EngineSQLite
+	Engine() string
+
+	// Create creates an empty isolated sandbox
+	Create(ctx context.Context) (EngineSandbox, error)
+
+	// Ping checks that the engine is reachable
+	Ping(ctx context.Context) error
+
+	// Close releases the resources of the provider
+	Close() error
+}
+
+// EngineSandbox is a sandbox created by a SandboxProvider. Its statements run
+// on one pinned connection.
+type EngineSandbox interface {
+	// Name identifies the sandbox in logs and audit records
+	Name() string
+
+	// Run executes a single statement
+	Run(ctx context.Context, stmt string, opts formatter.ValueOptions) (*StatementOutcome, error)
+
+	// Drop releases the connection and removes the sandbox
+	Drop(ctx context.Context) error
+}
+
+// StatementOutcome is the result of a statement run by an EngineSandbox
+type StatementOutcome struct {
+	ResultSets   []*domain.ResultSet
+	RowsAffected int64
+	LastInsertID int64
+}
+
+// ProviderExecutor executes scripts in sandboxes created by a SandboxProvider.
+// Scripts are split and rendered the same way as for MySQL.
+type ProviderExecutor struct {
+	provider SandboxProvider
+
+	// limits holds the settings that change on configuration reload
+	limits atomic.Pointer[limits]
+}
+
+// NewProviderExecutor creates an executor for the engine of provider
+func NewProviderExecutor(provider SandboxProvider, queryTimeout time.Duration, valueOptions formatter.ValueOptions) *ProviderExecutor {
+	e := &ProviderExecutor{provider: provider}
+	e.limits.Store(&limits{queryTimeout: queryTimeout, valueOptions: valueOptions})
+	return e
+}
+
+// Reload applies the reloadable settings of cfg
+func (e *ProviderExecutor) Reload(cfg *config.Config) {
+	e.limits.Store(newLimits(cfg))
+}
+
+// Engine returns the engine name
+func (e *ProviderExecutor) Engine() string {
+	return e.provider.Engine()
+}
+
+// Ping checks that the engine is reachable
+func (e *ProviderExecutor) Ping(ctx context.Context) error {
+	return e.provider.Ping(ctx)
+}
+
+// Close closes the provider
+func (e *ProviderExecutor) Close() error {
+	return e.provider.Close()
+}
+
+// Execute executes SQL query in a new sandbox of the engine
+func (e *ProviderExecutor) Execute(ctx context.Context, req *domain.ExecuteRequest) (*domain.ExecuteResponse, error) {
+	startTime := time.Now()
+
+	mode, err := formatter.ParseMode(req.OutputMode)
+	if err != nil {
+		return domain.NewErrorResponse(domain.CodeValidation, err.Error()), nil
+	}
+
+	if req.Fixture != "" {
+		return domain.NewErrorResponse(domain.CodeValidation, fmt.Sprintf("Fixtures are not supported by the %s engine", e.Engine())), nil
+	}
+
+	if req.Params != nil {
+		return domain.NewErrorResponse(domain.CodeValidation, fmt.Sprintf("Params are not supported by the %s engine", e.Engine())), nil
+	}
+
+	statements := splitSQLStatements(req.Query)
+	if len(statements) == 0 {
+		return domain.NewErrorResponse(domain.CodeValidation, "Query execution failed: no valid SQL statements found"), nil
+	}
+
+	if req.Transactional {
+		if err := checkTransactional(statements); err != nil {
+			return domain.NewErrorResponse(domain.CodeValidation, fmt.Sprintf("Query execution failed: %v", err)), nil
+		}
+	}
+
+	// Create context with timeout
+	timeout := e.limits.Load().timeout(req)
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Create sandbox
+	sandbox, err := e.provider.Create(execCtx)
+	if err != nil {
+		return domain.NewErrorResponse(domain.CodeOf(err), fmt.Sprintf("Failed to create sandbox: %v", err)), nil
+	}
+
+	// Ensure cleanup
+	defer func() {
+		if cleanupErr := sandbox.Drop(context.Background()); cleanupErr != nil {
+			fmt.Printf("WARNING: Failed to cleanup sandbox %s: %v\n", sandbox.Name(), cleanupErr)
+		}
+	}()
+
+	var transaction *domain.TransactionState
+	if req.Transactional {
+		if _, err := sandbox.Run(execCtx, "BEGIN", e.limits.Load().valueOptions); err != nil {
+			return domain.NewErrorResponse(domain.CodeSQLError, fmt.Sprintf("Failed to start transaction: %v", err)), nil
+		}
+		transaction = &domain.TransactionState{
+			Autocommit:     true,
+			RolledBack:     true,
+			RollbackReason: domain.RollbackTransactional,
+		}
+	}
+
+	output, results, err := e.runStatements(execCtx, sandbox, statements, mode)
+
+	if req.Transactional {
+		if _, rollbackErr := sandbox.Run(context.WithoutCancel(execCtx), "ROLLBACK", e.limits.Load().valueOptions); rollbackErr != nil {
+			fmt.Printf("WARNING: Failed to roll back transaction in %s: %v\n", sandbox.Name(), rollbackErr)
+		}
+	}
+
+	var response *domain.ExecuteResponse
+	if err != nil {
+		response = domain.NewErrorResponse(describeFailure(execCtx, err, timeout))
+	} else {
+		response = domain.NewSuccessResponse(output, time.Since(startTime).Milliseconds())
+	}
+
+	response.Engine = e.Engine()
+	response.Statements = results
+	response.Transaction = transaction
+	response.Sandbox = sandbox.Name()
+
+	return response, nil
+}
+
+// runStatements executes the statements in order, stopping at the first error
+func (e *ProviderExecutor) runStatements(ctx context.Context, sandbox EngineSandbox, statements []sqlStatement, mode formatter.Mode) (string, []domain.StatementResult, error) {
+	var outputBuilder strings.Builder
+	results := make([]domain.StatementResult, 0, len(statements))
+	ReportProgress(ctx, 0, len(statements))
+
+	for i, stmt := range statements {
+		result := domain.StatementResult{
+			Index:     i + 1,
+			Statement: stmt.text,
+		}
+
+		// A trailing \G switches this statement to vertical output
+		stmtMode := mode
+		if stmt.vertical {
+			stmtMode = formatter.ModeVertical
+		}
+
+		startTime := time.Now()
+		outcome, err := sandbox.Run(ctx, stmt.text, e.limits.Load().valueOptions)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			return "", results, fmt.Errorf("error in statement %d: %w", i+1, err)
+		}
+		duration := time.Since(startTime)
+
+		result.Success = true
+		result.ExecutionTimeMs = duration.Milliseconds()
+		result.RowsAffected = outcome.RowsAffected
+		result.LastInsertID = outcome.LastInsertID
+		result.ResultSets = outcome.ResultSets
+		for _, resultSet := range outcome.ResultSets {
+			result.RowsReturned += int64(len(resultSet.Rows))
+		}
+		results = append(results, result)
+		ReportProgress(ctx, i+1, len(statements))
+
+		// Append output
+		output := renderStatement(stmt.text, stmtMode, &result, duration)
+		if outputBuilder.Len() > 0 && output != "" {
+			outputBuilder.WriteString("\n\n")
+		}
+		outputBuilder.WriteString(output)
+	}
+
+	return outputBuilder.String(), results, nil
+}
@@ -0,0 +1,365 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+)
+
+var (
+	// storageEnginePattern extracts storage engine names from table options
+	storageEnginePattern = regexp.MustCompile("(?i)\\bENGINE\\s*=?\\s*[`'\"]?(\\w+)")
+
+	// engineOptionPattern matches the text before a quoted engine name
+	engineOptionPattern = regexp.MustCompile(`(?i)\bENGINE\s*=?\s*$`)
+
+	// quotaVariablePattern matches assignments to the variables the quota
+	// relies on
+	quotaVariablePattern = regexp.MustCompile(`(?i)\bSET\b[\s\S]*\b(max_heap_table_size|tmp_table_size|information_schema_stats_expiry)\b`)
+
+	// createDatabasePattern matches CREATE DATABASE and CREATE SCHEMA
+	createDatabasePattern = regexp.MustCompile(`(?i)\bCREATE\s+(DATABASE|SCHEMA)\b`)
+
+	// qualifiedTablePattern extracts the database of a qualified table name
+	// that is created or renamed to
+	qualifiedTablePattern = regexp.MustCompile("(?i)\\b(?:TABLE(?:\\s+IF\\s+NOT\\s+EXISTS)?|RENAME(?:\\s+TO|\\s+AS)?|TO)\\s+(`[^`]+`|\\w+)\\s*\\.\\s*(?:`[^`]+`|\\w+)")
+)
+
+// quota enforces the storage limits of sandboxes. Sizes come from the data
+// and index length in information_schema.TABLES and the session temporary
+// tablespaces and are approximate. Tables may only be created in the
+// sandbox database, where they are counted.
+type quota struct {
+	maxBytes         int64
+	maxHeapTableSize int64
+	blockedEngines   map[string]bool
+}
+
+// newQuota creates a quota from its configuration
+func newQuota(cfg config.QuotaConfig) quota {
+	q := quota{
+		maxBytes:         cfg.MaxBytes,
+		maxHeapTableSize: cfg.MaxHeapTableSize,
+		blockedEngines:   make(map[string]bool, len(cfg.BlockedEngines)),
+	}
+	for _, engine := range cfg.BlockedEngines {
+		q.blockedEngines[strings.ToUpper(engine)] = true
+	}
+	return q
+}
+
+// checkStatement rejects statements that use a blocked storage engine or
+// change a variable the quota relies on. Engines are looked for in CREATE
+// TABLE and ALTER TABLE, variables in SET. Stored programs may contain
+// such statements anywhere in their body, so they are searched as a whole.
+func (q quota) checkStatement(stmt string) error {
+	text := quotaText(stmt)
+	words := leadingKeywords(text, 4)
+	if len(words) == 0 {
+		return nil
+	}
+
+	program := isStoredProgram(words)
+	if program {
+		// Bodies may build statements from string literals
+		text = stmt
+	}
+
+	if program || ((words[0] == "CREATE" || words[0] == "ALTER") && containsAny(words[1:], "TABLE")) {
+		for _, match := range storageEnginePattern.FindAllStringSubmatch(text, -1) {
+			if engine := strings.ToUpper(match[1]); q.blockedEngines[engine] {
+				return fmt.Errorf("%w: %s", domain.ErrStorageEngineBlocked, engine)
+			}
+		}
+	}
+
+	if (program || words[0] == "SET") && (q.maxBytes > 0 || q.maxHeapTableSize > 0) {
+		if match := quotaVariablePattern.FindStringSubmatch(text); match != nil {
+			return fmt.Errorf("%w: %s", domain.ErrQuotaVariable, strings.ToLower(match[1]))
+		}
+	}
+
+	return nil
+}
+
+// checkSchema rejects statements that create a database or a table outside
+// the sandbox database, where the quota would not count it. Stored programs
+// are searched as a whole.
+func (q quota) checkSchema(stmt string, dbName string) error {
+	if q.maxBytes <= 0 {
+		return nil
+	}
+
+	text := quotaText(stmt)
+	words := leadingKeywords(text, 4)
+	if len(words) == 0 {
+		return nil
+	}
+
+	if isStoredProgram(words) {
+		text = stmt
+	} else if words[0] != "CREATE" && words[0] != "ALTER" && words[0] != "RENAME" {
+		return nil
+	}
+
+	if createDatabasePattern.MatchString(text) {
+		return domain.ErrForeignSchema
+	}
+	for _, match := range qualifiedTablePattern.FindAllStringSubmatch(text, -1) {
+		if schema := strings.Trim(match[1], "`"); schema != dbName {
+			return fmt.Errorf("%w: %s", domain.ErrForeignSchema, schema)
+		}
+	}
+	return nil
+}
+
+// quotaText returns the statement without comments and with blanked string
+// literals, so that the quota checks do not match their content. Executable
+// /*! */ comments are kept, and so are quoted engine names, which MySQL
+// accepts as well.
+func quotaText(stmt string) string {
+	var text strings.Builder
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+		switch {
+		case c == '#' || isDashComment(stmt, i):
+			i = skipLineComment(stmt, i)
+			text.WriteByte(' ')
+		case c == '/' && i+1 < len(stmt) && stmt[i+1] == '*':
+			end := skipBlockComment(stmt, i)
+			if i+2 < len(stmt) && stmt[i+2] == '!' {
+				// Skip the optional version number
+				start := i + 3
+				for start < end && stmt[start] >= '0' && stmt[start] <= '9' {
+					start++
+				}
+				if body := stmt[start:end]; strings.HasSuffix(body, "*") {
+					text.WriteString(" " + quotaText(body[:len(body)-1]))
+				}
+			}
+			text.WriteByte(' ')
+			i = end
+		case c == '\'' || c == '"':
+			end := skipQuoted(stmt, i)
+			current := text.String()
+			if engineOptionPattern.MatchString(current[max(0, len(current)-32):]) {
+				text.WriteString(stmt[i : end+1])
+			} else {
+				text.WriteString("''")
+			}
+			i = end
+		case c == '`':
+			end := skipQuoted(stmt, i)
+			text.WriteString(stmt[i : end+1])
+			i = end
+		default:
+			text.WriteByte(c)
+		}
+	}
+	return text.String()
+}
+
+// isStoredProgram reports whether the leading keywords of a statement
+// create a procedure, function, trigger or event
+func isStoredProgram(words []string) bool {
+	return len(words) > 0 && words[0] == "CREATE" && containsAny(words[1:], "PROCEDURE", "FUNCTION", "TRIGGER", "EVENT")
+}
+
+// containsAny reports whether words contains one of the keywords
+func containsAny(words []string, keywords ...string) bool {
+	for _, word := range words {
+		for _, keyword := range keywords {
+			if word == keyword {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exceeded returns the quota error for a sandbox of the given size
+func (q quota) exceeded(size int64) error {
+	return fmt.Errorf("%w: %d bytes used, limit is %d bytes", domain.ErrQuotaExceeded, size, q.maxBytes)
+}
+
+// shrinksStorage reports whether a statement can only free storage. Such
+// statements are allowed in a sandbox over its quota.
+func shrinksStorage(stmt string) bool {
+	words := leadingKeywords(stmt, 1)
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "DROP", "TRUNCATE", "DELETE":
+		return true
+	}
+	return false
+}
+
+// prepareQuota configures the pinned connection for quota enforcement
+func (s *Sandbox) prepareQuota(ctx context.Context) error {
+	q := s.executor.limits.Load().quota
+
+	if q.maxHeapTableSize > 0 {
+		query := fmt.Sprintf("SET SESSION max_heap_table_size = %d, tmp_table_size = %d", q.maxHeapTableSize, q.maxHeapTableSize)
+		if _, err := s.conn.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to limit heap table size: %w", err)
+		}
+	}
+
+	if q.maxBytes > 0 {
+		disableStatsCache(ctx, s.conn)
+	}
+	return nil
+}
+
+// checkQuota measures the sandbox after a statement. A sandbox over its
+// quota only accepts statements that free storage.
+func (s *Sandbox) checkQuota(ctx context.Context, stmt string) error {
+	q := s.executor.limits.Load().quota
+	if q.maxBytes <= 0 {
+		return nil
+	}
+
+	size, err := s.size(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to measure sandbox: %w", err)
+	}
+
+	exceeded := size > q.maxBytes
+	s.quotaExceeded.Store(exceeded)
+	if exceeded && !shrinksStorage(stmt) {
+		return q.exceeded(size)
+	}
+	return nil
+}
+
+// size returns the data and index length of the tables of the sandbox,
+// including the temporary tables of its connection
+func (s *Sandbox) size(ctx context.Context) (int64, error) {
+	var size int64
+	err := s.conn.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ?`,
+		s.dbName).Scan(&size)
+	if err != nil {
+		return 0, err
+	}
+
+	var id, tempSize int64
+	err = s.conn.QueryRowContext(ctx, tempSizeQuery+" AND ID = CONNECTION_ID() GROUP BY ID").Scan(&id, &tempSize)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) && !isUnknownTable(err) {
+		return 0, err
+	}
+	return size + tempSize, nil
+}
+
+// quotaError returns the quota error of a sandbox whose statement was
+// aborted by the quota watcher, or nil
+func (s *Sandbox) quotaError() error {
+	if !s.quotaExceeded.Load() {
+		return nil
+	}
+	return fmt.Errorf("%w: statement aborted at the limit of %d bytes", domain.ErrQuotaExceeded, s.executor.limits.Load().quota.maxBytes)
+}
+
+// quotaLoop periodically measures the live sandboxes and aborts statements
+// that grow a sandbox beyond its quota, e.g. a long INSERT ... SELECT or a
+// statement in a persistent session
+func (e *MySQLExecutor) quotaLoop(interval time.Duration) {
+	defer e.wg.Done()
+
+	if interval <= 0 {
+		<-e.stop
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			e.enforceQuota(context.Background())
+		}
+	}
+}
+
+// enforceQuota measures the live sandboxes of every replica once
+func (e *MySQLExecutor) enforceQuota(ctx context.Context) {
+	q := e.limits.Load().quota
+	if q.maxBytes <= 0 {
+		return
+	}
+
+	byReplica := make(map[*replica][]*Sandbox)
+	for _, sandbox := range e.liveSandboxes() {
+		byReplica[sandbox.replica] = append(byReplica[sandbox.replica], sandbox)
+	}
+
+	for r, sandboxes := range byReplica {
+		if !r.healthy.Load() {
+			continue
+		}
+
+		sizes, err := r.schemaSizes(ctx, e.dbPrefix)
+		if err != nil {
+			fmt.Printf("WARNING: Failed to measure sandboxes on %s: %v\n", r.address, err)
+			continue
+		}
+		tempSizes, err := r.tempSizes(ctx)
+		if err != nil {
+			fmt.Printf("WARNING: Failed to measure temporary tables on %s: %v\n", r.address, err)
+			continue
+		}
+
+		var processes map[int64]process
+		for _, sandbox := range sandboxes {
+			size := sizes[sandbox.dbName] + tempSizes[sandbox.connectionID]
+			if size <= q.maxBytes {
+				sandbox.quotaExceeded.Store(false)
+				continue
+			}
+
+			if !sandbox.quotaExceeded.Swap(true) {
+				fmt.Printf("WARNING: Sandbox %s exceeds its storage quota (%d bytes)\n", sandbox.dbName, size)
+			}
+
+			// Idle sandboxes reject growing statements by themselves
+			if processes == nil {
+				if processes, err = r.processes(ctx); err != nil {
+					fmt.Printf("WARNING: Failed to read processlist on %s: %v\n", r.address, err)
+					break
+				}
+			}
+			if processes[sandbox.connectionID].info == "" {
+				continue
+			}
+
+			if _, err := r.db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", sandbox.connectionID)); err != nil {
+				fmt.Printf("WARNING: Failed to abort statement of sandbox %s: %v\n", sandbox.dbName, err)
+			}
+		}
+	}
+}
+
+// execer is implemented by *sql.DB and *sql.Conn
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// disableStatsCache makes information_schema.TABLES report current sizes
+// on the connection. MySQL 8.0 caches them for a day by default; older
+// servers and MariaDB do not know the variable and always report current
+// sizes, so errors are ignored.
+func disableStatsCache(ctx context.Context, conn execer) {
+	conn.ExecContext(ctx, "SET SESSION information_schema_stats_expiry = 0")
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+)
+
+func TestQuotaCheckStatement(t *testing.T) {
+	q := newQuota(config.QuotaConfig{
+		MaxBytes:       1024,
+		BlockedEngines: []string{"memory"},
+	})
+
+	tests := []struct {
+		stmt string
+		want error
+	}{
+		{"CREATE TABLE t (id INT) ENGINE=MEMORY", domain.ErrStorageEngineBlocked},
+		{"CREATE TABLE t (id INT) engine = `Memory`", domain.ErrStorageEngineBlocked},
+		{"ALTER TABLE t ENGINE MEMORY", domain.ErrStorageEngineBlocked},
+		{"CREATE TABLE t (id INT) ENGINE=InnoDB", nil},
+		{"SET SESSION max_heap_table_size = 1073741824", domain.ErrQuotaVariable},
+		{"SET @@tmp_table_size = 1 << 30", domain.ErrQuotaVariable},
+		{"SET information_schema_stats_expiry = 86400", domain.ErrQuotaVariable},
+		{"SELECT @@max_heap_table_size", nil},
+		{"SET @x = 1", nil},
+		{"UPDATE t SET note = 'tmp_table_size'", nil},
+		{"UPDATE t SET tmp_table_size = 1", nil},
+		{"INSERT INTO t VALUES ('ENGINE=MEMORY')", nil},
+		{"CREATE TABLE t (note TEXT COMMENT 'ENGINE=MEMORY') ENGINE=InnoDB", nil},
+		{"CREATE TABLE t (id INT) /* ENGINE=MEMORY */", nil},
+		{"CREATE TABLE t (id INT) /*!50100 ENGINE=MEMORY */", domain.ErrStorageEngineBlocked},
+		{"CREATE TABLE t (id INT) ENGINE = 'MEMORY'", domain.ErrStorageEngineBlocked},
+		{"/* setup */ SET SESSION tmp_table_size = 1", domain.ErrQuotaVariable},
+		{"/*!SET SESSION tmp_table_size = 1 */", domain.ErrQuotaVariable},
+		{"CREATE PROCEDURE p() SET SESSION tmp_table_size = 1", domain.ErrQuotaVariable},
+		{"CREATE PROCEDURE p() BEGIN CREATE TABLE m (id INT) ENGINE=MEMORY; END", domain.ErrStorageEngineBlocked},
+	}
+
+	for _, tt := range tests {
+		if err := q.checkStatement(tt.stmt); !errors.Is(err, tt.want) {
+			t.Errorf("checkStatement(%q) = %v, want %v", tt.stmt, err, tt.want)
+		}
+	}
+
+	// Without limits the variables may be changed
+	if err := newQuota(config.QuotaConfig{}).checkStatement("SET max_heap_table_size = 1"); err != nil {
+		t.Errorf("Unexpected error without quota: %v", err)
+	}
+}
+
+func TestQuotaCheckSchema(t *testing.T) {
+	q := newQuota(config.QuotaConfig{MaxBytes: 1024})
+
+	tests := []struct {
+		stmt string
+		want error
+	}{
+		{"CREATE DATABASE other", domain.ErrForeignSchema},
+		{"/*!CREATE SCHEMA other */", domain.ErrForeignSchema},
+		{"CREATE TABLE other.t (id INT)", domain.ErrForeignSchema},
+		{"CREATE TEMPORARY TABLE IF NOT EXISTS `other` . `t` (id INT)", domain.ErrForeignSchema},
+		{"RENAME TABLE t TO other.t", domain.ErrForeignSchema},
+		{"ALTER TABLE t RENAME TO `other`.t", domain.ErrForeignSchema},
+		{"CREATE PROCEDURE p() CREATE TABLE other.t (id INT)", domain.ErrForeignSchema},
+		{"CREATE TABLE student_db_1.t (id INT)", nil},
+		{"CREATE TABLE `student_db_1`.`t` AS SELECT t.id FROM s AS t", nil},
+		{"CREATE TEMPORARY TABLE t (id INT)", nil},
+		{"ALTER TABLE t RENAME COLUMN a TO b", nil},
+		{"CREATE TABLE t (note TEXT COMMENT 'see other.t')", nil},
+		{"INSERT INTO other.t VALUES (1)", nil},
+	}
+
+	for _, tt := range tests {
+		if err := q.checkSchema(tt.stmt, "student_db_1"); !errors.Is(err, tt.want) {
+			t.Errorf("checkSchema(%q) = %v, want %v", tt.stmt, err, tt.want)
+		}
+	}
+
+	// Without a quota nothing needs to be counted
+	if err := newQuota(config.QuotaConfig{}).checkSchema("CREATE DATABASE other", "student_db_1"); err != nil {
+		t.Errorf("Unexpected error without quota: %v", err)
+	}
+}
+
+func TestShrinksStorage(t *testing.T) {
+	tests := map[string]bool{
+		"DROP TABLE t":                  true,
+		"truncate t":                    true,
+		"DELETE FROM t WHERE id > 1":    true,
+		"INSERT INTO t SELECT * FROM t": false,
+		"SELECT 1":                      false,
+	}
+
+	for stmt, want := range tests {
+		if got := shrinksStorage(stmt); got != want {
+			t.Errorf("shrinksStorage(%q) = %v, want %v", stmt, got, want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// probeOwner owns the sandboxes created by readiness probes
+const probeOwner = "readyz"
+
+// Probe creates and drops a sandbox to check that the backend can serve
+// executions
+func (e *MySQLExecutor) Probe(ctx context.Context) error {
+	sandbox, err := newSandbox(ctx, e, e.dbPrefix, probeOwner)
+	if err != nil {
+		return err
+	}
+	return sandbox.Cleanup(ctx)
+}
+
+// PoolStats sums the connection pool statistics of the replicas
+func (e *MySQLExecutor) PoolStats() domain.PoolStats {
+	stats := domain.PoolStats{Backend: e.name}
+	for _, r := range e.replicas {
+		dbStats := r.db.Stats()
+		stats.Open += dbStats.OpenConnections
+		stats.InUse += dbStats.InUse
+		stats.MaxOpen += dbStats.MaxOpenConnections
+		stats.WaitCount += dbStats.WaitCount
+	}
+
+	// Unlimited pools are never saturated
+	if stats.MaxOpen > 0 {
+		stats.Usage = float64(stats.InUse) / float64(stats.MaxOpen)
+	}
+	return stats
+}
+
+// recordFailedDrop records a sandbox whose database could not be dropped
+func (e *MySQLExecutor) recordFailedDrop(name string) {
+	e.sandboxesMu.Lock()
+	defer e.sandboxesMu.Unlock()
+	e.failedDrops[name] = struct{}{}
+}
+
+// forgetFailedDrop forgets a sandbox once its database has been dropped
+func (e *MySQLExecutor) forgetFailedDrop(name string) {
+	e.sandboxesMu.Lock()
+	defer e.sandboxesMu.Unlock()
+	delete(e.failedDrops, name)
+}
+
+// CleanupBacklog returns the number of sandboxes whose database could not be
+// dropped and awaits the orphan cleanup
+func (e *MySQLExecutor) CleanupBacklog() int {
+	e.sandboxesMu.Lock()
+	defer e.sandboxesMu.Unlock()
+	return len(e.failedDrops)
+}
+
+// Probe creates and drops a sandbox on every backend and returns the first
+// error
+func (b *Backends) Probe(ctx context.Context) error {
+	for _, name := range b.names {
+		if err := b.backends[name].Probe(ctx); err != nil {
+			return fmt.Errorf("backend %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// PoolStats returns the connection pool statistics of every backend
+func (b *Backends) PoolStats() []domain.PoolStats {
+	stats := make([]domain.PoolStats, 0, len(b.names))
+	for _, name := range b.names {
+		stats = append(stats, b.backends[name].PoolStats())
+	}
+	return stats
+}
+
+// CleanupBacklog returns the number of sandboxes of every backend that could
+// not be dropped
+func (b *Backends) CleanupBacklog() int {
+	count := 0
+	for _, exec := range b.backends {
+		count += exec.CleanupBacklog()
+	}
+	return count
+}
+
+// WaitReady pings the backends until each has a reachable replica, backing
+// off between attempts. It gives up after cfg.MaxWait, unless that is 0, or
+// when ctx is done.
+func (b *Backends) WaitReady(ctx context.Context, cfg config.StartupConfig) error {
+	start := time.Now()
+	backoff := cfg.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		var errs []error
+		for _, name := range b.names {
+			if err := b.backends[name].Ping(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("backend %s: %w", name, err))
+			}
+		}
+		err := errors.Join(errs...)
+		if err == nil {
+			return nil
+		}
+
+		if cfg.MaxWait > 0 && time.Since(start)+backoff > cfg.MaxWait {
+			return fmt.Errorf("MySQL not reachable after %s: %w", time.Since(start).Round(time.Second), err)
+		}
+		fmt.Printf("⏳ Waiting for MySQL (attempt %d), retrying in %s: %v\n", attempt, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, cfg.MaxBackoff)
+	}
+}
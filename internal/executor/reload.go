@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/formatter"
+)
+
+// limits holds the executor settings that can change without a restart.
+// Executions read them once per use, so a reload affects new statements only.
+type limits struct {
+	queryTimeout time.Duration
+	valueOptions formatter.ValueOptions
+	quota        quota
+}
+
+// newLimits creates the limits of an executor from its configuration
+func newLimits(cfg *config.Config) *limits {
+	return &limits{
+		queryTimeout: cfg.Executor.QueryTimeout,
+		valueOptions: formatter.ValueOptions{
+			JSONStyle:      cfg.Executor.JSONStyle,
+			MaxValueLength: cfg.Executor.MaxValueLength,
+		},
+		quota: newQuota(cfg.Quota),
+	}
+}
+
+// timeout returns the query timeout of a request. Requests may bring their
+// own, e.g. jobs that are allowed to run longer.
+func (l *limits) timeout(req *domain.ExecuteRequest) time.Duration {
+	if req.Timeout > 0 {
+		return req.Timeout
+	}
+	return l.queryTimeout
+}
+
+// reloader is implemented by executors with reloadable settings
+type reloader interface {
+	Reload(cfg *config.Config)
+}
+
+// Reload applies the reloadable settings of cfg
+func (e *MySQLExecutor) Reload(cfg *config.Config) {
+	e.limits.Store(newLimits(cfg))
+}
+
+// Reload applies the reloadable settings of cfg to every backend
+func (b *Backends) Reload(cfg *config.Config) {
+	for _, exec := range b.backends {
+		exec.Reload(cfg)
+	}
+}
+
+// Reload applies the reloadable settings of cfg to every engine
+func (e *Engines) Reload(cfg *config.Config) {
+	for _, exec := range e.executors {
+		if r, ok := exec.(reloader); ok {
+			r.Reload(cfg)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// replicaCheckTimeout bounds a single health check
+const replicaCheckTimeout = 2 * time.Second
+
+// replica is one server of a backend with its own connection pool
+type replica struct {
+	address string
+	db      *sql.DB
+	healthy atomic.Bool
+
+	mu        sync.Mutex
+	lastError string
+}
+
+// openReplica opens the connection pool of a replica. The server is not
+// contacted until the first check.
+func openReplica(backend config.BackendConfig, replicaCfg config.ReplicaConfig) (*replica, error) {
+	cfg, err := mysqlConfig(backend, replicaCfg)
+	if err != nil {
+		return nil, err
+	}
+	address := cfg.Addr
+
+	// Open connection
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MySQL connection to %s: %w", address, err)
+	}
+	db := sql.OpenDB(connector)
+
+	// Configure connection pool
+	db.SetMaxOpenConns(backend.MaxOpenConns)
+	db.SetMaxIdleConns(backend.MaxIdleConns)
+	db.SetConnMaxLifetime(backend.ConnMaxLifetime)
+
+	return &replica{address: address, db: db}, nil
+}
+
+// OpenDB opens a connection pool to the first replica of a backend, for
+// connections outside of sandboxes such as the audit table
+func OpenDB(backend config.BackendConfig) (*sql.DB, error) {
+	r, err := openReplica(backend, backend.Replicas[0])
+	if err != nil {
+		return nil, err
+	}
+	return r.db, nil
+}
+
+// check pings the replica and records whether it is healthy
+func (r *replica) check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+	defer cancel()
+
+	err := r.db.PingContext(ctx)
+
+	r.mu.Lock()
+	if err != nil {
+		r.lastError = err.Error()
+	} else {
+		r.lastError = ""
+	}
+	r.mu.Unlock()
+
+	// Log transitions only
+	if r.healthy.Swap(err == nil) != (err == nil) {
+		if err != nil {
+			fmt.Printf("WARNING: MySQL replica %s is down: %v\n", r.address, err)
+		} else {
+			fmt.Printf("MySQL replica %s is up\n", r.address)
+		}
+	}
+
+	return err
+}
+
+// health returns the last recorded state of the replica
+func (r *replica) health() domain.ReplicaHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return domain.ReplicaHealth{
+		Address: r.address,
+		Healthy: r.healthy.Load(),
+		Error:   r.lastError,
+	}
+}
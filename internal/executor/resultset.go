@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"database/sql"
+	"fmt"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/formatter"
+)
+
+// readResultSets reads every result set of rows. Results without columns,
+// such as the outcome of an INSERT or the final status of a procedure, are
+// skipped.
+func readResultSets(rows *sql.Rows, opts formatter.ValueOptions) ([]*domain.ResultSet, error) {
+	var resultSets []*domain.ResultSet
+	for {
+		resultSet, err := readResultSet(rows, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resultSet.Columns) > 0 {
+			resultSets = append(resultSets, resultSet)
+		}
+
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return resultSets, nil
+}
+
+// readResultSet reads all rows of the current result set for formatting.
+// Values are rendered according to their column types.
+func readResultSet(rows *sql.Rows, opts formatter.ValueOptions) (*domain.ResultSet, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	resultSet := &domain.ResultSet{
+		Columns: make([]domain.Column, len(columnTypes)),
+	}
+	for i, ct := range columnTypes {
+		resultSet.Columns[i] = domain.Column{
+			Name:    ct.Name(),
+			Type:    ct.DatabaseTypeName(),
+			Numeric: formatter.IsNumericType(ct.DatabaseTypeName()),
+		}
+		if _, scale, ok := ct.DecimalSize(); ok && scale < 64 {
+			resultSet.Columns[i].Scale = int(scale)
+		}
+	}
+
+	columnCount := len(columnTypes)
+	if columnCount == 0 {
+		return resultSet, nil
+	}
+
+	values := make([]interface{}, columnCount)
+	valuePtrs := make([]interface{}, columnCount)
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		// Scan the row
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		// Convert values to cells
+		row := make([]domain.Value, columnCount)
+		for i, val := range values {
+			row[i] = formatter.RenderValue(resultSet.Columns[i], val, opts)
+		}
+		resultSet.Rows = append(resultSet.Rows, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return resultSet, nil
+}
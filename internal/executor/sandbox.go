@@ -2,157 +2,454 @@ package executor
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
+	"sync/atomic"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/formatter"
 
 	"github.com/google/uuid"
 )
 
-// Sandbox represents an isolated MySQL database for query execution
+// Sandbox represents an isolated MySQL database for query execution.
+// All statements of a sandbox run on one pinned connection so that session
+// state (current database, variables, warnings) is preserved between them.
 type Sandbox struct {
-	executor *MySQLExecutor
-	dbName   string
+	executor     *MySQLExecutor
+	replica      *replica
+	dbName       string
+	owner        string
+	createdAt    time.Time
+	connectionID int64
+	conn         *sql.Conn
+	tx           domain.TransactionState
+
+	// lastInsertID is the last LAST_INSERT_ID() value seen on the connection
+	lastInsertID int64
+
+	// prepared holds the parameter counts of the statements prepared with
+	// PREPARE on the connection by name
+	prepared map[string]int
+
+	// lastActivity is the start of the last execution in Unix nanoseconds
+	lastActivity atomic.Int64
+
+	// quotaExceeded is set while the sandbox is over its storage quota
+	quotaExceeded atomic.Bool
 }
 
-// NewSandbox creates a new isolated sandbox database
-func NewSandbox(executor *MySQLExecutor, dbPrefix string) (*Sandbox, error) {
+// newSandbox creates a new isolated sandbox database on one of the replicas
+// of the executor's backend within the deadline of ctx. When a replica turns
+// out to be down the next one is tried. The owner is only used for
+// bookkeeping.
+func newSandbox(ctx context.Context, executor *MySQLExecutor, dbPrefix string, owner string) (*Sandbox, error) {
 	// Generate unique database name using UUID
 	dbName := fmt.Sprintf("%s%s", dbPrefix, generateShortUUID())
 
-	sandbox := &Sandbox{
-		executor: executor,
-		dbName:   dbName,
+	// Fail fast while the backend is down
+	if err := executor.breaker.allow(); err != nil {
+		return nil, err
 	}
 
-	// Create the temporary database
-	if err := sandbox.create(context.Background()); err != nil {
-		return nil, err
+	// Reserve the name before the database exists, so that it is never
+	// mistaken for an orphan
+	executor.reserveSandbox(dbName)
+
+	var lastErr error
+	reachable := false
+	for _, r := range executor.candidates() {
+		sandbox := &Sandbox{
+			executor:  executor,
+			replica:   r,
+			dbName:    dbName,
+			owner:     owner,
+			createdAt: time.Now().UTC(),
+		}
+
+		// Create the temporary database
+		err := sandbox.create(ctx)
+		if err == nil {
+			sandbox.lastActivity.Store(sandbox.createdAt.UnixNano())
+			executor.registerSandbox(sandbox)
+			executor.breaker.success()
+			return sandbox, nil
+		}
+		lastErr = err
+
+		// An expired or cancelled request says nothing about the server
+		if ctx.Err() != nil {
+			executor.breaker.release()
+			executor.unregisterSandbox(dbName)
+			return nil, err
+		}
+
+		// Errors of a reachable server are not solved by failing over
+		if r.check(ctx) == nil {
+			reachable = true
+			break
+		}
 	}
 
-	return sandbox, nil
+	executor.unregisterSandbox(dbName)
+	if reachable {
+		executor.breaker.success()
+		return nil, lastErr
+	}
+	executor.breaker.failure()
+	return nil, fmt.Errorf("%w: %w", domain.ErrDatabaseConnection, lastErr)
 }
 
-// create creates the temporary database
+// create creates the temporary database and pins a connection to it
 func (s *Sandbox) create(ctx context.Context) error {
 	query := fmt.Sprintf("CREATE DATABASE `%s`", s.dbName)
-	_, err := s.executor.db.ExecContext(ctx, query)
+	_, err := s.replica.db.ExecContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%w %s: %w", domain.ErrDatabaseCreation, s.dbName, err)
+	}
+
+	conn, err := s.replica.db.Conn(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create database %s: %w", s.dbName, err)
+		s.Cleanup(context.WithoutCancel(ctx))
+		return fmt.Errorf("%w for %s: %w", domain.ErrDatabaseConnection, s.dbName, err)
 	}
+	s.conn = conn
+
+	// Pooled connections keep session state from earlier sandboxes, so
+	// LAST_INSERT_ID() is reset to the value of a fresh connection
+	err = s.conn.QueryRowContext(ctx, "SELECT @@autocommit, LAST_INSERT_ID(0), CONNECTION_ID()").Scan(&s.tx.Autocommit, &s.lastInsertID, &s.connectionID)
+	if err != nil {
+		s.Cleanup(context.WithoutCancel(ctx))
+		return fmt.Errorf("failed to read session state for %s: %w", s.dbName, err)
+	}
+
+	if err := s.prepareQuota(ctx); err != nil {
+		s.Cleanup(context.WithoutCancel(ctx))
+		return err
+	}
+
 	return nil
 }
 
-// Cleanup drops the temporary database
+// Cleanup rolls back an open transaction, releases the pinned connection and
+// drops the temporary database. The database is dropped through the pool
+// because the pinned connection may have been closed by a cancelled query.
 func (s *Sandbox) Cleanup(ctx context.Context) error {
+	if s.conn != nil {
+		s.rollback(ctx, domain.RollbackEnd)
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	// A database that cannot be dropped is left to the orphan cleanup
+	defer s.executor.unregisterSandbox(s.dbName)
+
 	query := fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", s.dbName)
-	_, err := s.executor.db.ExecContext(ctx, query)
+	_, err := s.replica.db.ExecContext(ctx, query)
 	if err != nil {
-		return fmt.Errorf("failed to drop database %s: %w", s.dbName, err)
+		s.executor.recordFailedDrop(s.dbName)
+		return fmt.Errorf("%w %s: %w", domain.ErrDatabaseCleanup, s.dbName, err)
 	}
 	return nil
 }
 
-// ExecuteQuery executes SQL query in the sandbox and returns formatted output
-func (s *Sandbox) ExecuteQuery(ctx context.Context, query string) (string, error) {
-	// First, switch to the sandbox database
+// Name returns the name of the sandbox database
+func (s *Sandbox) Name() string {
+	return s.dbName
+}
+
+// Executor returns the executor of the backend the sandbox was created on
+func (s *Sandbox) Executor() *MySQLExecutor {
+	return s.executor
+}
+
+// use makes the sandbox database the current database of the pinned connection
+func (s *Sandbox) use(ctx context.Context) error {
 	useQuery := fmt.Sprintf("USE `%s`", s.dbName)
-	if _, err := s.executor.db.ExecContext(ctx, useQuery); err != nil {
-		return "", fmt.Errorf("failed to switch to database %s: %w", s.dbName, err)
+	if _, err := s.conn.ExecContext(ctx, useQuery); err != nil {
+		return fmt.Errorf("failed to switch to database %s: %w", s.dbName, err)
+	}
+	return nil
+}
+
+// LoadFixture runs the script of the named fixture in the sandbox
+func (s *Sandbox) LoadFixture(ctx context.Context, name string) error {
+	fixture, err := s.executor.fixtures.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if len(splitSQLStatements(fixture.Script)) == 0 {
+		return s.use(ctx)
+	}
+
+	// The statements of the fixture are not part of the progress of a script
+	if _, _, err := s.ExecuteQuery(WithProgress(ctx, nil), fixture.Script, nil, formatter.ModeBatch); err != nil {
+		return fmt.Errorf("failed to load fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+// ExecuteQuery executes SQL query in the sandbox and returns output formatted
+// in the given mode together with the outcome of every attempted statement.
+// The params, if any, are bound to the placeholders of the query.
+func (s *Sandbox) ExecuteQuery(ctx context.Context, query string, params *domain.Params, mode formatter.Mode) (string, []domain.StatementResult, error) {
+	s.lastActivity.Store(time.Now().UnixNano())
+
+	// First, switch to the sandbox database
+	if err := s.use(ctx); err != nil {
+		return "", nil, err
 	}
 
 	// Split query into individual statements
 	statements := splitSQLStatements(query)
 	if len(statements) == 0 {
-		return "", fmt.Errorf("no valid SQL statements found")
+		return "", nil, fmt.Errorf("no valid SQL statements found")
+	}
+
+	bound, err := bindParams(statements, params)
+	if err != nil {
+		return "", nil, err
 	}
 
 	var outputBuilder strings.Builder
+	results := make([]domain.StatementResult, 0, len(statements))
+	ReportProgress(ctx, 0, len(statements))
 
 	// Execute each statement
 	for i, stmt := range statements {
-		if strings.TrimSpace(stmt) == "" {
-			continue
+		result := domain.StatementResult{
+			Index:     i + 1,
+			Statement: stmt.text,
+		}
+
+		// A trailing \G switches this statement to vertical output
+		stmtMode := mode
+		if stmt.vertical {
+			stmtMode = formatter.ModeVertical
 		}
 
 		// Execute statement
-		result, err := s.executeStatement(ctx, stmt)
+		output, err := s.executeStatement(ctx, stmt.text, bound[i], stmtMode, &result)
+
 		if err != nil {
-			return "", fmt.Errorf("error in statement %d: %w", i+1, err)
+			s.rollback(ctx, domain.RollbackError)
+			result.Error = err.Error()
+			result.InTransaction = s.tx.InTransaction
+			results = append(results, result)
+			return "", results, fmt.Errorf("error in statement %d: %w", i+1, err)
+		}
+
+		if err := s.trackTransaction(ctx, stmt.text); err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			return "", results, fmt.Errorf("error in statement %d: %w", i+1, err)
 		}
 
+		result.Success = true
+		result.InTransaction = s.tx.InTransaction
+		results = append(results, result)
+		ReportProgress(ctx, i+1, len(statements))
+
 		// Append output
-		if i > 0 {
+		if outputBuilder.Len() > 0 && output != "" {
 			outputBuilder.WriteString("\n\n")
 		}
-		outputBuilder.WriteString(result)
+		outputBuilder.WriteString(output)
+	}
+
+	return outputBuilder.String(), results, nil
+}
+
+// executeStatement executes a single SQL statement and formats the output.
+// Every statement is sent with QueryContext; whether it produced result sets
+// is decided from the returned column metadata rather than from keywords, so
+// WITH ... SELECT, (SELECT ...), TABLE t, VALUES ROW(...), CHECK TABLE and
+// statements preceded by comments all return their rows. A statement with
+// bound params runs as a server-side prepared statement.
+func (s *Sandbox) executeStatement(ctx context.Context, stmt string, bound *boundStatement, mode formatter.Mode, result *domain.StatementResult) (string, error) {
+	q := s.executor.limits.Load().quota
+	if err := q.checkStatement(stmt); err != nil {
+		return "", err
+	}
+	if err := q.checkSchema(stmt, s.dbName); err != nil {
+		return "", err
+	}
+
+	cmd, err := s.describePrepared(ctx, stmt)
+	if err != nil {
+		return "", err
+	}
+	if cmd != nil {
+		result.Prepared = cmd.info
+	}
+
+	startTime := time.Now()
+
+	if bound != nil {
+		err = s.queryPrepared(ctx, bound, result)
+	} else {
+		err = s.queryResultSets(ctx, stmt, result)
+	}
+	s.trackPrepared(cmd, err)
+	if err != nil {
+		// A statement aborted by the quota watcher reports the quota
+		if quotaErr := s.quotaError(); quotaErr != nil {
+			return "", quotaErr
+		}
+		return "", err
+	}
+
+	duration := time.Since(startTime)
+	result.ExecutionTimeMs = duration.Milliseconds()
+
+	if err := s.collectStatus(ctx, result); err != nil {
+		return "", err
+	}
+
+	if err := s.checkQuota(ctx, stmt); err != nil {
+		return "", err
 	}
 
-	return outputBuilder.String(), nil
+	return renderStatement(stmt, mode, result, duration), nil
 }
 
-// executeStatement executes a single SQL statement and formats the output
-func (s *Sandbox) executeStatement(ctx context.Context, stmt string) (string, error) {
-	// Determine if this is a SELECT query
-	trimmedStmt := strings.TrimSpace(strings.ToUpper(stmt))
-	isSelect := strings.HasPrefix(trimmedStmt, "SELECT") ||
-		strings.HasPrefix(trimmedStmt, "SHOW") ||
-		strings.HasPrefix(trimmedStmt, "DESCRIBE") ||
-		strings.HasPrefix(trimmedStmt, "DESC") ||
-		strings.HasPrefix(trimmedStmt, "EXPLAIN")
+// renderStatement formats the result sets and status of an executed
+// statement like the mysql client does
+func renderStatement(stmt string, mode formatter.Mode, result *domain.StatementResult, duration time.Duration) string {
+	// A procedure call ends with a status after its result sets
+	isCall := false
+	if words := leadingKeywords(stmt, 1); len(words) == 1 && words[0] == "CALL" {
+		isCall = true
+	}
 
-	if isSelect {
-		return s.executeSelectStatement(ctx, stmt)
+	outputs := make([]string, 0, len(result.ResultSets)+1)
+	for i, resultSet := range result.ResultSets {
+		// Warnings are reported once, on the last summary line
+		warningCount := 0
+		if i == len(result.ResultSets)-1 && !isCall {
+			warningCount = result.WarningCount
+		}
+		outputs = append(outputs, renderResultSet(mode, resultSet, warningCount, duration))
 	}
 
-	return s.executeNonSelectStatement(ctx, stmt)
+	if len(result.ResultSets) == 0 || isCall {
+		// Batch mode has no status lines
+		if summary := formatter.OKSummary(mode, result.RowsAffected, result.WarningCount, duration); summary != "" {
+			outputs = append(outputs, summary)
+		}
+	}
+
+	return joinOutput(
+		strings.Join(outputs, "\n\n"),
+		formatter.Warnings(mode, result.Warnings),
+	)
 }
 
-// executeSelectStatement executes a SELECT-like statement and formats results as a table
-func (s *Sandbox) executeSelectStatement(ctx context.Context, stmt string) (string, error) {
-	rows, err := s.executor.db.QueryContext(ctx, stmt)
+// queryResultSets runs a statement and reads all of its result sets into
+// the statement result
+func (s *Sandbox) queryResultSets(ctx context.Context, stmt string, result *domain.StatementResult) error {
+	rows, err := s.conn.QueryContext(ctx, stmt)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer rows.Close()
 
-	return formatResultSet(rows)
+	return s.readRows(rows, result)
 }
 
-// executeNonSelectStatement executes INSERT, UPDATE, DELETE, CREATE, etc.
-func (s *Sandbox) executeNonSelectStatement(ctx context.Context, stmt string) (string, error) {
-	result, err := s.executor.db.ExecContext(ctx, stmt)
+// readRows reads all result sets of a statement into the statement result
+func (s *Sandbox) readRows(rows *sql.Rows, result *domain.StatementResult) error {
+	resultSets, err := readResultSets(rows, s.executor.limits.Load().valueOptions)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	lastInsertID, _ := result.LastInsertId()
+	result.ResultSets = resultSets
+	for _, resultSet := range resultSets {
+		result.RowsReturned += int64(len(resultSet.Rows))
+	}
+	return nil
+}
 
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Query OK, %d row(s) affected", rowsAffected))
+// queryPrepared runs a statement with bound params as a server-side prepared
+// statement and reads all of its result sets into the statement result
+func (s *Sandbox) queryPrepared(ctx context.Context, bound *boundStatement, result *domain.StatementResult) error {
+	result.Prepared = &domain.PreparedStatement{
+		ParamCount: len(bound.args),
+		Params:     bound.params,
+	}
 
-	if lastInsertID > 0 {
-		output.WriteString(fmt.Sprintf(" (last insert ID: %d)", lastInsertID))
+	stmt, err := s.conn.PrepareContext(ctx, bound.text)
+	if err != nil {
+		return err
 	}
+	defer stmt.Close()
 
-	return output.String(), nil
+	rows, err := stmt.QueryContext(ctx, bound.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return s.readRows(rows, result)
 }
 
-// splitSQLStatements splits SQL string into individual statements
-func splitSQLStatements(query string) []string {
-	// Simple split by semicolon
-	// TODO: Handle strings with semicolons inside
-	parts := strings.Split(query, ";")
+// renderResultSet formats a result set followed by its summary line
+func renderResultSet(mode formatter.Mode, resultSet *domain.ResultSet, warningCount int, duration time.Duration) string {
+	var body string
+	if len(resultSet.Rows) > 0 || mode == formatter.ModeBatch {
+		body = formatter.Render(mode, resultSet)
+	}
+	return body + formatter.ResultSummary(mode, len(resultSet.Rows), warningCount, duration)
+}
 
-	statements := make([]string, 0, len(parts))
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if trimmed != "" {
-			statements = append(statements, trimmed)
+// collectStatus reads the outcome of the previous statement on the pinned
+// connection: affected rows, a newly generated AUTO_INCREMENT value and
+// warnings. SHOW WARNINGS is only issued when the warning count is non-zero.
+func (s *Sandbox) collectStatus(ctx context.Context, result *domain.StatementResult) error {
+	var rowCount, lastInsertID int64
+	var warningCount int
+	err := s.conn.QueryRowContext(ctx, "SELECT ROW_COUNT(), LAST_INSERT_ID(), @@warning_count").
+		Scan(&rowCount, &lastInsertID, &warningCount)
+	if err != nil {
+		return fmt.Errorf("failed to read statement status: %w", err)
+	}
+
+	// ROW_COUNT() is -1 for statements that return a result set
+	if rowCount > 0 {
+		result.RowsAffected = rowCount
+	}
+
+	// LAST_INSERT_ID() keeps its value until the next generated id, so a
+	// new id is reported when the value changes
+	if lastInsertID != s.lastInsertID {
+		result.LastInsertID = lastInsertID
+		s.lastInsertID = lastInsertID
+	}
+
+	result.WarningCount = warningCount
+	if warningCount == 0 {
+		return nil
+	}
+
+	rows, err := s.conn.QueryContext(ctx, "SHOW WARNINGS")
+	if err != nil {
+		return fmt.Errorf("failed to fetch warnings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var warning domain.Warning
+		if err := rows.Scan(&warning.Level, &warning.Code, &warning.Message); err != nil {
+			return fmt.Errorf("failed to scan warning: %w", err)
 		}
+		result.Warnings = append(result.Warnings, warning)
 	}
 
-	return statements
+	return rows.Err()
 }
 
 // generateShortUUID generates a short UUID for database names
@@ -161,3 +458,14 @@ func generateShortUUID() string {
 	// Take first 8 characters (remove hyphens)
 	return strings.ReplaceAll(fullUUID[:13], "-", "")
 }
+
+// joinOutput joins the non-empty parts of a statement output with newlines
+func joinOutput(parts ...string) string {
+	nonEmpty := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "\n")
+}
@@ -0,0 +1,46 @@
+package executor
+
+import "context"
+
+// Scheduler limits the number of executions running at once across the
+// server. Executions beyond the limit wait for a slot in arrival order.
+type Scheduler struct {
+	slots chan struct{}
+}
+
+// NewScheduler creates a scheduler running at most maxConcurrent executions
+// at once, or any number when maxConcurrent is 0
+func NewScheduler(maxConcurrent int) *Scheduler {
+	s := &Scheduler{}
+	if maxConcurrent > 0 {
+		s.slots = make(chan struct{}, maxConcurrent)
+	}
+	return s
+}
+
+// Acquire waits for a free slot. It fails with the context error when ctx
+// ends first. Every successful Acquire must be followed by Release.
+func (s *Scheduler) Acquire(ctx context.Context) error {
+	if s.slots == nil {
+		return nil
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees the slot taken by Acquire
+func (s *Scheduler) Release() {
+	if s.slots != nil {
+		<-s.slots
+	}
+}
+
+// Running returns the number of executions holding a slot
+func (s *Scheduler) Running() int {
+	return len(s.slots)
+}
@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	s := NewScheduler(1)
+	if err := s.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire on a full scheduler error = %v, want DeadlineExceeded", err)
+	}
+
+	s.Release()
+	if err := s.Acquire(context.Background()); err != nil {
+		t.Errorf("Acquire after Release error = %v", err)
+	}
+	if s.Running() != 1 {
+		t.Errorf("Running = %d, want 1", s.Running())
+	}
+
+	unlimited := NewScheduler(0)
+	for i := 0; i < 3; i++ {
+		if err := unlimited.Acquire(context.Background()); err != nil {
+			t.Errorf("unlimited Acquire error = %v", err)
+		}
+	}
+}
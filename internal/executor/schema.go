@@ -0,0 +1,267 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// Schema reads the tables, views and routines of the sandbox database from
+// information_schema
+func (s *Sandbox) Schema(ctx context.Context) (*domain.Schema, error) {
+	schema := &domain.Schema{
+		Database: s.dbName,
+		Tables:   []domain.TableInfo{},
+		Views:    []domain.ViewInfo{},
+		Routines: []domain.RoutineInfo{},
+	}
+
+	tables, err := s.readTables(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.readColumns(ctx, schema, tables); err != nil {
+		return nil, err
+	}
+
+	if err := s.readIndexes(ctx, tables); err != nil {
+		return nil, err
+	}
+
+	if err := s.readForeignKeys(ctx, tables); err != nil {
+		return nil, err
+	}
+
+	if err := s.readRoutines(ctx, schema); err != nil {
+		return nil, err
+	}
+
+	schema.ComputeETag()
+	return schema, nil
+}
+
+// readTables reads base tables and views. It returns an index of the tables
+// by name for the following queries.
+func (s *Sandbox) readTables(ctx context.Context, schema *domain.Schema) (map[string]*domain.TableInfo, error) {
+	rows, err := s.conn.QueryContext(ctx, `
+		SELECT TABLE_NAME, TABLE_TYPE, ENGINE, TABLE_COMMENT
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME`, s.dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tables: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, tableType string
+		var engine, comment sql.NullString
+		if err := rows.Scan(&name, &tableType, &engine, &comment); err != nil {
+			return nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+
+		if tableType == "VIEW" {
+			schema.Views = append(schema.Views, domain.ViewInfo{Name: name, Columns: []domain.ColumnInfo{}})
+			continue
+		}
+
+		schema.Tables = append(schema.Tables, domain.TableInfo{
+			Name:        name,
+			Engine:      engine.String,
+			Comment:     comment.String,
+			Columns:     []domain.ColumnInfo{},
+			Indexes:     []domain.IndexInfo{},
+			ForeignKeys: []domain.ForeignKeyInfo{},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tables: %w", err)
+	}
+
+	tables := make(map[string]*domain.TableInfo, len(schema.Tables))
+	for i := range schema.Tables {
+		tables[schema.Tables[i].Name] = &schema.Tables[i]
+	}
+	return tables, nil
+}
+
+// readColumns reads the columns of tables and views in ordinal order
+func (s *Sandbox) readColumns(ctx context.Context, schema *domain.Schema, tables map[string]*domain.TableInfo) error {
+	views := make(map[string]*domain.ViewInfo, len(schema.Views))
+	for i := range schema.Views {
+		views[schema.Views[i].Name] = &schema.Views[i]
+	}
+
+	rows, err := s.conn.QueryContext(ctx, `
+		SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT,
+		       COLUMN_KEY, EXTRA, COLUMN_COMMENT
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, ORDINAL_POSITION`, s.dbName)
+	if err != nil {
+		return fmt.Errorf("failed to read columns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName, nullable string
+		var column domain.ColumnInfo
+		var defaultValue, key, extra, comment sql.NullString
+		if err := rows.Scan(&tableName, &column.Name, &column.Type, &nullable, &defaultValue, &key, &extra, &comment); err != nil {
+			return fmt.Errorf("failed to scan column: %w", err)
+		}
+
+		column.Nullable = nullable == "YES"
+		if defaultValue.Valid {
+			column.Default = &defaultValue.String
+		}
+		column.Key = key.String
+		column.Extra = extra.String
+		column.Comment = comment.String
+
+		if table, ok := tables[tableName]; ok {
+			table.Columns = append(table.Columns, column)
+		} else if view, ok := views[tableName]; ok {
+			view.Columns = append(view.Columns, column)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read columns: %w", err)
+	}
+	return nil
+}
+
+// readIndexes reads indexes with their columns in index order
+func (s *Sandbox) readIndexes(ctx context.Context, tables map[string]*domain.TableInfo) error {
+	rows, err := s.conn.QueryContext(ctx, `
+		SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, INDEX_TYPE, COLUMN_NAME
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`, s.dbName)
+	if err != nil {
+		return fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName, indexName string
+		var nonUnique int
+		var indexType, columnName sql.NullString
+		if err := rows.Scan(&tableName, &indexName, &nonUnique, &indexType, &columnName); err != nil {
+			return fmt.Errorf("failed to scan index: %w", err)
+		}
+
+		table, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+
+		// Rows of one index are adjacent because of the ORDER BY
+		last := len(table.Indexes) - 1
+		if last < 0 || table.Indexes[last].Name != indexName {
+			table.Indexes = append(table.Indexes, domain.IndexInfo{
+				Name:    indexName,
+				Unique:  nonUnique == 0,
+				Type:    indexType.String,
+				Columns: []string{},
+			})
+			last++
+		}
+
+		// Functional key parts have no column name
+		if columnName.Valid {
+			table.Indexes[last].Columns = append(table.Indexes[last].Columns, columnName.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read indexes: %w", err)
+	}
+	return nil
+}
+
+// readForeignKeys reads foreign key constraints with their referential actions
+func (s *Sandbox) readForeignKeys(ctx context.Context, tables map[string]*domain.TableInfo) error {
+	rows, err := s.conn.QueryContext(ctx, `
+		SELECT k.TABLE_NAME, k.CONSTRAINT_NAME, k.COLUMN_NAME,
+		       k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME,
+		       r.UPDATE_RULE, r.DELETE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE k
+		LEFT JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+		  ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA
+		 AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+		WHERE k.TABLE_SCHEMA = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, s.dbName)
+	if err != nil {
+		return fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName, name, column, refTable, refColumn string
+		var onUpdate, onDelete sql.NullString
+		if err := rows.Scan(&tableName, &name, &column, &refTable, &refColumn, &onUpdate, &onDelete); err != nil {
+			return fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+
+		table, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+
+		last := len(table.ForeignKeys) - 1
+		if last < 0 || table.ForeignKeys[last].Name != name {
+			table.ForeignKeys = append(table.ForeignKeys, domain.ForeignKeyInfo{
+				Name:            name,
+				ReferencedTable: refTable,
+				OnUpdate:        onUpdate.String,
+				OnDelete:        onDelete.String,
+			})
+			last++
+		}
+
+		fk := &table.ForeignKeys[last]
+		fk.Columns = append(fk.Columns, column)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, refColumn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	return nil
+}
+
+// readRoutines reads stored procedures and functions
+func (s *Sandbox) readRoutines(ctx context.Context, schema *domain.Schema) error {
+	rows, err := s.conn.QueryContext(ctx, `
+		SELECT ROUTINE_NAME, ROUTINE_TYPE, DTD_IDENTIFIER
+		FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = ?
+		ORDER BY ROUTINE_NAME`, s.dbName)
+	if err != nil {
+		return fmt.Errorf("failed to read routines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var routine domain.RoutineInfo
+		var returnType sql.NullString
+		if err := rows.Scan(&routine.Name, &routine.Type, &returnType); err != nil {
+			return fmt.Errorf("failed to scan routine: %w", err)
+		}
+
+		routine.Type = strings.ToLower(routine.Type)
+		routine.ReturnType = returnType.String
+		schema.Routines = append(schema.Routines, routine)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read routines: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package executor
+
+import "strings"
+
+// sqlStatement is a single statement of a script
+type sqlStatement struct {
+	// text is the statement without its terminator
+	text string
+
+	// vertical is set when the statement was terminated by \G
+	vertical bool
+}
+
+// splitSQLStatements splits a script into statements on ';', '\g' and '\G'
+// terminators. Terminators inside quoted strings, identifiers and comments
+// are ignored. Statements consisting only of comments are dropped.
+//
+// Like the mysql client, a "DELIMITER xx" line at the start of a statement
+// replaces ';' with xx, so that routine bodies can contain semicolons.
+func splitSQLStatements(query string) []sqlStatement {
+	var statements []sqlStatement
+
+	start := 0
+	hasCode := false
+	delimiter := ";"
+
+	flush := func(end int, vertical bool) {
+		if hasCode {
+			statements = append(statements, sqlStatement{
+				text:     strings.TrimSpace(query[start:end]),
+				vertical: vertical,
+			})
+		}
+		hasCode = false
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i)
+			hasCode = true
+
+		case c == '#' || isDashComment(query, i):
+			i = skipLineComment(query, i)
+
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			// Executable comments (/*! ... */) carry code
+			if strings.HasPrefix(query[i:], "/*!") || strings.HasPrefix(query[i:], "/*+") {
+				hasCode = true
+			}
+			i = skipBlockComment(query, i)
+
+		case strings.HasPrefix(query[i:], delimiter):
+			flush(i, false)
+			i += len(delimiter) - 1
+			start = i + 1
+
+		case c == '\\' && i+1 < len(query) && (query[i+1] == 'g' || query[i+1] == 'G'):
+			flush(i, query[i+1] == 'G')
+			i++
+			start = i + 1
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			// Whitespace
+
+		case !hasCode && isDelimiterCommand(query, i):
+			// The command is not sent to the server
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			if fields := strings.Fields(query[i : i+end]); len(fields) > 1 {
+				delimiter = fields[1]
+			}
+			i += end
+			start = i + 1
+
+		default:
+			hasCode = true
+		}
+	}
+
+	flush(len(query), false)
+
+	return statements
+}
+
+// isDelimiterCommand reports whether a DELIMITER client command starts at i
+func isDelimiterCommand(query string, i int) bool {
+	const keyword = "delimiter"
+	if len(query)-i <= len(keyword) || !strings.EqualFold(query[i:i+len(keyword)], keyword) {
+		return false
+	}
+	return strings.IndexByte(" \t", query[i+len(keyword)]) >= 0
+}
+
+// skipQuoted returns the index of the closing quote of the string or
+// identifier starting at i. Doubled quotes and backslash escapes are honoured.
+func skipQuoted(query string, i int) int {
+	quote := query[i]
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case quote:
+			if j+1 < len(query) && query[j+1] == quote {
+				j++
+				continue
+			}
+			return j
+		}
+	}
+	return len(query) - 1
+}
+
+// isDashComment reports whether a "-- " comment starts at i. MySQL requires
+// the dashes to be followed by whitespace or the end of input.
+func isDashComment(query string, i int) bool {
+	if !strings.HasPrefix(query[i:], "--") {
+		return false
+	}
+	return i+2 == len(query) || strings.IndexByte(" \t\r\n", query[i+2]) >= 0
+}
+
+// skipLineComment returns the index of the end of a -- or # comment
+func skipLineComment(query string, i int) int {
+	if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+		return i + end
+	}
+	return len(query) - 1
+}
+
+// skipBlockComment returns the index of the closing slash of a /* */ comment
+func skipBlockComment(query string, i int) int {
+	if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+		return i + 2 + end + 1
+	}
+	return len(query) - 1
+}
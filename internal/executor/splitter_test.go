@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"testing"
+)
+
+func TestSplitSQLStatements(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected []string
+	}{
+		{"SELECT 1; SELECT 2;", []string{"SELECT 1", "SELECT 2"}},
+		{"SELECT 1", []string{"SELECT 1"}},
+		{"INSERT INTO t VALUES ('a;b'); SELECT 1", []string{"INSERT INTO t VALUES ('a;b')", "SELECT 1"}},
+		{`SELECT "x;y", 'it''s;', 'esc\';'`, []string{`SELECT "x;y", 'it''s;', 'esc\';'`}},
+		{"SELECT `weird;name` FROM t", []string{"SELECT `weird;name` FROM t"}},
+		{"SELECT 1; -- comment; with semicolon\nSELECT 2", []string{"SELECT 1", "-- comment; with semicolon\nSELECT 2"}},
+		{"SELECT 1 /* ; */; # only a comment;\n", []string{"SELECT 1 /* ; */"}},
+		{"SELECT 5--1;", []string{"SELECT 5--1"}},
+		{";;  ;", nil},
+	}
+
+	for _, c := range cases {
+		statements := splitSQLStatements(c.query)
+		if len(statements) != len(c.expected) {
+			t.Errorf("Query %q: expected %d statements, got %d: %+v", c.query, len(c.expected), len(statements), statements)
+			continue
+		}
+		for i, stmt := range statements {
+			if stmt.text != c.expected[i] {
+				t.Errorf("Query %q: statement %d = %q, expected %q", c.query, i+1, stmt.text, c.expected[i])
+			}
+		}
+	}
+}
+
+func TestSplitSQLStatements_Vertical(t *testing.T) {
+	statements := splitSQLStatements("SELECT * FROM t\\G SELECT 'a\\G'\\g SELECT 2")
+
+	if len(statements) != 3 {
+		t.Fatalf("Expected 3 statements, got %d: %+v", len(statements), statements)
+	}
+	if !statements[0].vertical || statements[0].text != "SELECT * FROM t" {
+		t.Errorf("Expected first statement to be vertical, got %+v", statements[0])
+	}
+	if statements[1].vertical || statements[1].text != "SELECT 'a\\G'" {
+		t.Errorf("Expected \\g to terminate without vertical mode, got %+v", statements[1])
+	}
+	if statements[2].vertical {
+		t.Errorf("Expected last statement not to be vertical")
+	}
+}
+
+func TestSplitSQLStatements_Delimiter(t *testing.T) {
+	query := `CREATE TABLE t (id INT);
+DELIMITER //
+CREATE PROCEDURE p(OUT total INT)
+BEGIN
+  SELECT COUNT(*) INTO total FROM t;
+  SELECT 'done;';
+END //
+delimiter ;
+CALL p(@total); SELECT @total;
+DELIMITER $$
+CREATE FUNCTION f() RETURNS INT DETERMINISTIC RETURN 1$$`
+
+	expected := []string{
+		"CREATE TABLE t (id INT)",
+		"CREATE PROCEDURE p(OUT total INT)\nBEGIN\n  SELECT COUNT(*) INTO total FROM t;\n  SELECT 'done;';\nEND",
+		"CALL p(@total)",
+		"SELECT @total",
+		"CREATE FUNCTION f() RETURNS INT DETERMINISTIC RETURN 1",
+	}
+
+	statements := splitSQLStatements(query)
+	if len(statements) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d: %+v", len(expected), len(statements), statements)
+	}
+	for i, stmt := range statements {
+		if stmt.text != expected[i] {
+			t.Errorf("Statement %d = %q, expected %q", i+1, stmt.text, expected[i])
+		}
+	}
+}
+
+func TestSplitSQLStatements_DelimiterInsideStatement(t *testing.T) {
+	// DELIMITER is only a command at the start of a statement
+	statements := splitSQLStatements("SELECT delimiter FROM t; SELECT 1")
+	if len(statements) != 2 || statements[0].text != "SELECT delimiter FROM t" {
+		t.Errorf("Unexpected statements: %+v", statements)
+	}
+}
@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/formatter"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteProvider creates embedded in-memory SQLite sandboxes. It needs no
+// database server.
+type SQLiteProvider struct{}
+
+// NewSQLiteProvider creates a SQLite sandbox provider
+func NewSQLiteProvider() *SQLiteProvider {
+	return &SQLiteProvider{}
+}
+
+// Engine returns the engine name
+func (p *SQLiteProvider) Engine() string {
+	return domain.EngineSQLite
+}
+
+// Create opens a new private in-memory database
+func (p *SQLiteProvider) Create(ctx context.Context) (EngineSandbox, error) {
+	// Every connection to ":memory:" gets its own database, so the pool is
+	// limited to the one pinned connection
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	// Enforce foreign keys like the other engines do
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = ON"); err != nil {
+		conn.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to configure SQLite database: %w", err)
+	}
+
+	return &sqliteSandbox{
+		name: "sqlite_" + generateShortUUID(),
+		db:   db,
+		conn: conn,
+	}, nil
+}
+
+// Ping always succeeds because SQLite is embedded
+func (p *SQLiteProvider) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close does nothing; every sandbox owns its database
+func (p *SQLiteProvider) Close() error {
+	return nil
+}
+
+// sqliteSandbox is an in-memory SQLite database
+type sqliteSandbox struct {
+	name string
+	db   *sql.DB
+	conn *sql.Conn
+
+	// totalChanges and lastInsertID are the values of total_changes() and
+	// last_insert_rowid() after the previous statement
+	totalChanges int64
+	lastInsertID int64
+}
+
+// Name returns the sandbox name
+func (s *sqliteSandbox) Name() string {
+	return s.name
+}
+
+// Run executes a single statement. Affected rows are derived from
+// total_changes(), because changes() keeps its value across statements that
+// do not modify rows.
+func (s *sqliteSandbox) Run(ctx context.Context, stmt string, opts formatter.ValueOptions) (*StatementOutcome, error) {
+	rows, err := s.conn.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	resultSets, err := readResultSets(rows, opts)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var totalChanges, lastInsertID int64
+	err = s.conn.QueryRowContext(ctx, "SELECT total_changes(), last_insert_rowid()").Scan(&totalChanges, &lastInsertID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statement status: %w", err)
+	}
+
+	outcome := &StatementOutcome{
+		ResultSets:   resultSets,
+		RowsAffected: totalChanges - s.totalChanges,
+	}
+	if lastInsertID != s.lastInsertID {
+		outcome.LastInsertID = lastInsertID
+	}
+
+	s.totalChanges = totalChanges
+	s.lastInsertID = lastInsertID
+	return outcome, nil
+}
+
+// Drop closes the database, which discards it
+func (s *sqliteSandbox) Drop(ctx context.Context) error {
+	s.conn.Close()
+	return s.db.Close()
+}
@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/formatter"
+)
+
+func TestSQLiteExecutor(t *testing.T) {
+	exec := NewProviderExecutor(NewSQLiteProvider(), 5*time.Second, formatter.ValueOptions{})
+
+	query := `CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+INSERT INTO users (name) VALUES ('Ann'), ('Bob');
+UPDATE users SET name = upper(name) WHERE id = 2;
+WITH u AS (SELECT id, name FROM users) SELECT * FROM u ORDER BY id;
+CREATE INDEX idx_users_name ON users (name);`
+
+	resp, err := exec.Execute(context.Background(), &domain.ExecuteRequest{Query: query, Engine: domain.EngineSQLite})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("Execute() failed: %s", resp.Error)
+	}
+	if resp.Engine != domain.EngineSQLite {
+		t.Errorf("Engine = %q, want %q", resp.Engine, domain.EngineSQLite)
+	}
+
+	want := []struct {
+		rowsAffected int64
+		lastInsertID int64
+		rowsReturned int64
+	}{
+		{0, 0, 0},
+		{2, 2, 0},
+		{1, 0, 0},
+		{0, 0, 2},
+		{0, 0, 0},
+	}
+	if len(resp.Statements) != len(want) {
+		t.Fatalf("got %d statements, want %d", len(resp.Statements), len(want))
+	}
+	for i, w := range want {
+		got := resp.Statements[i]
+		if got.RowsAffected != w.rowsAffected || got.LastInsertID != w.lastInsertID || got.RowsReturned != w.rowsReturned {
+			t.Errorf("statement %d = affected %d, insert id %d, returned %d; want %+v",
+				i+1, got.RowsAffected, got.LastInsertID, got.RowsReturned, w)
+		}
+	}
+
+	if !strings.Contains(resp.Output, "| BOB  |") {
+		t.Errorf("output does not contain the updated row:\n%s", resp.Output)
+	}
+}
+
+func TestSQLiteExecutor_Isolation(t *testing.T) {
+	exec := NewProviderExecutor(NewSQLiteProvider(), 5*time.Second, formatter.ValueOptions{})
+	ctx := context.Background()
+
+	resp, _ := exec.Execute(ctx, &domain.ExecuteRequest{Query: "CREATE TABLE t (id INTEGER)"})
+	if !resp.Success {
+		t.Fatalf("Execute() failed: %s", resp.Error)
+	}
+
+	// Every execution gets a fresh database
+	resp, _ = exec.Execute(ctx, &domain.ExecuteRequest{Query: "SELECT * FROM t"})
+	if resp.Success {
+		t.Errorf("expected table from a previous sandbox to be gone")
+	}
+}
+
+func TestSQLiteExecutor_Transactional(t *testing.T) {
+	exec := NewProviderExecutor(NewSQLiteProvider(), 5*time.Second, formatter.ValueOptions{})
+
+	resp, _ := exec.Execute(context.Background(), &domain.ExecuteRequest{
+		Query:         "CREATE TABLE t (id INTEGER)",
+		Transactional: true,
+	})
+	if resp.Success {
+		t.Errorf("expected DDL to be rejected in transactional mode")
+	}
+
+	resp, _ = exec.Execute(context.Background(), &domain.ExecuteRequest{
+		Query:         "CREATE TEMPORARY TABLE t (id INTEGER); INSERT INTO t VALUES (1); SELECT COUNT(*) FROM t",
+		Transactional: true,
+	})
+	if !resp.Success {
+		t.Fatalf("Execute() failed: %s", resp.Error)
+	}
+	if resp.Transaction == nil || !resp.Transaction.RolledBack {
+		t.Errorf("Transaction = %+v, want rolled back", resp.Transaction)
+	}
+}
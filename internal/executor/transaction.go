@@ -0,0 +1,233 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// rollbackTimeout bounds an automatic rollback issued after the execution
+// context may already have expired
+const rollbackTimeout = 5 * time.Second
+
+// txEffect is the effect of a statement on the transaction state
+type txEffect int
+
+const (
+	// txNone does not start or end a transaction by itself
+	txNone txEffect = iota
+
+	// txBegin starts a transaction: START TRANSACTION, BEGIN, COMMIT AND CHAIN
+	txBegin
+
+	// txEnd ends the transaction: COMMIT, ROLLBACK
+	txEnd
+
+	// txImplicitCommit commits the transaction implicitly: DDL, LOCK TABLES, ...
+	txImplicitCommit
+
+	// txSet may change the autocommit variable
+	txSet
+)
+
+// implicitCommitKeywords are the first keywords of statements that cause an
+// implicit commit
+var implicitCommitKeywords = map[string]bool{
+	"ALTER":     true,
+	"CREATE":    true,
+	"DROP":      true,
+	"RENAME":    true,
+	"TRUNCATE":  true,
+	"GRANT":     true,
+	"REVOKE":    true,
+	"LOCK":      true,
+	"UNLOCK":    true,
+	"ANALYZE":   true,
+	"CHECK":     true,
+	"OPTIMIZE":  true,
+	"REPAIR":    true,
+	"FLUSH":     true,
+	"RESET":     true,
+	"CACHE":     true,
+	"INSTALL":   true,
+	"UNINSTALL": true,
+}
+
+// classifyTransaction determines how a statement affects the transaction
+func classifyTransaction(stmt string) txEffect {
+	words := leadingKeywords(stmt, 4)
+	if len(words) == 0 {
+		return txNone
+	}
+
+	switch words[0] {
+	case "BEGIN":
+		return txBegin
+	case "START":
+		if len(words) > 1 && words[1] == "TRANSACTION" {
+			return txBegin
+		}
+	case "COMMIT", "ROLLBACK":
+		// ROLLBACK TO SAVEPOINT keeps the transaction open
+		if words[0] == "ROLLBACK" && len(words) > 1 && words[1] == "TO" {
+			return txNone
+		}
+		if hasChain(words) {
+			return txBegin
+		}
+		return txEnd
+	case "SET":
+		return txSet
+	case "CREATE", "DROP":
+		// Temporary tables do not commit
+		if len(words) > 1 && words[1] == "TEMPORARY" {
+			return txNone
+		}
+		return txImplicitCommit
+	}
+
+	if implicitCommitKeywords[words[0]] {
+		return txImplicitCommit
+	}
+	return txNone
+}
+
+// hasChain reports whether COMMIT/ROLLBACK keywords request AND CHAIN
+func hasChain(words []string) bool {
+	for i := 1; i+1 < len(words); i++ {
+		if words[i] == "AND" {
+			return words[i+1] == "CHAIN"
+		}
+	}
+	return false
+}
+
+// leadingKeywords returns up to n upper-cased leading words of a statement,
+// skipping whitespace and comments
+func leadingKeywords(stmt string, n int) []string {
+	var words []string
+
+	for i := 0; i < len(stmt) && len(words) < n; {
+		c := stmt[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || isDashComment(stmt, i):
+			i = skipLineComment(stmt, i) + 1
+		case c == '/' && i+1 < len(stmt) && stmt[i+1] == '*':
+			i = skipBlockComment(stmt, i) + 1
+		case isWordChar(c):
+			start := i
+			for i < len(stmt) && isWordChar(stmt[i]) {
+				i++
+			}
+			words = append(words, strings.ToUpper(stmt[start:i]))
+		default:
+			return words
+		}
+	}
+
+	return words
+}
+
+// isWordChar reports whether c can be part of an unquoted keyword
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// checkTransactional rejects statements that would end the transaction of a
+// transactional execution
+func checkTransactional(statements []sqlStatement) error {
+	for i, stmt := range statements {
+		effect := classifyTransaction(stmt.text)
+		if effect == txBegin || effect == txEnd || effect == txImplicitCommit ||
+			(effect == txSet && strings.Contains(strings.ToUpper(stmt.text), "AUTOCOMMIT")) {
+			return domain.Errorf(domain.CodeValidation, "statement %d would end the transaction and is not allowed in transactional mode", i+1)
+		}
+	}
+	return nil
+}
+
+// Transaction returns the current transaction state of the sandbox
+func (s *Sandbox) Transaction() domain.TransactionState {
+	return s.tx
+}
+
+// readAutocommit reads the autocommit variable of the pinned connection
+func (s *Sandbox) readAutocommit(ctx context.Context) error {
+	if err := s.conn.QueryRowContext(ctx, "SELECT @@autocommit").Scan(&s.tx.Autocommit); err != nil {
+		return fmt.Errorf("failed to read autocommit: %w", err)
+	}
+	return nil
+}
+
+// trackTransaction updates the transaction state after a successful statement
+func (s *Sandbox) trackTransaction(ctx context.Context, stmt string) error {
+	switch classifyTransaction(stmt) {
+	case txBegin:
+		s.tx.InTransaction = true
+		return nil
+	case txEnd, txImplicitCommit:
+		s.tx.InTransaction = false
+		return nil
+	case txSet:
+		wasAutocommit := s.tx.Autocommit
+		if err := s.readAutocommit(ctx); err != nil {
+			return err
+		}
+		// Enabling autocommit commits the open transaction
+		if s.tx.Autocommit && !wasAutocommit {
+			s.tx.InTransaction = false
+		}
+		return nil
+	}
+
+	// Without autocommit every other statement opens a transaction
+	if !s.tx.Autocommit {
+		s.tx.InTransaction = true
+	}
+	return nil
+}
+
+// begin starts a transaction for a transactional execution
+func (s *Sandbox) begin(ctx context.Context) error {
+	if s.tx.InTransaction {
+		return fmt.Errorf("a transaction is already open; commit or roll it back before a transactional execution")
+	}
+
+	if _, err := s.conn.ExecContext(ctx, "START TRANSACTION"); err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	s.tx.InTransaction = true
+	return nil
+}
+
+// rollback rolls back the open transaction, if any, and records why. It
+// uses its own deadline because the execution context may have expired.
+func (s *Sandbox) rollback(ctx context.Context, reason string) {
+	if !s.tx.InTransaction {
+		return
+	}
+
+	rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	// A failed rollback means the connection is gone, and the server rolls
+	// back the transaction of a closed connection itself
+	if _, err := s.conn.ExecContext(rollbackCtx, "ROLLBACK"); err != nil {
+		fmt.Printf("WARNING: Failed to roll back transaction in %s: %v\n", s.dbName, err)
+	}
+
+	s.tx.InTransaction = false
+	s.tx.RolledBack = true
+	s.tx.RollbackReason = reason
+}
+
+// resetRollback clears the automatic rollback report of a previous request
+func (s *Sandbox) resetRollback() {
+	s.tx.RolledBack = false
+	s.tx.RollbackReason = ""
+}
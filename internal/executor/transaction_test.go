@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyTransaction(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want txEffect
+	}{
+		{"START TRANSACTION", txBegin},
+		{"start transaction read only", txBegin},
+		{"BEGIN", txBegin},
+		{"BEGIN WORK", txBegin},
+		{"/* retry */ BEGIN", txBegin},
+		{"COMMIT", txEnd},
+		{"COMMIT WORK", txEnd},
+		{"ROLLBACK", txEnd},
+		{"ROLLBACK AND NO CHAIN", txEnd},
+		{"COMMIT AND CHAIN", txBegin},
+		{"ROLLBACK WORK AND CHAIN", txBegin},
+		{"ROLLBACK TO SAVEPOINT sp1", txNone},
+		{"ROLLBACK TO sp1", txNone},
+		{"SAVEPOINT sp1", txNone},
+		{"SET autocommit = 0", txSet},
+		{"CREATE TABLE t (id INT)", txImplicitCommit},
+		{"-- comment\nDROP TABLE t", txImplicitCommit},
+		{"CREATE TEMPORARY TABLE t (id INT)", txNone},
+		{"DROP TEMPORARY TABLE t", txNone},
+		{"ALTER TABLE t ADD c INT", txImplicitCommit},
+		{"TRUNCATE TABLE t", txImplicitCommit},
+		{"LOCK TABLES t WRITE", txImplicitCommit},
+		{"INSERT INTO t VALUES (1)", txNone},
+		{"SELECT 1", txNone},
+		{"START SLAVE", txNone},
+		{"", txNone},
+	}
+
+	for _, tt := range tests {
+		if got := classifyTransaction(tt.stmt); got != tt.want {
+			t.Errorf("classifyTransaction(%q) = %v, want %v", tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func TestLeadingKeywords(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want []string
+	}{
+		{"select * from t", []string{"SELECT"}},
+		{"  # note\n/* c */ Rollback  to  sp", []string{"ROLLBACK", "TO", "SP"}},
+		{"(SELECT 1)", nil},
+	}
+
+	for _, tt := range tests {
+		if got := leadingKeywords(tt.stmt, 3); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("leadingKeywords(%q) = %v, want %v", tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func TestCheckTransactional(t *testing.T) {
+	allowed := "INSERT INTO t VALUES (1); SAVEPOINT a; UPDATE t SET v = 2; ROLLBACK TO a; SET @x = 1;"
+	if err := checkTransactional(splitSQLStatements(allowed)); err != nil {
+		t.Errorf("checkTransactional(%q) error = %v", allowed, err)
+	}
+
+	rejected := []string{
+		"INSERT INTO t VALUES (1); COMMIT;",
+		"DELETE FROM t; CREATE TABLE u (id INT);",
+		"START TRANSACTION;",
+		"SET autocommit = 1;",
+	}
+	for _, query := range rejected {
+		if err := checkTransactional(splitSQLStatements(query)); err == nil {
+			t.Errorf("checkTransactional(%q) expected error", query)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Issue flags raised for plan nodes
+const (
+	IssueFullTableScan = "full table scan"
+	IssueFullIndexScan = "full index scan"
+	IssueFilesort      = "using filesort"
+	IssueTemporary     = "using temporary table"
+)
+
+// Node is one operation of a query plan
+type Node struct {
+	// Operation is the plan operation, e.g. "query_block", "table",
+	// "nested_loop", "ordering_operation"
+	Operation string `json:"operation"`
+
+	// SelectID is the id of a query block
+	SelectID int `json:"select_id,omitempty"`
+
+	// Table is the table name of a table access
+	Table string `json:"table,omitempty"`
+
+	// AccessType is the join type (ALL, index, range, ref, eq_ref, const, ...)
+	AccessType string `json:"access_type,omitempty"`
+
+	// PossibleKeys lists the indexes the optimizer considered
+	PossibleKeys []string `json:"possible_keys,omitempty"`
+
+	// Key is the index actually used
+	Key string `json:"key,omitempty"`
+
+	// RowsExamined is the estimated number of rows examined per scan
+	RowsExamined int64 `json:"rows_examined,omitempty"`
+
+	// RowsProduced is the estimated number of rows produced per join
+	RowsProduced int64 `json:"rows_produced,omitempty"`
+
+	// Filtered is the estimated percentage of rows left by the condition
+	Filtered float64 `json:"filtered,omitempty"`
+
+	// Cost is the query cost of a query block or the prefix cost of a table
+	Cost float64 `json:"cost,omitempty"`
+
+	// Condition is the attached condition of a table access
+	Condition string `json:"condition,omitempty"`
+
+	// Message is an optimizer message such as "No tables used"
+	Message string `json:"message,omitempty"`
+
+	// Issues lists performance problems found at this node
+	Issues []string `json:"issues,omitempty"`
+
+	// Children are the nested operations
+	Children []*Node `json:"children,omitempty"`
+}
+
+// operationKeys are wrapper operations that nest other operations
+var operationKeys = []string{
+	"ordering_operation",
+	"grouping_operation",
+	"duplicates_removal",
+	"windowing",
+	"buffer_result",
+}
+
+// subqueryKeys hold arrays of subqueries attached to a query block or table
+var subqueryKeys = []string{
+	"attached_subqueries",
+	"optimized_away_subqueries",
+	"select_list_subqueries",
+	"having_subqueries",
+	"order_by_subqueries",
+	"group_by_subqueries",
+	"update_value_subqueries",
+}
+
+// Parse parses the output of EXPLAIN FORMAT=JSON into a plan tree
+func Parse(data []byte) (*Node, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse EXPLAIN output: %w", err)
+	}
+
+	block, ok := doc["query_block"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("EXPLAIN output has no query_block")
+	}
+
+	return parseQueryBlock(block), nil
+}
+
+// parseQueryBlock converts a query_block object
+func parseQueryBlock(obj map[string]interface{}) *Node {
+	node := &Node{
+		Operation: "query_block",
+		SelectID:  int(number(obj["select_id"])),
+		Message:   str(obj["message"]),
+	}
+	if cost, ok := obj["cost_info"].(map[string]interface{}); ok {
+		node.Cost = number(cost["query_cost"])
+	}
+
+	node.Children = parseChildren(obj)
+	return node
+}
+
+// parseChildren collects the nested operations of an object
+func parseChildren(obj map[string]interface{}) []*Node {
+	var children []*Node
+
+	if table, ok := obj["table"].(map[string]interface{}); ok {
+		children = append(children, parseTable(table))
+	}
+
+	if loop, ok := obj["nested_loop"].([]interface{}); ok {
+		node := &Node{Operation: "nested_loop"}
+		for _, item := range loop {
+			if entry, ok := item.(map[string]interface{}); ok {
+				node.Children = append(node.Children, parseChildren(entry)...)
+			}
+		}
+		children = append(children, node)
+	}
+
+	for _, key := range operationKeys {
+		if op, ok := obj[key].(map[string]interface{}); ok {
+			children = append(children, parseOperation(key, op))
+		}
+	}
+
+	if union, ok := obj["union_result"].(map[string]interface{}); ok {
+		children = append(children, parseUnion(union))
+	}
+
+	if block, ok := obj["query_block"].(map[string]interface{}); ok {
+		children = append(children, parseQueryBlock(block))
+	}
+
+	for _, key := range subqueryKeys {
+		if subqueries, ok := obj[key].([]interface{}); ok {
+			for _, item := range subqueries {
+				if entry, ok := item.(map[string]interface{}); ok {
+					children = append(children, parseChildren(entry)...)
+				}
+			}
+		}
+	}
+
+	return children
+}
+
+// parseTable converts a table access object
+func parseTable(obj map[string]interface{}) *Node {
+	node := &Node{
+		Operation:    "table",
+		Table:        str(obj["table_name"]),
+		AccessType:   str(obj["access_type"]),
+		Key:          str(obj["key"]),
+		PossibleKeys: stringList(obj["possible_keys"]),
+		RowsExamined: int64(number(obj["rows_examined_per_scan"])),
+		RowsProduced: int64(number(obj["rows_produced_per_join"])),
+		Filtered:     number(obj["filtered"]),
+		Condition:    str(obj["attached_condition"]),
+		Message:      str(obj["message"]),
+	}
+	if cost, ok := obj["cost_info"].(map[string]interface{}); ok {
+		node.Cost = number(cost["prefix_cost"])
+	}
+
+	switch node.AccessType {
+	case "ALL":
+		node.Issues = append(node.Issues, IssueFullTableScan)
+	case "index":
+		node.Issues = append(node.Issues, IssueFullIndexScan)
+	}
+	if flag(obj["using_filesort"]) {
+		node.Issues = append(node.Issues, IssueFilesort)
+	}
+	if flag(obj["using_temporary_table"]) {
+		node.Issues = append(node.Issues, IssueTemporary)
+	}
+
+	if derived, ok := obj["materialized_from_subquery"].(map[string]interface{}); ok {
+		node.Children = append(node.Children, parseChildren(derived)...)
+	}
+	for _, key := range subqueryKeys {
+		if subqueries, ok := obj[key].([]interface{}); ok {
+			for _, item := range subqueries {
+				if entry, ok := item.(map[string]interface{}); ok {
+					node.Children = append(node.Children, parseChildren(entry)...)
+				}
+			}
+		}
+	}
+
+	return node
+}
+
+// parseOperation converts ordering, grouping and similar wrapper operations
+func parseOperation(name string, obj map[string]interface{}) *Node {
+	node := &Node{Operation: name}
+
+	if flag(obj["using_filesort"]) {
+		node.Issues = append(node.Issues, IssueFilesort)
+	}
+	if flag(obj["using_temporary_table"]) {
+		node.Issues = append(node.Issues, IssueTemporary)
+	}
+
+	node.Children = parseChildren(obj)
+	return node
+}
+
+// parseUnion converts a union_result object
+func parseUnion(obj map[string]interface{}) *Node {
+	node := &Node{
+		Operation:  "union_result",
+		Table:      str(obj["table_name"]),
+		AccessType: str(obj["access_type"]),
+	}
+	if flag(obj["using_temporary_table"]) {
+		node.Issues = append(node.Issues, IssueTemporary)
+	}
+
+	if specs, ok := obj["query_specifications"].([]interface{}); ok {
+		for _, item := range specs {
+			if entry, ok := item.(map[string]interface{}); ok {
+				node.Children = append(node.Children, parseChildren(entry)...)
+			}
+		}
+	}
+
+	return node
+}
+
+// AllIssues returns the distinct issues found anywhere in the plan
+func (n *Node) AllIssues() []string {
+	seen := make(map[string]bool)
+	var walk func(*Node)
+	walk = func(node *Node) {
+		for _, issue := range node.Issues {
+			seen[issue] = true
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(n)
+
+	issues := make([]string, 0, len(seen))
+	for issue := range seen {
+		issues = append(issues, issue)
+	}
+	sort.Strings(issues)
+	return issues
+}
+
+// str returns a JSON string value or ""
+func str(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// stringList returns a JSON array of strings
+func stringList(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// number returns a JSON number or numeric string ("1.20") as float64
+func number(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+// flag returns a JSON boolean value
+func flag(v interface{}) bool {
+	b, _ := v.(bool)
+	return b
+}
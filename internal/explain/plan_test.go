@@ -0,0 +1,133 @@
+package explain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const samplePlan = `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "12.50"},
+    "ordering_operation": {
+      "using_filesort": true,
+      "nested_loop": [
+        {
+          "table": {
+            "table_name": "orders",
+            "access_type": "ALL",
+            "rows_examined_per_scan": 100,
+            "rows_produced_per_join": 10,
+            "filtered": "10.00",
+            "cost_info": {"prefix_cost": "10.25"},
+            "attached_condition": "(orders.status = 'new')"
+          }
+        },
+        {
+          "table": {
+            "table_name": "customers",
+            "access_type": "eq_ref",
+            "possible_keys": ["PRIMARY"],
+            "key": "PRIMARY",
+            "rows_examined_per_scan": 1,
+            "rows_produced_per_join": 10,
+            "filtered": "100.00",
+            "cost_info": {"prefix_cost": "12.50"}
+          }
+        }
+      ]
+    }
+  }
+}`
+
+func TestParse(t *testing.T) {
+	root, err := Parse([]byte(samplePlan))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if root.Operation != "query_block" || root.SelectID != 1 || root.Cost != 12.5 {
+		t.Errorf("root = %+v, want query_block #1 with cost 12.5", root)
+	}
+
+	if len(root.Children) != 1 || root.Children[0].Operation != "ordering_operation" {
+		t.Fatalf("root children = %+v, want ordering_operation", root.Children)
+	}
+
+	ordering := root.Children[0]
+	if !reflect.DeepEqual(ordering.Issues, []string{IssueFilesort}) {
+		t.Errorf("ordering issues = %v, want [%s]", ordering.Issues, IssueFilesort)
+	}
+
+	if len(ordering.Children) != 1 || ordering.Children[0].Operation != "nested_loop" {
+		t.Fatalf("ordering children = %+v, want nested_loop", ordering.Children)
+	}
+
+	tables := ordering.Children[0].Children
+	if len(tables) != 2 {
+		t.Fatalf("nested_loop has %d children, want 2", len(tables))
+	}
+
+	orders := tables[0]
+	if orders.Table != "orders" || orders.AccessType != "ALL" || orders.RowsExamined != 100 || orders.Filtered != 10 {
+		t.Errorf("orders = %+v", orders)
+	}
+	if !reflect.DeepEqual(orders.Issues, []string{IssueFullTableScan}) {
+		t.Errorf("orders issues = %v, want [%s]", orders.Issues, IssueFullTableScan)
+	}
+
+	customers := tables[1]
+	if customers.Key != "PRIMARY" || len(customers.Issues) != 0 {
+		t.Errorf("customers = %+v", customers)
+	}
+
+	want := []string{IssueFullTableScan, IssueFilesort}
+	if got := root.AllIssues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("AllIssues() = %v, want %v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"not json",
+		`{"foo": 1}`,
+	}
+
+	for _, input := range inputs {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("Parse(%q) expected error", input)
+		}
+	}
+}
+
+func TestRenderTree(t *testing.T) {
+	root, err := Parse([]byte(samplePlan))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tree := RenderTree(root)
+	lines := strings.Split(strings.TrimRight(tree, "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("RenderTree() produced %d lines, want 5:\n%s", len(lines), tree)
+	}
+
+	prefixes := []string{
+		"query_block #1",
+		"└── ordering_operation",
+		"    └── nested_loop",
+		"        ├── table orders [ALL]",
+		"        └── table customers [eq_ref] key=PRIMARY",
+	}
+	for i, prefix := range prefixes {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+
+	if !strings.Contains(lines[3], "!! "+IssueFullTableScan) {
+		t.Errorf("line %q does not flag %q", lines[3], IssueFullTableScan)
+	}
+}
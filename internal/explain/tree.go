@@ -0,0 +1,81 @@
+package explain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RenderTree renders the plan as an ASCII tree for terminal clients
+func RenderTree(root *Node) string {
+	var out strings.Builder
+	out.WriteString(describe(root))
+	out.WriteString("\n")
+	renderChildren(&out, root.Children, "")
+	return out.String()
+}
+
+// renderChildren renders child nodes with box-drawing branches
+func renderChildren(out *strings.Builder, children []*Node, prefix string) {
+	for i, child := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		out.WriteString(prefix)
+		out.WriteString(branch)
+		out.WriteString(describe(child))
+		out.WriteString("\n")
+
+		renderChildren(out, child.Children, prefix+indent)
+	}
+}
+
+// describe formats a single node on one line
+func describe(n *Node) string {
+	parts := []string{n.Operation}
+
+	switch n.Operation {
+	case "query_block":
+		if n.SelectID > 0 {
+			parts[0] = fmt.Sprintf("query_block #%d", n.SelectID)
+		}
+	case "table":
+		parts[0] = "table " + n.Table
+	case "union_result":
+		if n.Table != "" {
+			parts = append(parts, n.Table)
+		}
+	}
+
+	if n.AccessType != "" {
+		parts = append(parts, "["+n.AccessType+"]")
+	}
+	if n.Key != "" {
+		parts = append(parts, "key="+n.Key)
+	}
+	if len(n.PossibleKeys) > 0 {
+		parts = append(parts, "possible_keys="+strings.Join(n.PossibleKeys, ","))
+	}
+	if n.RowsExamined > 0 {
+		parts = append(parts, "rows="+strconv.FormatInt(n.RowsExamined, 10))
+	}
+	if n.Filtered > 0 {
+		parts = append(parts, "filtered="+strconv.FormatFloat(n.Filtered, 'f', -1, 64)+"%")
+	}
+	if n.Cost > 0 {
+		parts = append(parts, "cost="+strconv.FormatFloat(n.Cost, 'f', 2, 64))
+	}
+	if n.Condition != "" {
+		parts = append(parts, "where "+n.Condition)
+	}
+	if n.Message != "" {
+		parts = append(parts, "("+n.Message+")")
+	}
+	if len(n.Issues) > 0 {
+		parts = append(parts, "!! "+strings.Join(n.Issues, ", "))
+	}
+
+	return strings.Join(parts, " ")
+}
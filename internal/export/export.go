@@ -0,0 +1,266 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/formatter"
+)
+
+// Format is an export file format
+type Format string
+
+// Supported export formats
+const (
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatTSV      Format = "tsv"
+	FormatNDJSON   Format = "ndjson"
+	FormatMarkdown Format = "markdown"
+	FormatXLSX     Format = "xlsx"
+)
+
+// formatInfo describes the MIME type and file extension of a format
+type formatInfo struct {
+	contentType string
+	extension   string
+}
+
+var formats = map[Format]formatInfo{
+	FormatJSON:     {"application/json", "json"},
+	FormatCSV:      {"text/csv; charset=utf-8; header=present", "csv"},
+	FormatTSV:      {"text/tab-separated-values; charset=utf-8", "tsv"},
+	FormatNDJSON:   {"application/x-ndjson", "ndjson"},
+	FormatMarkdown: {"text/markdown; charset=utf-8", "md"},
+	FormatXLSX:     {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+}
+
+// aliases maps alternative names and MIME types to formats
+var aliases = map[string]Format{
+	"jsonl":                     FormatNDJSON,
+	"md":                        FormatMarkdown,
+	"text/csv":                  FormatCSV,
+	"text/tab-separated-values": FormatTSV,
+	"application/x-ndjson":      FormatNDJSON,
+	"application/jsonl":         FormatNDJSON,
+	"text/markdown":             FormatMarkdown,
+	"application/json":          FormatJSON,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": FormatXLSX,
+}
+
+// ParseFormat parses a format name, extension or MIME type
+func ParseFormat(name string) (Format, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, ok := formats[Format(name)]; ok {
+		return Format(name), nil
+	}
+	if format, ok := aliases[name]; ok {
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported export format %q (expected csv, tsv, ndjson, markdown or xlsx)", name)
+}
+
+// Negotiate selects a format from an explicit format parameter or, when it
+// is empty, from the Accept header. JSON is returned when nothing else matches.
+func Negotiate(param string, accept string) (Format, error) {
+	if param != "" {
+		return ParseFormat(param)
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if format, ok := aliases[mediaType]; ok {
+			return format, nil
+		}
+	}
+
+	return FormatJSON, nil
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	return formats[f].contentType
+}
+
+// Extension returns the file extension of the format without a dot
+func (f Format) Extension() string {
+	return formats[f].extension
+}
+
+// Sheet is a named result set, written as one worksheet in workbook formats
+type Sheet struct {
+	Name string
+	Rows domain.RowIterator
+}
+
+// Write writes result sets in the given format. Row formats (CSV, TSV,
+// NDJSON, Markdown) contain a single result set and use the first sheet;
+// XLSX writes every sheet.
+func Write(w io.Writer, format Format, sheets []Sheet) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("nothing to export")
+	}
+
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, sheets[0].Rows)
+	case FormatTSV:
+		return writeTSV(w, sheets[0].Rows)
+	case FormatNDJSON:
+		return writeNDJSON(w, sheets[0].Rows)
+	case FormatMarkdown:
+		return writeMarkdown(w, sheets[0].Rows)
+	case FormatXLSX:
+		return writeXLSX(w, sheets)
+	default:
+		return fmt.Errorf("format %q cannot be exported", format)
+	}
+}
+
+// writeCSV writes RFC 4180 CSV with a header line. NULL becomes an empty field.
+func writeCSV(w io.Writer, it domain.RowIterator) error {
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+
+	columns := it.Columns()
+	record := make([]string, len(columns))
+	for i, col := range columns {
+		record[i] = col.Name
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+
+	for it.Next() {
+		for i, val := range it.Row() {
+			record[i] = val.Text
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return it.Err()
+}
+
+// tsvEscaper escapes characters that cannot appear in TSV fields
+var tsvEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\t", "\\t",
+	"\n", "\\n",
+	"\r", "\\r",
+)
+
+// writeTSV writes tab-separated values with a header line. Tabs, newlines
+// and backslashes are escaped and NULL is written as \N, as LOAD DATA expects.
+func writeTSV(w io.Writer, it domain.RowIterator) error {
+	columns := it.Columns()
+	fields := make([]string, len(columns))
+	for i, col := range columns {
+		fields[i] = tsvEscaper.Replace(col.Name)
+	}
+	if _, err := io.WriteString(w, strings.Join(fields, "\t")+"\n"); err != nil {
+		return err
+	}
+
+	for it.Next() {
+		for i, val := range it.Row() {
+			if val.Null {
+				fields[i] = `\N`
+			} else {
+				fields[i] = tsvEscaper.Replace(val.Text)
+			}
+		}
+		if _, err := io.WriteString(w, strings.Join(fields, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// writeNDJSON writes one JSON object per row, keeping the column order.
+// Numeric columns are written as JSON numbers, JSON columns are embedded
+// as documents and NULL as null.
+func writeNDJSON(w io.Writer, it domain.RowIterator) error {
+	columns := it.Columns()
+	keys := make([][]byte, len(columns))
+	for i, col := range columns {
+		key, err := json.Marshal(col.Name)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	var line []byte
+	for it.Next() {
+		line = append(line[:0], '{')
+		for i, val := range it.Row() {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			line = append(line, keys[i]...)
+			line = append(line, ':')
+			line = appendJSONValue(line, columns[i], val)
+		}
+		line = append(line, '}', '\n')
+
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// appendJSONValue appends the JSON encoding of a cell
+func appendJSONValue(buf []byte, col domain.Column, val domain.Value) []byte {
+	if val.Null {
+		return append(buf, "null"...)
+	}
+	if col.Numeric && isNumber(val.Text) {
+		return append(buf, val.Text...)
+	}
+	if col.Type == "JSON" && json.Valid([]byte(val.Text)) {
+		var compact bytes.Buffer
+		if json.Compact(&compact, []byte(val.Text)) == nil {
+			return append(buf, compact.Bytes()...)
+		}
+	}
+	encoded, _ := json.Marshal(val.Text)
+	return append(buf, encoded...)
+}
+
+// numberPattern matches decimal numbers valid in both JSON and SpreadsheetML
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// isNumber reports whether text can be written as a bare number
+func isNumber(text string) bool {
+	return numberPattern.MatchString(text)
+}
+
+// writeMarkdown writes a Markdown table
+func writeMarkdown(w io.Writer, it domain.RowIterator) error {
+	rs, err := domain.CollectRows(it)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, formatter.Render(formatter.ModeMarkdown, rs))
+	return err
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+func sampleSheet() []Sheet {
+	rs := &domain.ResultSet{
+		Columns: []domain.Column{
+			{Name: "id", Type: "INT", Numeric: true},
+			{Name: "name", Type: "VARCHAR"},
+			{Name: "price", Type: "DECIMAL", Numeric: true},
+		},
+		Rows: [][]domain.Value{
+			{{Text: "1"}, {Text: `a,"b"`}, {Text: "1.50"}},
+			{{Text: "2"}, {Text: "tab\there\nnext"}, {Null: true}},
+		},
+	}
+	return []Sheet{{Name: "Statement 1", Rows: rs.Iterator()}}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, sampleSheet()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	expected := "id,name,price\r\n1,\"a,\"\"b\"\"\",1.50\r\n2,\"tab\there\r\nnext\",\r\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV output: %q", buf.String())
+	}
+}
+
+func TestWrite_TSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatTSV, sampleSheet()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	expected := "id\tname\tprice\n1\ta,\"b\"\t1.50\n2\ttab\\there\\nnext\t\\N\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected TSV output: %q", buf.String())
+	}
+}
+
+func TestWrite_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatNDJSON, sampleSheet()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	expected := `{"id":1,"name":"a,\"b\"","price":1.50}` + "\n" +
+		`{"id":2,"name":"tab\there\nnext","price":null}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected NDJSON output: %q", buf.String())
+	}
+}
+
+func TestWrite_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	sheets := append(sampleSheet(), sampleSheet()...)
+	if err := Write(&buf, FormatXLSX, sheets); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Output is not a valid zip archive: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range archive.File {
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "xl/workbook.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected %s in workbook", name)
+		}
+	}
+
+	if !strings.Contains(files["xl/workbook.xml"], `name="Statement 1 (2)"`) {
+		t.Errorf("Expected duplicate sheet names to be made unique: %s", files["xl/workbook.xml"])
+	}
+	if !strings.Contains(files["xl/worksheets/sheet1.xml"], `<c r="C2"><v>1.50</v></c>`) {
+		t.Errorf("Expected numeric cell for DECIMAL column: %s", files["xl/worksheets/sheet1.xml"])
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		param    string
+		accept   string
+		expected Format
+	}{
+		{"", "", FormatJSON},
+		{"", "*/*", FormatJSON},
+		{"csv", "application/json", FormatCSV},
+		{"", "text/csv", FormatCSV},
+		{"", "application/x-ndjson; q=0.9, application/json", FormatNDJSON},
+		{"jsonl", "", FormatNDJSON},
+		{"md", "", FormatMarkdown},
+	}
+
+	for _, c := range cases {
+		format, err := Negotiate(c.param, c.accept)
+		if err != nil || format != c.expected {
+			t.Errorf("Negotiate(%q, %q) = %q, %v; expected %q", c.param, c.accept, format, err, c.expected)
+		}
+	}
+
+	if _, err := Negotiate("pdf", ""); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestColumnRef(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for index, expected := range cases {
+		if got := columnRef(index); got != expected {
+			t.Errorf("columnRef(%d) = %s, expected %s", index, got, expected)
+		}
+	}
+}
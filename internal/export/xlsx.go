@@ -0,0 +1,236 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// maxSheetNameLength is the Excel limit for worksheet names
+const maxSheetNameLength = 31
+
+// maxSheetRows is the Excel limit for rows per worksheet
+const maxSheetRows = 1048576
+
+// Static parts of a minimal SpreadsheetML package
+const (
+	xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+		`<borders count="1"><border/></borders>` +
+		`<cellStyleXfs count="1"><xf/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf/><xf fontId="1" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`
+)
+
+// writeXLSX writes an XLSX workbook with one worksheet per sheet. Strings are
+// stored inline so the workbook can be produced in a single pass.
+func writeXLSX(w io.Writer, sheets []Sheet) error {
+	archive := zip.NewWriter(w)
+
+	names := sheetNames(sheets)
+
+	if err := writeZipEntry(archive, "[Content_Types].xml", contentTypesXML(len(sheets))); err != nil {
+		return err
+	}
+	if err := writeZipEntry(archive, "_rels/.rels", xlsxRels); err != nil {
+		return err
+	}
+	if err := writeZipEntry(archive, "xl/workbook.xml", workbookXML(names)); err != nil {
+		return err
+	}
+	if err := writeZipEntry(archive, "xl/_rels/workbook.xml.rels", workbookRelsXML(len(sheets))); err != nil {
+		return err
+	}
+	if err := writeZipEntry(archive, "xl/styles.xml", xlsxStyles); err != nil {
+		return err
+	}
+
+	for i, sheet := range sheets {
+		entry, err := archive.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		if err := writeWorksheet(entry, sheet.Rows); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// writeZipEntry writes a complete file into the archive
+func writeZipEntry(archive *zip.Writer, name string, content string) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(entry, content)
+	return err
+}
+
+// writeWorksheet streams the rows of a result set as worksheet XML.
+// The header row is bold and numeric columns are stored as numbers.
+func writeWorksheet(w io.Writer, it domain.RowIterator) error {
+	buf := bufio.NewWriter(w)
+
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	columns := it.Columns()
+	refs := make([]string, len(columns))
+	for i := range columns {
+		refs[i] = columnRef(i)
+	}
+
+	buf.WriteString(`<row r="1">`)
+	for i, col := range columns {
+		writeStringCell(buf, refs[i]+"1", col.Name, 1)
+	}
+	buf.WriteString(`</row>`)
+
+	rowNum := 1
+	for it.Next() {
+		rowNum++
+		if rowNum > maxSheetRows {
+			return fmt.Errorf("result set exceeds the XLSX limit of %d rows", maxSheetRows)
+		}
+
+		rowRef := strconv.Itoa(rowNum)
+		buf.WriteString(`<row r="` + rowRef + `">`)
+		for i, val := range it.Row() {
+			switch {
+			case val.Null:
+				// Leave NULL cells empty
+			case columns[i].Numeric && isNumber(val.Text):
+				buf.WriteString(`<c r="` + refs[i] + rowRef + `"><v>` + val.Text + `</v></c>`)
+			default:
+				writeStringCell(buf, refs[i]+rowRef, val.Text, 0)
+			}
+		}
+		buf.WriteString(`</row>`)
+	}
+
+	buf.WriteString(`</sheetData></worksheet>`)
+
+	if err := it.Err(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// writeStringCell writes an inline string cell with the given style index
+func writeStringCell(buf *bufio.Writer, ref string, text string, style int) {
+	buf.WriteString(`<c r="` + ref + `" t="inlineStr"`)
+	if style > 0 {
+		buf.WriteString(` s="` + strconv.Itoa(style) + `"`)
+	}
+	buf.WriteString(`><is><t xml:space="preserve">`)
+	xml.EscapeText(buf, []byte(sanitizeXMLText(text)))
+	buf.WriteString(`</t></is></c>`)
+}
+
+// sanitizeXMLText drops characters that are not allowed in XML 1.0
+func sanitizeXMLText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r != 0xFFFE && r != 0xFFFF) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// columnRef converts a 0-based column index into an Excel column name
+func columnRef(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// sheetNames returns unique worksheet names that satisfy Excel's rules
+func sheetNames(sheets []Sheet) []string {
+	invalid := strings.NewReplacer("[", "", "]", "", ":", "", "*", "", "?", "", "/", "", "\\", "")
+
+	names := make([]string, len(sheets))
+	seen := make(map[string]bool)
+	for i, sheet := range sheets {
+		name := strings.TrimSpace(invalid.Replace(sheet.Name))
+		if name == "" {
+			name = fmt.Sprintf("Sheet%d", i+1)
+		}
+		if len([]rune(name)) > maxSheetNameLength {
+			name = string([]rune(name)[:maxSheetNameLength])
+		}
+
+		unique := name
+		for n := 2; seen[strings.ToLower(unique)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			base := []rune(name)
+			if len(base)+len(suffix) > maxSheetNameLength {
+				base = base[:maxSheetNameLength-len(suffix)]
+			}
+			unique = string(base) + suffix
+		}
+
+		seen[strings.ToLower(unique)] = true
+		names[i] = unique
+	}
+
+	return names
+}
+
+// contentTypesXML lists the parts of the package
+func contentTypesXML(sheetCount int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheetCount; i++ {
+		b.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i))
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+// workbookXML lists the worksheets of the workbook
+func workbookXML(names []string) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range names {
+		b.WriteString(`<sheet name="`)
+		xml.EscapeText(&b, []byte(name))
+		b.WriteString(fmt.Sprintf(`" sheetId="%d" r:id="rId%d"/>`, i+1, i+1))
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+// workbookRelsXML links the workbook to its worksheets and styles
+func workbookRelsXML(sheetCount int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheetCount; i++ {
+		b.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i))
+	}
+	b.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheetCount+1))
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package fixture
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// ErrNotFound is returned for unknown fixture names
+var ErrNotFound = domain.NewError(domain.CodeNotFound, "fixture not found")
+
+// Fixture is a named SQL script that prepares a sandbox for an exercise.
+//
+// Metadata is read from "-- @key: value" comment lines at the top of the
+// file, e.g.
+//
+//	-- @description: Shop schema with customers and orders
+type Fixture struct {
+	// Name is the file name without the .sql extension
+	Name string `json:"name"`
+
+	// Description is a short human-readable summary
+	Description string `json:"description,omitempty"`
+
+	// Metadata holds all header values by lower-cased key
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Script is the SQL executed in the sandbox
+	Script string `json:"-"`
+}
+
+// Store holds the fixtures loaded from a directory
+type Store struct {
+	mu       sync.RWMutex
+	fixtures map[string]*Fixture
+}
+
+// NewStore loads every *.sql file of dir. A missing directory yields an
+// empty store.
+func NewStore(dir string) (*Store, error) {
+	store := &Store{fixtures: make(map[string]*Fixture)}
+	if dir == "" {
+		return store, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), ".sql")
+		store.fixtures[name] = Parse(name, string(data))
+	}
+
+	return store, nil
+}
+
+// Parse creates a fixture from its script and header metadata
+func Parse(name string, script string) *Fixture {
+	fixture := &Fixture{
+		Name:     name,
+		Metadata: make(map[string]string),
+		Script:   script,
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// Metadata ends at the first line that is not a header comment
+		header, ok := strings.CutPrefix(line, "-- @")
+		if !ok {
+			break
+		}
+
+		key, value, ok := strings.Cut(header, ":")
+		if !ok {
+			continue
+		}
+		fixture.Metadata[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+
+	fixture.Description = fixture.Metadata["description"]
+	return fixture
+}
+
+// Get returns the fixture with the given name. A nil store has no fixtures.
+func (s *Store) Get(name string) (*Fixture, error) {
+	if s == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fixture, ok := s.fixtures[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return fixture, nil
+}
+
+// List returns all fixtures sorted by name
+func (s *Store) List() []*Fixture {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]*Fixture, 0, len(s.fixtures))
+	for _, fixture := range s.fixtures {
+		list = append(list, fixture)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
@@ -0,0 +1,72 @@
+package fixture
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	script := `-- @description: Shop schema
+-- @Backend: mysql-8.0
+
+-- regular comment
+-- @ignored: not a header
+CREATE TABLE customers (id INT PRIMARY KEY);
+`
+
+	fixture := Parse("shop", script)
+
+	if fixture.Name != "shop" {
+		t.Errorf("Name = %q, want %q", fixture.Name, "shop")
+	}
+	if fixture.Description != "Shop schema" {
+		t.Errorf("Description = %q, want %q", fixture.Description, "Shop schema")
+	}
+	if fixture.Metadata["backend"] != "mysql-8.0" {
+		t.Errorf("Metadata[backend] = %q, want %q", fixture.Metadata["backend"], "mysql-8.0")
+	}
+	if _, ok := fixture.Metadata["ignored"]; ok {
+		t.Errorf("header after the first non-header line should be ignored")
+	}
+	if fixture.Script != script {
+		t.Errorf("Script was modified")
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"shop.sql":   "-- @description: Shop\nCREATE TABLE t (id INT);\n",
+		"empty.sql":  "",
+		"readme.txt": "not a fixture",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	list := store.List()
+	if len(list) != 2 || list[0].Name != "empty" || list[1].Name != "shop" {
+		t.Fatalf("List() = %v, want [empty shop]", list)
+	}
+
+	if _, err := store.Get("shop"); err != nil {
+		t.Errorf("Get(shop) error = %v", err)
+	}
+	if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	missing, err := NewStore(filepath.Join(dir, "does-not-exist"))
+	if err != nil || len(missing.List()) != 0 {
+		t.Errorf("NewStore(missing dir) = %v, %v, want empty store", missing, err)
+	}
+}
@@ -0,0 +1,105 @@
+package formatter
+
+import (
+	"html"
+	"strings"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// batchEscaper escapes special characters like mysql --batch does
+var batchEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\t", "\\t",
+	"\n", "\\n",
+	"\x00", "\\0",
+)
+
+// markdownEscaper keeps cell contents from breaking the table layout
+var markdownEscaper = strings.NewReplacer(
+	"|", "\\|",
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+// renderBatch prints a header line and tab-separated rows (mysql -B)
+func renderBatch(rs *domain.ResultSet) string {
+	var output strings.Builder
+
+	cells := make([]string, len(rs.Columns))
+	for i, col := range rs.Columns {
+		cells[i] = batchEscaper.Replace(col.Name)
+	}
+	output.WriteString(strings.Join(cells, "\t"))
+	output.WriteString("\n")
+
+	for _, row := range rs.Rows {
+		for i, val := range row {
+			cells[i] = batchEscaper.Replace(val.String())
+		}
+		output.WriteString(strings.Join(cells, "\t"))
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
+
+// renderHTML prints an HTML table (mysql -H)
+func renderHTML(rs *domain.ResultSet) string {
+	var output strings.Builder
+
+	output.WriteString("<TABLE BORDER=1><TR>")
+	for _, col := range rs.Columns {
+		output.WriteString("<TH>")
+		output.WriteString(html.EscapeString(col.Name))
+		output.WriteString("</TH>")
+	}
+	output.WriteString("</TR>")
+
+	for _, row := range rs.Rows {
+		output.WriteString("<TR>")
+		for _, val := range row {
+			output.WriteString("<TD>")
+			output.WriteString(html.EscapeString(val.String()))
+			output.WriteString("</TD>")
+		}
+		output.WriteString("</TR>")
+	}
+	output.WriteString("</TABLE>\n")
+
+	return output.String()
+}
+
+// renderMarkdown prints a Markdown table with numeric columns right-aligned
+func renderMarkdown(rs *domain.ResultSet) string {
+	var output strings.Builder
+
+	output.WriteString("|")
+	for _, col := range rs.Columns {
+		output.WriteString(" ")
+		output.WriteString(markdownEscaper.Replace(col.Name))
+		output.WriteString(" |")
+	}
+	output.WriteString("\n|")
+
+	for _, col := range rs.Columns {
+		if col.Numeric {
+			output.WriteString(" ---: |")
+		} else {
+			output.WriteString(" --- |")
+		}
+	}
+	output.WriteString("\n")
+
+	for _, row := range rs.Rows {
+		output.WriteString("|")
+		for _, val := range row {
+			output.WriteString(" ")
+			output.WriteString(markdownEscaper.Replace(val.String()))
+			output.WriteString(" |")
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
@@ -0,0 +1,145 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// Mode selects how result sets are rendered
+type Mode string
+
+// Supported output modes, mirroring the mysql client options
+const (
+	// ModeTable draws ASCII tables (default interactive mysql output)
+	ModeTable Mode = "table"
+
+	// ModeVertical prints one "column: value" line per column (\G, mysql -E)
+	ModeVertical Mode = "vertical"
+
+	// ModeBatch prints tab-separated rows without borders or summaries (mysql -B)
+	ModeBatch Mode = "batch"
+
+	// ModeHTML prints HTML tables (mysql -H)
+	ModeHTML Mode = "html"
+
+	// ModeMarkdown prints GitHub-flavoured Markdown tables
+	ModeMarkdown Mode = "markdown"
+)
+
+// ParseMode parses an output mode name. An empty name selects table mode.
+func ParseMode(name string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case "":
+		return ModeTable, nil
+	case ModeTable, ModeVertical, ModeBatch, ModeHTML, ModeMarkdown:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown output mode %q (expected table, vertical, batch, html or markdown)", name)
+	}
+}
+
+// IsNumericType reports whether the mysql client would right-align a column
+// of the given database type. SQLite's INTEGER, REAL and NUMERIC declared
+// types are treated the same way.
+func IsNumericType(typeName string) bool {
+	switch strings.TrimPrefix(strings.ToUpper(typeName), "UNSIGNED ") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT",
+		"DECIMAL", "FLOAT", "DOUBLE", "YEAR", "NULL",
+		"INTEGER", "REAL", "NUMERIC":
+		return true
+	default:
+		return false
+	}
+}
+
+// Render renders the rows of a result set. Summary lines are produced
+// separately by ResultSummary.
+func Render(mode Mode, rs *domain.ResultSet) string {
+	if len(rs.Columns) == 0 {
+		return ""
+	}
+
+	switch mode {
+	case ModeVertical:
+		return renderVertical(rs)
+	case ModeBatch:
+		return renderBatch(rs)
+	case ModeHTML:
+		return renderHTML(rs)
+	case ModeMarkdown:
+		return renderMarkdown(rs)
+	default:
+		return renderTable(rs)
+	}
+}
+
+// ResultSummary formats the footer printed after a result set,
+// e.g. "2 rows in set, 1 warning (0.01 sec)"
+func ResultSummary(mode Mode, rowCount int, warningCount int, duration time.Duration) string {
+	if mode == ModeBatch {
+		return ""
+	}
+
+	var summary string
+	if rowCount == 0 {
+		summary = "Empty set"
+	} else {
+		summary = formatRowCount(rowCount, "in set")
+	}
+
+	return summary + formatWarningCount(warningCount) + formatDuration(duration)
+}
+
+// OKSummary formats the line printed after a statement without a result set,
+// e.g. "Query OK, 1 row affected (0.00 sec)"
+func OKSummary(mode Mode, rowsAffected int64, warningCount int, duration time.Duration) string {
+	if mode == ModeBatch {
+		return ""
+	}
+
+	return "Query OK, " + formatRowCount(int(rowsAffected), "affected") +
+		formatWarningCount(warningCount) + formatDuration(duration)
+}
+
+// Warnings formats warnings the way the mysql client prints them with
+// warnings enabled, one per line
+func Warnings(mode Mode, warnings []domain.Warning) string {
+	if mode == ModeBatch || len(warnings) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(warnings))
+	for _, warning := range warnings {
+		lines = append(lines, fmt.Sprintf("%s (Code %d): %s", warning.Level, warning.Code, warning.Message))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatRowCount formats "1 row <suffix>" or "N rows <suffix>"
+func formatRowCount(count int, suffix string) string {
+	if count == 1 {
+		return "1 row " + suffix
+	}
+	return fmt.Sprintf("%d rows %s", count, suffix)
+}
+
+// formatWarningCount formats the ", N warnings" suffix
+func formatWarningCount(count int) string {
+	switch count {
+	case 0:
+		return ""
+	case 1:
+		return ", 1 warning"
+	default:
+		return fmt.Sprintf(", %d warnings", count)
+	}
+}
+
+// formatDuration formats the " (0.01 sec)" timing suffix
+func formatDuration(duration time.Duration) string {
+	return fmt.Sprintf(" (%.2f sec)", duration.Seconds())
+}
@@ -0,0 +1,126 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+func sampleResultSet() *domain.ResultSet {
+	return &domain.ResultSet{
+		Columns: []domain.Column{
+			{Name: "id", Type: "INT", Numeric: true},
+			{Name: "name", Type: "VARCHAR"},
+		},
+		Rows: [][]domain.Value{
+			{{Text: "1"}, {Text: "Иван"}},
+			{{Text: "22"}, {Text: "日本語"}},
+			{{Text: "333"}, {Null: true}},
+		},
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	cases := map[string]int{
+		"":        0,
+		"abc":     3,
+		"Иван":    4,
+		"日本語":     6,
+		"ｆｕｌｌ":    8,
+		"e\u0301": 1,
+	}
+
+	for s, expected := range cases {
+		if got := DisplayWidth(s); got != expected {
+			t.Errorf("DisplayWidth(%q) = %d, expected %d", s, got, expected)
+		}
+	}
+}
+
+func TestRender_Table(t *testing.T) {
+	expected := strings.Join([]string{
+		"+-----+--------+",
+		"| id  | name   |",
+		"+-----+--------+",
+		"|   1 | Иван   |",
+		"|  22 | 日本語 |",
+		"| 333 | NULL   |",
+		"+-----+--------+",
+		"",
+	}, "\n")
+
+	if got := Render(ModeTable, sampleResultSet()); got != expected {
+		t.Errorf("Unexpected table output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestRender_Vertical(t *testing.T) {
+	output := Render(ModeVertical, sampleResultSet())
+
+	if !strings.Contains(output, "*************************** 2. row ***************************\n  id: 22\nname: 日本語\n") {
+		t.Errorf("Unexpected vertical output:\n%s", output)
+	}
+}
+
+func TestRender_Batch(t *testing.T) {
+	rs := &domain.ResultSet{
+		Columns: []domain.Column{{Name: "a"}, {Name: "b"}},
+		Rows:    [][]domain.Value{{{Text: "x\ty"}, {Text: "line1\nline2"}}, {{Null: true}, {Text: `back\slash`}}},
+	}
+
+	expected := "a\tb\nx\\ty\tline1\\nline2\nNULL\tback\\\\slash\n"
+	if got := Render(ModeBatch, rs); got != expected {
+		t.Errorf("Unexpected batch output: %q", got)
+	}
+}
+
+func TestRender_HTMLAndMarkdown(t *testing.T) {
+	rs := &domain.ResultSet{
+		Columns: []domain.Column{{Name: "n", Numeric: true}, {Name: "s"}},
+		Rows:    [][]domain.Value{{{Text: "1"}, {Text: "<a|b>"}}},
+	}
+
+	html := Render(ModeHTML, rs)
+	if html != "<TABLE BORDER=1><TR><TH>n</TH><TH>s</TH></TR><TR><TD>1</TD><TD>&lt;a|b&gt;</TD></TR></TABLE>\n" {
+		t.Errorf("Unexpected HTML output: %q", html)
+	}
+
+	markdown := Render(ModeMarkdown, rs)
+	if markdown != "| n | s |\n| ---: | --- |\n| 1 | <a\\|b> |\n" {
+		t.Errorf("Unexpected Markdown output: %q", markdown)
+	}
+}
+
+func TestSummaries(t *testing.T) {
+	cases := []struct {
+		got      string
+		expected string
+	}{
+		{ResultSummary(ModeTable, 1, 0, 10*time.Millisecond), "1 row in set (0.01 sec)"},
+		{ResultSummary(ModeTable, 0, 0, 0), "Empty set (0.00 sec)"},
+		{ResultSummary(ModeVertical, 3, 2, 1500*time.Millisecond), "3 rows in set, 2 warnings (1.50 sec)"},
+		{ResultSummary(ModeBatch, 3, 0, 0), ""},
+		{OKSummary(ModeTable, 1, 1, 0), "Query OK, 1 row affected, 1 warning (0.00 sec)"},
+		{OKSummary(ModeTable, 0, 0, 0), "Query OK, 0 rows affected (0.00 sec)"},
+	}
+
+	for _, c := range cases {
+		if c.got != c.expected {
+			t.Errorf("Got %q, expected %q", c.got, c.expected)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if mode, err := ParseMode(""); err != nil || mode != ModeTable {
+		t.Errorf("Expected empty mode to default to table, got %q, %v", mode, err)
+	}
+	if mode, err := ParseMode("Vertical"); err != nil || mode != ModeVertical {
+		t.Errorf("Expected case-insensitive mode parsing, got %q, %v", mode, err)
+	}
+	if _, err := ParseMode("xml"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
@@ -0,0 +1,110 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// renderTable draws a bordered ASCII table like the interactive mysql client
+func renderTable(rs *domain.ResultSet) string {
+	// Calculate column widths in terminal cells
+	colWidths := make([]int, len(rs.Columns))
+	for i, col := range rs.Columns {
+		colWidths[i] = DisplayWidth(col.Name)
+	}
+
+	for _, row := range rs.Rows {
+		for i, val := range row {
+			if w := DisplayWidth(val.String()); w > colWidths[i] {
+				colWidths[i] = w
+			}
+		}
+	}
+
+	border := buildBorder(colWidths)
+
+	var output strings.Builder
+
+	// Header
+	output.WriteString(border)
+	output.WriteString("\n")
+
+	headers := make([]string, len(rs.Columns))
+	for i, col := range rs.Columns {
+		headers[i] = padRight(col.Name, colWidths[i])
+	}
+	output.WriteString(buildRow(headers))
+	output.WriteString("\n")
+
+	output.WriteString(border)
+	output.WriteString("\n")
+
+	// Data rows
+	cells := make([]string, len(rs.Columns))
+	for _, row := range rs.Rows {
+		for i, val := range row {
+			if rs.Columns[i].Numeric {
+				cells[i] = padLeft(val.String(), colWidths[i])
+			} else {
+				cells[i] = padRight(val.String(), colWidths[i])
+			}
+		}
+		output.WriteString(buildRow(cells))
+		output.WriteString("\n")
+	}
+
+	// Bottom border
+	output.WriteString(border)
+	output.WriteString("\n")
+
+	return output.String()
+}
+
+// buildBorder creates a border line for the table
+func buildBorder(colWidths []int) string {
+	var border strings.Builder
+	border.WriteString("+")
+	for _, width := range colWidths {
+		border.WriteString(strings.Repeat("-", width+2))
+		border.WriteString("+")
+	}
+	return border.String()
+}
+
+// buildRow joins already padded cells into a table row
+func buildRow(cells []string) string {
+	var row strings.Builder
+	row.WriteString("|")
+	for _, cell := range cells {
+		row.WriteString(" ")
+		row.WriteString(cell)
+		row.WriteString(" |")
+	}
+	return row.String()
+}
+
+// renderVertical prints every row as a block of "column: value" lines,
+// like the mysql client does for statements terminated by \G
+func renderVertical(rs *domain.ResultSet) string {
+	nameWidth := 0
+	for _, col := range rs.Columns {
+		if w := DisplayWidth(col.Name); w > nameWidth {
+			nameWidth = w
+		}
+	}
+
+	var output strings.Builder
+	for n, row := range rs.Rows {
+		output.WriteString(fmt.Sprintf("*************************** %d. row ***************************\n", n+1))
+		for i, val := range row {
+			output.WriteString(padLeft(rs.Columns[i].Name, nameWidth))
+			output.WriteString(": ")
+			output.WriteString(val.String())
+			output.WriteString("\n")
+		}
+	}
+
+	return output.String()
+}
@@ -0,0 +1,171 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// JSON rendering styles
+const (
+	JSONCompact = "compact"
+	JSONPretty  = "pretty"
+)
+
+// truncationMarker is appended to values cut at MaxValueLength
+const truncationMarker = "..."
+
+// ValueOptions controls how column values are rendered
+type ValueOptions struct {
+	// JSONStyle is JSONCompact or JSONPretty
+	JSONStyle string
+
+	// MaxValueLength truncates TEXT and BLOB values to this many characters.
+	// Zero disables truncation.
+	MaxValueLength int
+}
+
+// RenderValue converts a value scanned from the driver into a cell using the
+// column type: binary strings as 0x hex, BIT as b'...', JSON compact or
+// pretty, spatial values as WKT and temporal values in MySQL format.
+func RenderValue(col domain.Column, raw interface{}, opts ValueOptions) domain.Value {
+	switch v := raw.(type) {
+	case nil:
+		return domain.Value{Null: true}
+	case []byte:
+		return domain.Value{Text: renderBytes(col, v, opts)}
+	case string:
+		return domain.Value{Text: renderBytes(col, []byte(v), opts)}
+	case int64:
+		return domain.Value{Text: strconv.FormatInt(v, 10)}
+	case uint64:
+		return domain.Value{Text: strconv.FormatUint(v, 10)}
+	case float32:
+		return domain.Value{Text: strconv.FormatFloat(float64(v), 'g', -1, 32)}
+	case float64:
+		return domain.Value{Text: strconv.FormatFloat(v, 'g', -1, 64)}
+	case time.Time:
+		return domain.Value{Text: renderTime(col, v)}
+	case bool:
+		if v {
+			return domain.Value{Text: "1"}
+		}
+		return domain.Value{Text: "0"}
+	default:
+		return domain.Value{Text: fmt.Sprint(v)}
+	}
+}
+
+// renderBytes renders a value received in its wire (text) representation
+func renderBytes(col domain.Column, v []byte, opts ValueOptions) string {
+	switch strings.ToUpper(col.Type) {
+	case "BINARY", "VARBINARY", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB":
+		return renderHex(v, opts.MaxValueLength)
+	case "BIT":
+		return renderBit(v)
+	case "JSON":
+		return truncate(renderJSON(v, opts.JSONStyle), opts.MaxValueLength)
+	case "GEOMETRY":
+		if wkt, err := GeometryToWKT(v); err == nil {
+			return wkt
+		}
+		return renderHex(v, opts.MaxValueLength)
+	case "TEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT", "VARCHAR", "CHAR":
+		return truncate(string(v), opts.MaxValueLength)
+	default:
+		return string(v)
+	}
+}
+
+// renderHex renders binary data as 0x-prefixed upper-case hex, like the
+// mysql client with --binary-as-hex
+func renderHex(v []byte, maxLength int) string {
+	if len(v) == 0 {
+		return ""
+	}
+
+	truncated := false
+	if maxLength > 0 && len(v)*2+2 > maxLength {
+		limit := (maxLength - 2) / 2
+		if limit < 1 {
+			limit = 1
+		}
+		v = v[:limit]
+		truncated = true
+	}
+
+	text := "0x" + strings.ToUpper(hex.EncodeToString(v))
+	if truncated {
+		text += truncationMarker
+	}
+	return text
+}
+
+// renderBit renders BIT values as b'...' without leading zeros
+func renderBit(v []byte) string {
+	var bits strings.Builder
+	for _, b := range v {
+		bits.WriteString(fmt.Sprintf("%08b", b))
+	}
+
+	digits := strings.TrimLeft(bits.String(), "0")
+	if digits == "" {
+		digits = "0"
+	}
+	return "b'" + digits + "'"
+}
+
+// renderJSON re-encodes a JSON document in the requested style
+func renderJSON(v []byte, style string) string {
+	var buf bytes.Buffer
+
+	var err error
+	if style == JSONPretty {
+		err = json.Indent(&buf, v, "", "  ")
+	} else {
+		err = json.Compact(&buf, v)
+	}
+	if err != nil {
+		return string(v)
+	}
+
+	return buf.String()
+}
+
+// renderTime formats a parsed temporal value the way MySQL prints it
+func renderTime(col domain.Column, t time.Time) string {
+	if strings.ToUpper(col.Type) == "DATE" {
+		if t.IsZero() {
+			return "0000-00-00"
+		}
+		return t.Format("2006-01-02")
+	}
+
+	if t.IsZero() {
+		return "0000-00-00 00:00:00"
+	}
+
+	text := t.Format("2006-01-02 15:04:05")
+	if col.Scale > 0 && col.Scale <= 6 {
+		fraction := fmt.Sprintf("%09d", t.Nanosecond())[:col.Scale]
+		text += "." + fraction
+	}
+	return text
+}
+
+// truncate cuts text to maxLength characters and marks the cut
+func truncate(text string, maxLength int) string {
+	if maxLength <= 0 || utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+
+	runes := []rune(text)
+	return string(runes[:maxLength]) + truncationMarker
+}
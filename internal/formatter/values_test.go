@@ -0,0 +1,118 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+func TestRenderValue(t *testing.T) {
+	opts := ValueOptions{JSONStyle: JSONCompact}
+	ts := time.Date(2025, 3, 4, 5, 6, 7, 123456789, time.UTC)
+
+	cases := []struct {
+		col      domain.Column
+		raw      interface{}
+		expected string
+	}{
+		{domain.Column{Type: "VARBINARY"}, []byte("abc"), "0x616263"},
+		{domain.Column{Type: "BLOB"}, []byte{0x00, 0xff}, "0x00FF"},
+		{domain.Column{Type: "TEXT"}, []byte("Привет"), "Привет"},
+		{domain.Column{Type: "BIT"}, []byte{0x00, 0x05}, "b'101'"},
+		{domain.Column{Type: "BIT"}, []byte{0x00}, "b'0'"},
+		{domain.Column{Type: "JSON"}, []byte(`{"a": [1, 2]}`), `{"a":[1,2]}`},
+		{domain.Column{Type: "DECIMAL"}, []byte("10.50"), "10.50"},
+		{domain.Column{Type: "BIGINT"}, int64(-42), "-42"},
+		{domain.Column{Type: "DOUBLE"}, 0.1, "0.1"},
+		{domain.Column{Type: "DATETIME", Scale: 3}, ts, "2025-03-04 05:06:07.123"},
+		{domain.Column{Type: "DATE"}, ts, "2025-03-04"},
+		{domain.Column{Type: "DATETIME"}, time.Time{}, "0000-00-00 00:00:00"},
+	}
+
+	for _, c := range cases {
+		if got := RenderValue(c.col, c.raw, opts); got.Text != c.expected || got.Null {
+			t.Errorf("RenderValue(%s, %v) = %q, expected %q", c.col.Type, c.raw, got.Text, c.expected)
+		}
+	}
+
+	if !RenderValue(domain.Column{Type: "BLOB"}, nil, opts).Null {
+		t.Error("Expected nil to render as NULL")
+	}
+}
+
+func TestRenderValue_PrettyJSON(t *testing.T) {
+	got := RenderValue(domain.Column{Type: "JSON"}, []byte(`{"a": 1}`), ValueOptions{JSONStyle: JSONPretty})
+	if got.Text != "{\n  \"a\": 1\n}" {
+		t.Errorf("Unexpected pretty JSON: %q", got.Text)
+	}
+}
+
+func TestRenderValue_Truncation(t *testing.T) {
+	opts := ValueOptions{MaxValueLength: 4}
+
+	if got := RenderValue(domain.Column{Type: "TEXT"}, []byte("Привет мир"), opts); got.Text != "Прив..." {
+		t.Errorf("Expected rune-aware truncation, got %q", got.Text)
+	}
+	if got := RenderValue(domain.Column{Type: "BLOB"}, []byte("abcdef"), opts); got.Text != "0x61..." {
+		t.Errorf("Expected truncated hex, got %q", got.Text)
+	}
+	if got := RenderValue(domain.Column{Type: "INT"}, []byte("123456"), opts); got.Text != "123456" {
+		t.Errorf("Expected numbers never to be truncated, got %q", got.Text)
+	}
+}
+
+// geometry builds a MySQL internal geometry value from WKB parts
+func geometry(parts ...interface{}) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // SRID
+	for _, part := range parts {
+		switch v := part.(type) {
+		case float64:
+			binary.Write(&buf, binary.LittleEndian, math.Float64bits(v))
+		case int:
+			binary.Write(&buf, binary.LittleEndian, uint32(v))
+		case byte:
+			buf.WriteByte(v)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestGeometryToWKT(t *testing.T) {
+	const le = byte(1)
+
+	cases := []struct {
+		value    []byte
+		expected string
+	}{
+		{geometry(le, wkbPoint, 1.5, -2.0), "POINT(1.5 -2)"},
+		{geometry(le, wkbLineString, 2, 0.0, 0.0, 1.0, 1.0), "LINESTRING(0 0,1 1)"},
+		{geometry(le, wkbPolygon, 1, 4, 0.0, 0.0, 1.0, 0.0, 1.0, 1.0, 0.0, 0.0), "POLYGON((0 0,1 0,1 1,0 0))"},
+		{geometry(le, wkbMultiPoint, 2, le, wkbPoint, 1.0, 2.0, le, wkbPoint, 3.0, 4.0), "MULTIPOINT((1 2),(3 4))"},
+		{geometry(le, wkbGeometryCollection, 2, le, wkbPoint, 1.0, 2.0, le, wkbLineString, 2, 0.0, 0.0, 1.0, 1.0),
+			"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(0 0,1 1))"},
+	}
+
+	for _, c := range cases {
+		got, err := GeometryToWKT(c.value)
+		if err != nil || got != c.expected {
+			t.Errorf("GeometryToWKT = %q, %v; expected %q", got, err, c.expected)
+		}
+	}
+
+	invalid := [][]byte{
+		nil,
+		geometry(le, wkbPoint, 1.0),
+		geometry(le, wkbLineString, 1000000),
+		geometry(byte(7), wkbPoint, 1.0, 2.0),
+	}
+	for _, value := range invalid {
+		if _, err := GeometryToWKT(value); err == nil {
+			t.Errorf("Expected error for malformed geometry %x", value)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package formatter
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// DisplayWidth returns the number of terminal cells needed to print s.
+// East Asian wide and fullwidth characters take two cells, combining marks
+// and other zero-width characters take none.
+func DisplayWidth(s string) int {
+	total := 0
+	for _, r := range s {
+		total += runeWidth(r)
+	}
+	return total
+}
+
+// runeWidth returns the number of terminal cells taken by a rune
+func runeWidth(r rune) int {
+	if r == 0 || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// padRight pads s with spaces up to the given display width
+func padRight(s string, cells int) string {
+	if gap := cells - DisplayWidth(s); gap > 0 {
+		return s + strings.Repeat(" ", gap)
+	}
+	return s
+}
+
+// padLeft pads s with leading spaces up to the given display width
+func padLeft(s string, cells int) string {
+	if gap := cells - DisplayWidth(s); gap > 0 {
+		return strings.Repeat(" ", gap) + s
+	}
+	return s
+}
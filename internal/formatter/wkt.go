@@ -0,0 +1,245 @@
+package formatter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WKB geometry types
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+// maxGeometryDepth limits nesting of geometry collections
+const maxGeometryDepth = 32
+
+// errInvalidWKB is returned for malformed geometry values
+var errInvalidWKB = errors.New("invalid WKB geometry")
+
+// GeometryToWKT converts a MySQL internal geometry value (4-byte SRID
+// followed by WKB) into Well-Known Text, as ST_AsText would print it
+func GeometryToWKT(v []byte) (string, error) {
+	if len(v) < 4 {
+		return "", errInvalidWKB
+	}
+
+	reader := &wkbReader{data: v[4:]}
+	var out strings.Builder
+	if err := reader.geometry(&out, 0); err != nil {
+		return "", err
+	}
+	if reader.pos != len(reader.data) {
+		return "", errInvalidWKB
+	}
+
+	return out.String(), nil
+}
+
+// wkbReader decodes WKB data
+type wkbReader struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+// geometry decodes one geometry including its byte order and type header
+func (r *wkbReader) geometry(out *strings.Builder, depth int) error {
+	if depth > maxGeometryDepth {
+		return errInvalidWKB
+	}
+
+	kind, err := r.header()
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case wkbPoint:
+		out.WriteString("POINT(")
+		if err := r.point(out); err != nil {
+			return err
+		}
+		out.WriteString(")")
+	case wkbLineString:
+		out.WriteString("LINESTRING")
+		return r.pointList(out)
+	case wkbPolygon:
+		out.WriteString("POLYGON")
+		return r.polygon(out)
+	case wkbMultiPoint:
+		out.WriteString("MULTIPOINT")
+		return r.collection(out, depth, wkbPoint)
+	case wkbMultiLineString:
+		out.WriteString("MULTILINESTRING")
+		return r.collection(out, depth, wkbLineString)
+	case wkbMultiPolygon:
+		out.WriteString("MULTIPOLYGON")
+		return r.collection(out, depth, wkbPolygon)
+	case wkbGeometryCollection:
+		out.WriteString("GEOMETRYCOLLECTION")
+		return r.collection(out, depth, 0)
+	default:
+		return fmt.Errorf("%w: unsupported type %d", errInvalidWKB, kind)
+	}
+
+	return nil
+}
+
+// header reads the byte order marker and the geometry type
+func (r *wkbReader) header() (uint32, error) {
+	if r.pos >= len(r.data) {
+		return 0, errInvalidWKB
+	}
+
+	switch r.data[r.pos] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return 0, errInvalidWKB
+	}
+	r.pos++
+
+	return r.uint32()
+}
+
+// uint32 reads a 32-bit integer in the current byte order
+func (r *wkbReader) uint32() (uint32, error) {
+	if r.pos+4 > len(r.data) {
+		return 0, errInvalidWKB
+	}
+	v := r.order.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+// count reads an element count and checks it against the remaining data
+func (r *wkbReader) count(minElementSize int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if int64(n)*int64(minElementSize) > int64(len(r.data)-r.pos) {
+		return 0, errInvalidWKB
+	}
+	return int(n), nil
+}
+
+// point writes "x y"
+func (r *wkbReader) point(out *strings.Builder) error {
+	if r.pos+16 > len(r.data) {
+		return errInvalidWKB
+	}
+	x := math.Float64frombits(r.order.Uint64(r.data[r.pos:]))
+	y := math.Float64frombits(r.order.Uint64(r.data[r.pos+8:]))
+	r.pos += 16
+
+	out.WriteString(formatCoordinate(x))
+	out.WriteString(" ")
+	out.WriteString(formatCoordinate(y))
+	return nil
+}
+
+// pointList writes "(x y,x y,...)"
+func (r *wkbReader) pointList(out *strings.Builder) error {
+	n, err := r.count(16)
+	if err != nil {
+		return err
+	}
+
+	out.WriteString("(")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		if err := r.point(out); err != nil {
+			return err
+		}
+	}
+	out.WriteString(")")
+	return nil
+}
+
+// polygon writes "((ring),(ring),...)"
+func (r *wkbReader) polygon(out *strings.Builder) error {
+	n, err := r.count(4)
+	if err != nil {
+		return err
+	}
+
+	out.WriteString("(")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		if err := r.pointList(out); err != nil {
+			return err
+		}
+	}
+	out.WriteString(")")
+	return nil
+}
+
+// collection writes the members of a multi-geometry. Members of typed
+// collections are written without their type name; geometry collections
+// keep the full WKT of every member.
+func (r *wkbReader) collection(out *strings.Builder, depth int, memberKind uint32) error {
+	n, err := r.count(5)
+	if err != nil {
+		return err
+	}
+
+	out.WriteString("(")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			out.WriteString(",")
+		}
+
+		if memberKind == 0 {
+			if err := r.geometry(out, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		kind, err := r.header()
+		if err != nil {
+			return err
+		}
+		if kind != memberKind {
+			return errInvalidWKB
+		}
+
+		switch kind {
+		case wkbPoint:
+			out.WriteString("(")
+			err = r.point(out)
+			out.WriteString(")")
+		case wkbLineString:
+			err = r.pointList(out)
+		case wkbPolygon:
+			err = r.polygon(out)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	out.WriteString(")")
+	return nil
+}
+
+// formatCoordinate prints a coordinate in the shortest form, like MySQL
+func formatCoordinate(v float64) string {
+	return strings.Replace(strconv.FormatFloat(v, 'g', -1, 64), "e+", "e", 1)
+}
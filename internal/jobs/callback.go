@@ -0,0 +1,105 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/logging"
+)
+
+// callbackAttempts is how often a callback is tried before it is given up
+const callbackAttempts = 3
+
+// notifier posts finished jobs to the callback URL
+type notifier struct {
+	url     string
+	token   string
+	timeout time.Duration
+	client  *http.Client
+
+	// backoff is the delay before the second attempt; it doubles afterwards
+	backoff time.Duration
+}
+
+// newNotifier creates a notifier for url. Callbacks are signed when token
+// is set.
+func newNotifier(url, token string, timeout time.Duration) *notifier {
+	return &notifier{
+		url:     url,
+		token:   token,
+		timeout: timeout,
+		client:  &http.Client{},
+		backoff: time.Second,
+	}
+}
+
+// notify posts the job, retrying failed attempts. The receiver must answer
+// with a 2xx status.
+func (n *notifier) notify(ctx context.Context, job *domain.Job) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		logging.Warnf("Failed to encode callback of job %s: %v", job.ID, err)
+		return
+	}
+
+	backoff := n.backoff
+	for attempt := 1; ; attempt++ {
+		err = n.post(ctx, job.ID, body)
+		if err == nil {
+			return
+		}
+		if attempt == callbackAttempts || ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	logging.Warnf("Failed to deliver callback of job %s: %v", job.ID, err)
+}
+
+// post sends one callback request
+func (n *notifier) post(ctx context.Context, id string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Job-ID", id)
+	if n.token != "" {
+		req.Header.Set("X-Signature", "sha256="+sign(n.token, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback answered %s", resp.Status)
+	}
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of body with token
+func sign(token string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
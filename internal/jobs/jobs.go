@@ -0,0 +1,379 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/executor"
+	"mysql-tui-editor/server/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// Job errors
+var (
+	ErrNotFound  = domain.NewError(domain.CodeNotFound, "job not found")
+	ErrForbidden = domain.NewError(domain.CodeForbidden, "job belongs to another user")
+	ErrQueueFull = domain.NewError(domain.CodeUnavailable, "job queue is full")
+	ErrClosed    = domain.NewError(domain.CodeUnavailable, "server is shutting down")
+)
+
+// RunFunc executes the request of a job
+type RunFunc func(ctx context.Context) (*domain.ExecuteResponse, error)
+
+// entry is a queued or running job
+type entry struct {
+	// job is guarded by the mutex of the manager
+	job domain.Job
+
+	run    RunFunc
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Manager runs jobs with a pool of workers and keeps finished jobs for a
+// TTL. Queued and running jobs are kept in memory; every state change is
+// saved to the store.
+type Manager struct {
+	store    Store
+	ttl      time.Duration
+	notifier *notifier
+
+	mu     sync.Mutex
+	active map[string]*entry
+	closed bool
+
+	queue     chan *entry
+	workers   sync.WaitGroup
+	callbacks sync.WaitGroup
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewManager creates a job manager with the configured store and starts
+// its workers. Jobs that were queued or running when the server stopped
+// are marked as failed.
+func NewManager(cfg config.JobsConfig) (*Manager, error) {
+	var store Store = NewMemoryStore()
+	if cfg.Store == "disk" {
+		fileStore, err := NewFileStore(cfg.Dir)
+		if err != nil {
+			return nil, err
+		}
+		store = fileStore
+	}
+
+	m := &Manager{
+		store:  store,
+		ttl:    cfg.ResultTTL,
+		active: make(map[string]*entry),
+		queue:  make(chan *entry, cfg.QueueSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if cfg.CallbackURL != "" {
+		m.notifier = newNotifier(cfg.CallbackURL, cfg.CallbackToken, cfg.CallbackTimeout)
+	}
+
+	if err := m.recover(time.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("failed to read jobs: %w", err)
+	}
+
+	for range cfg.Workers {
+		m.workers.Add(1)
+		go m.work()
+	}
+	go m.expireLoop()
+
+	return m, nil
+}
+
+// CallbacksEnabled reports whether a callback URL is configured
+func (m *Manager) CallbacksEnabled() bool {
+	return m.notifier != nil
+}
+
+// Submit queues a job of owner that executes run. It fails with
+// ErrQueueFull when no more jobs can wait for a worker.
+func (m *Manager) Submit(owner string, requestID string, callback bool, run RunFunc) (*domain.Job, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &entry{
+		job: domain.Job{
+			ID:        uuid.New().String(),
+			Status:    domain.JobQueued,
+			Owner:     owner,
+			RequestID: requestID,
+			Callback:  callback,
+			CreatedAt: time.Now().UTC(),
+		},
+		run:    run,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		cancel()
+		return nil, ErrClosed
+	}
+	select {
+	case m.queue <- e:
+	default:
+		m.mu.Unlock()
+		cancel()
+		return nil, ErrQueueFull
+	}
+	m.active[e.job.ID] = e
+	job := e.job
+
+	// Saved before a worker can start the job and save its next state
+	m.save(&job)
+	m.mu.Unlock()
+
+	return &job, nil
+}
+
+// Get returns the job with the given id. Jobs with an owner are only
+// accessible to that owner.
+func (m *Manager) Get(id string, owner string) (*domain.Job, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound
+	}
+
+	m.mu.Lock()
+	e, ok := m.active[id]
+	var job domain.Job
+	if ok {
+		job = e.job
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		stored, err := m.store.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		job = *stored
+	}
+
+	if job.Owner != "" && job.Owner != owner {
+		return nil, ErrForbidden
+	}
+	return &job, nil
+}
+
+// Cancel cancels a queued or running job, or deletes a finished one
+func (m *Manager) Cancel(id string, owner string) error {
+	job, err := m.Get(id, owner)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	e, ok := m.active[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return m.store.Delete(job.ID)
+	}
+
+	m.cancel(e)
+	return nil
+}
+
+// Close stops accepting jobs, cancels the queued and running ones and waits
+// for the workers and pending callbacks
+func (m *Manager) Close() {
+	m.mu.Lock()
+	m.closed = true
+	close(m.queue)
+	entries := make([]*entry, 0, len(m.active))
+	for _, e := range m.active {
+		entries = append(entries, e)
+	}
+	m.mu.Unlock()
+
+	for _, e := range entries {
+		m.cancel(e)
+	}
+
+	m.workers.Wait()
+	close(m.stop)
+	<-m.done
+	m.callbacks.Wait()
+}
+
+// cancel cancels the context of a job. A queued job is finished right away;
+// a running job is finished by its worker once the execution returns.
+func (m *Manager) cancel(e *entry) {
+	e.cancel()
+
+	m.mu.Lock()
+	queued := e.job.Status == domain.JobQueued
+	if queued {
+		// Keeps the worker from starting the job
+		e.job.Status = domain.JobCancelled
+	}
+	m.mu.Unlock()
+
+	if queued {
+		m.finish(e, nil, nil)
+	}
+}
+
+// work runs queued jobs until the queue is closed
+func (m *Manager) work() {
+	defer m.workers.Done()
+
+	for e := range m.queue {
+		m.runJob(e)
+	}
+}
+
+// runJob executes a job and records its outcome
+func (m *Manager) runJob(e *entry) {
+	m.mu.Lock()
+	if e.job.Status != domain.JobQueued {
+		m.mu.Unlock()
+		return
+	}
+	startedAt := time.Now().UTC()
+	e.job.Status = domain.JobRunning
+	e.job.StartedAt = &startedAt
+	job := e.job
+	m.mu.Unlock()
+
+	m.save(&job)
+
+	ctx := executor.WithProgress(e.ctx, func(done, total int) {
+		m.mu.Lock()
+		e.job.Progress = domain.JobProgress{StatementsDone: done, StatementsTotal: total}
+		m.mu.Unlock()
+	})
+
+	response, err := e.run(ctx)
+	m.finish(e, response, err)
+}
+
+// finish records the outcome of a job, removes it from the active jobs and
+// posts the callback
+func (m *Manager) finish(e *entry, response *domain.ExecuteResponse, err error) {
+	m.mu.Lock()
+	now := time.Now().UTC()
+	expiresAt := now.Add(m.ttl)
+
+	job := &e.job
+	job.Result = response
+	job.FinishedAt = &now
+	job.ExpiresAt = &expiresAt
+
+	switch {
+	case e.ctx.Err() != nil:
+		job.Status = domain.JobCancelled
+		if response == nil {
+			job.Error = "Job cancelled"
+			job.ErrorCode = domain.CodeCancelled
+		}
+	case err != nil:
+		job.Status = domain.JobFailed
+		job.Error = err.Error()
+		job.ErrorCode = domain.CodeOf(err)
+	case response.Success:
+		job.Status = domain.JobSucceeded
+	default:
+		job.Status = domain.JobFailed
+	}
+
+	delete(m.active, job.ID)
+	finished := *job
+	m.mu.Unlock()
+
+	e.cancel()
+	m.save(&finished)
+
+	if finished.Callback && m.notifier != nil {
+		m.callbacks.Add(1)
+		go func() {
+			defer m.callbacks.Done()
+			m.notifier.notify(context.Background(), &finished)
+		}()
+	}
+}
+
+// save writes a job to the store. A failure is logged; the job keeps
+// running.
+func (m *Manager) save(job *domain.Job) {
+	if err := m.store.Save(job); err != nil {
+		logging.Warnf("Failed to save job %s: %v", job.ID, err)
+	}
+}
+
+// recover marks the stored jobs that did not finish before a restart as
+// failed, because their requests are lost
+func (m *Manager) recover(now time.Time) error {
+	jobs, err := m.store.List()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := now.Add(m.ttl)
+	for _, job := range jobs {
+		if job.Status.Finished() {
+			continue
+		}
+
+		job.Status = domain.JobFailed
+		job.Error = "Job interrupted by a server restart"
+		job.ErrorCode = domain.CodeUnavailable
+		job.FinishedAt = &now
+		job.ExpiresAt = &expiresAt
+		m.save(job)
+	}
+	return nil
+}
+
+// expireLoop periodically removes finished jobs whose TTL has passed
+func (m *Manager) expireLoop() {
+	defer close(m.done)
+
+	interval := m.ttl / 4
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.expire(now)
+		}
+	}
+}
+
+// expire removes finished jobs that expired before now
+func (m *Manager) expire(now time.Time) {
+	jobs, err := m.store.List()
+	if err != nil {
+		logging.Warnf("Failed to list jobs: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if !job.Status.Finished() || job.ExpiresAt == nil || job.ExpiresAt.After(now) {
+			continue
+		}
+		if err := m.store.Delete(job.ID); err != nil {
+			logging.Warnf("Failed to delete expired job %s: %v", job.ID, err)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/executor"
+)
+
+func testConfig() config.JobsConfig {
+	return config.JobsConfig{
+		Workers:         1,
+		QueueSize:       2,
+		ResultTTL:       time.Hour,
+		Store:           "memory",
+		CallbackTimeout: time.Second,
+	}
+}
+
+// waitFinished polls a job until it is finished
+func waitFinished(t *testing.T, m *Manager, id string) *domain.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id, "")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if job.Status.Finished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish", id)
+	return nil
+}
+
+func TestManagerRunsJobs(t *testing.T) {
+	m, err := NewManager(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	tests := []struct {
+		name   string
+		run    RunFunc
+		status domain.JobStatus
+		code   domain.ErrorCode
+	}{
+		{
+			name: "success",
+			run: func(ctx context.Context) (*domain.ExecuteResponse, error) {
+				executor.ReportProgress(ctx, 2, 2)
+				return domain.NewSuccessResponse("ok", 1), nil
+			},
+			status: domain.JobSucceeded,
+		},
+		{
+			name: "failed execution",
+			run: func(ctx context.Context) (*domain.ExecuteResponse, error) {
+				return domain.NewErrorResponse(domain.CodeSQLError, "syntax error"), nil
+			},
+			status: domain.JobFailed,
+		},
+		{
+			name: "error",
+			run: func(ctx context.Context) (*domain.ExecuteResponse, error) {
+				return nil, domain.ErrDatabaseUnavailable
+			},
+			status: domain.JobFailed,
+			code:   domain.CodeUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submitted, err := m.Submit("", "req-1", false, tt.run)
+			if err != nil {
+				t.Fatalf("Submit failed: %v", err)
+			}
+
+			job := waitFinished(t, m, submitted.ID)
+			if job.Status != tt.status {
+				t.Errorf("Expected status %s, got %s", tt.status, job.Status)
+			}
+			if job.ErrorCode != tt.code {
+				t.Errorf("Expected error code %q, got %q", tt.code, job.ErrorCode)
+			}
+			if job.RequestID != "req-1" || job.FinishedAt == nil || job.ExpiresAt == nil {
+				t.Errorf("Unexpected job: %+v", job)
+			}
+		})
+	}
+}
+
+func TestManagerProgress(t *testing.T) {
+	m, err := NewManager(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	job, err := m.Submit("", "", false, func(ctx context.Context) (*domain.ExecuteResponse, error) {
+		executor.ReportProgress(ctx, 3, 5)
+		return domain.NewSuccessResponse("", 0), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job = waitFinished(t, m, job.ID)
+	if job.Progress != (domain.JobProgress{StatementsDone: 3, StatementsTotal: 5}) {
+		t.Errorf("Unexpected progress: %+v", job.Progress)
+	}
+}
+
+func TestManagerCancelAndQueue(t *testing.T) {
+	m, err := NewManager(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	started := make(chan struct{})
+	blocking := func(ctx context.Context) (*domain.ExecuteResponse, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	idle := func(ctx context.Context) (*domain.ExecuteResponse, error) {
+		return domain.NewSuccessResponse("", 0), nil
+	}
+
+	running, err := m.Submit("alice", "", false, blocking)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	// The single worker is busy, so two jobs fill the queue
+	queued, err := m.Submit("alice", "", false, idle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Submit("alice", "", false, idle); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Submit("alice", "", false, idle); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+
+	if _, err := m.Get(running.ID, "bob"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+	if _, err := m.Get("../../etc/passwd", "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := m.Cancel(queued.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Cancel(running.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{queued.ID, running.ID} {
+		job, err := m.Get(id, "alice")
+		for err == nil && !job.Status.Finished() {
+			time.Sleep(5 * time.Millisecond)
+			job, err = m.Get(id, "alice")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != domain.JobCancelled || job.ErrorCode != domain.CodeCancelled {
+			t.Errorf("Expected cancelled job, got %s (%s)", job.Status, job.ErrorCode)
+		}
+	}
+
+	// Cancelling a finished job deletes it
+	if err := m.Cancel(queued.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Get(queued.ID, "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestManagerExpire(t *testing.T) {
+	m, err := NewManager(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	job, err := m.Submit("", "", false, func(ctx context.Context) (*domain.ExecuteResponse, error) {
+		return domain.NewSuccessResponse("", 0), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFinished(t, m, job.ID)
+
+	m.expire(time.Now().Add(30 * time.Minute))
+	if _, err := m.Get(job.ID, ""); err != nil {
+		t.Errorf("Job expired before its TTL: %v", err)
+	}
+
+	m.expire(time.Now().Add(2 * time.Hour))
+	if _, err := m.Get(job.ID, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected expired job to be removed, got %v", err)
+	}
+}
+
+func TestFileStoreRecovery(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+	if err := store.Save(&domain.Job{ID: id, Status: domain.JobRunning, Owner: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testConfig()
+	cfg.Store = "disk"
+	cfg.Dir = dir
+	m, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	job, err := m.Get(id, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != domain.JobFailed || job.ErrorCode != domain.CodeUnavailable || job.ExpiresAt == nil {
+		t.Errorf("Expected interrupted job to fail, got %+v", job)
+	}
+}
+
+func TestNotifierSignsCallbacks(t *testing.T) {
+	type callback struct {
+		id, signature string
+		body          []byte
+	}
+	received := make(chan callback, callbackAttempts)
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		received <- callback{id: r.Header.Get("X-Job-ID"), signature: r.Header.Get("X-Signature"), body: body}
+	}))
+	defer server.Close()
+
+	n := newNotifier(server.URL, "key", time.Second)
+	n.backoff = time.Millisecond
+	n.notify(context.Background(), &domain.Job{ID: "job-1", Status: domain.JobSucceeded})
+
+	select {
+	case got := <-received:
+		if got.id != "job-1" {
+			t.Errorf("Expected X-Job-ID job-1, got %q", got.id)
+		}
+		if want := "sha256=" + sign("key", got.body); got.signature != want {
+			t.Errorf("Expected signature %s, got %s", want, got.signature)
+		}
+	default:
+		t.Fatal("Callback was not retried")
+	}
+}
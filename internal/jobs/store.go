@@ -0,0 +1,172 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/logging"
+)
+
+// Store keeps jobs by ID
+type Store interface {
+	// Save creates or replaces a job
+	Save(job *domain.Job) error
+
+	// Get returns the job with the given ID or ErrNotFound
+	Get(id string) (*domain.Job, error)
+
+	// Delete removes a job. Unknown IDs are ignored.
+	Delete(id string) error
+
+	// List returns all jobs in no particular order
+	List() ([]*domain.Job, error)
+}
+
+// MemoryStore keeps jobs in memory; they are lost on restart
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]domain.Job
+}
+
+// NewMemoryStore creates an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]domain.Job)}
+}
+
+// Save creates or replaces a job
+func (s *MemoryStore) Save(job *domain.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+// Get returns the job with the given ID
+func (s *MemoryStore) Get(id string) (*domain.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &job, nil
+}
+
+// Delete removes a job
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+// List returns all jobs
+func (s *MemoryStore) List() ([]*domain.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]*domain.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// FileStore keeps every job as a JSON file in a directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save writes a job to its file. The file is replaced atomically, so a
+// crash never leaves a partial job behind.
+func (s *FileStore) Save(job *domain.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, job.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(job.ID))
+}
+
+// Get reads the job with the given ID
+func (s *FileStore) Get(id string) (*domain.Job, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job domain.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to read job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// Delete removes the file of a job
+func (s *FileStore) Delete(id string) error {
+	err := os.Remove(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// List reads all jobs. Unreadable files are skipped with a warning.
+func (s *FileStore) List() ([]*domain.Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*domain.Job
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		job, err := s.Get(id)
+		if err != nil {
+			logging.Warnf("Skipping job file %s: %v", entry.Name(), err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// path returns the file of a job
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
@@ -0,0 +1,30 @@
+// Package logging holds the log level shared by the request log and the
+// background workers
+package logging
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// level is the configured logging level, changed on reload
+var level atomic.Value
+
+// SetLevel sets the logging level: debug, info, warn or error
+func SetLevel(l string) {
+	level.Store(l)
+}
+
+// Level returns the logging level, empty until it is set
+func Level() string {
+	l, _ := level.Load().(string)
+	return l
+}
+
+// Warnf logs a warning unless only errors are logged
+func Warnf(format string, args ...interface{}) {
+	if Level() == "error" {
+		return
+	}
+	fmt.Printf("WARNING: "+format+"\n", args...)
+}
@@ -1,24 +1,58 @@
 package security
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"sync/atomic"
 
 	"mysql-tui-editor/server/internal/domain"
 )
 
+var (
+	// eventPattern matches CREATE EVENT and ALTER EVENT
+	eventPattern = regexp.MustCompile(`(?i)\b(CREATE|ALTER)\s+(DEFINER\s*=\s*\S+\s+)?EVENT\b`)
+
+	// definerPattern matches DEFINER = user clauses
+	definerPattern = regexp.MustCompile(`(?i)\bDEFINER\s*=`)
+)
+
 // Validator validates SQL queries for security threats
 type Validator struct {
 	dangerousPatterns []*regexp.Regexp
+
+	// enginePatterns holds additional patterns per engine
+	enginePatterns map[string][]*regexp.Regexp
+
+	// blockedPatterns holds the configured patterns, replaced on reload
+	blockedPatterns atomic.Pointer[[]*regexp.Regexp]
 }
 
 // NewValidator creates a new SQL validator
 func NewValidator() *Validator {
 	return &Validator{
 		dangerousPatterns: compileDangerousPatterns(),
+		enginePatterns:    compileEnginePatterns(),
 	}
 }
 
+// ValidateEngine checks the query against the common rules and the rules of
+// the engine it will run on
+func (v *Validator) ValidateEngine(engine string, query string) error {
+	if err := v.Validate(query); err != nil {
+		return err
+	}
+
+	normalizedQuery := normalizeQuery(query)
+	for _, pattern := range v.enginePatterns[engine] {
+		if pattern.MatchString(normalizedQuery) {
+			return domain.ErrDangerousCommand
+		}
+	}
+
+	return nil
+}
+
 // Validate checks if the query contains dangerous commands
 func (v *Validator) Validate(query string) error {
 	// Normalize query: convert to lowercase and remove extra whitespace
@@ -29,6 +63,16 @@ func (v *Validator) Validate(query string) error {
 		return domain.ErrDropDatabase
 	}
 
+	// Events run later under the definer's privileges, outside the sandbox lifetime
+	if eventPattern.MatchString(normalizedQuery) {
+		return domain.ErrEventNotAllowed
+	}
+
+	// A DEFINER clause lets routines, triggers and views run as another account
+	if definerPattern.MatchString(normalizedQuery) {
+		return domain.ErrDefinerClause
+	}
+
 	// Check for other dangerous patterns
 	for _, pattern := range v.dangerousPatterns {
 		if pattern.MatchString(normalizedQuery) {
@@ -36,6 +80,32 @@ func (v *Validator) Validate(query string) error {
 		}
 	}
 
+	// Check for the configured patterns
+	if blocked := v.blockedPatterns.Load(); blocked != nil {
+		for _, pattern := range *blocked {
+			if pattern.MatchString(normalizedQuery) {
+				return domain.ErrDangerousCommand
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetBlockedCommands replaces the configured patterns that are blocked in
+// addition to the built-in ones. Patterns are case-insensitive regular
+// expressions, e.g. `\bHANDLER\b`. On error the current patterns are kept.
+func (v *Validator) SetBlockedCommands(commands []string) error {
+	patterns := make([]*regexp.Regexp, 0, len(commands))
+	for _, cmd := range commands {
+		pattern, err := regexp.Compile(`(?i)` + cmd)
+		if err != nil {
+			return fmt.Errorf("invalid blocked command %q: %w", cmd, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	v.blockedPatterns.Store(&patterns)
 	return nil
 }
 
@@ -83,6 +153,49 @@ func compileDangerousPatterns() []*regexp.Regexp {
 	return patterns
 }
 
+// compileEnginePatterns compiles the engine specific patterns
+func compileEnginePatterns() map[string][]*regexp.Regexp {
+	engineCommands := map[string][]string{
+		domain.EngineSQLite: {
+			// Other database files
+			`\bATTACH\b`,
+			`\bVACUUM\s+INTO\b`,
+
+			// Native code
+			`\bLOAD_EXTENSION\s*\(`,
+		},
+		domain.EnginePostgres: {
+			// Server and client files
+			`\bCOPY\b`,
+			`\bPG_READ_(BINARY_)?FILE\s*\(`,
+			`\bPG_LS_DIR\s*\(`,
+			`\bLO_(IMPORT|EXPORT)\s*\(`,
+
+			// Changing the schema and role of the session. Sandboxes are
+			// isolated by their login role, these only keep sessions tidy.
+			`\bSET\s+(SESSION\s+|LOCAL\s+)?SEARCH_PATH\b`,
+			`\bSET_CONFIG\s*\(`,
+			`\bSET\s+(SESSION\s+|LOCAL\s+)?ROLE\b`,
+			`\bSET\s+(SESSION\s+|LOCAL\s+)?SESSION\s+AUTHORIZATION\b`,
+			`\bRESET\s+(ROLE|SESSION\s+AUTHORIZATION|SEARCH_PATH|ALL)\b`,
+			`\bDISCARD\s+ALL\b`,
+
+			// Extensions and remote connections
+			`\bCREATE\s+EXTENSION\b`,
+			`\bDBLINK\w*\s*\(`,
+		},
+	}
+
+	patterns := make(map[string][]*regexp.Regexp, len(engineCommands))
+	for engine, commands := range engineCommands {
+		for _, cmd := range commands {
+			patterns[engine] = append(patterns[engine], regexp.MustCompile(`(?i)`+cmd))
+		}
+	}
+
+	return patterns
+}
+
 // containsDropDatabase checks specifically for DROP DATABASE command
 func containsDropDatabase(query string) bool {
 	// Multiple patterns to catch various forms
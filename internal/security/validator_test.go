@@ -63,6 +63,10 @@ func TestValidator_SafeQueries(t *testing.T) {
 		"CREATE INDEX idx_name ON users(name);",
 		"SHOW TABLES;",
 		"DESCRIBE users;",
+		"CREATE PROCEDURE count_users(OUT total INT) BEGIN SELECT COUNT(*) INTO total FROM users; END",
+		"CREATE TRIGGER users_bi BEFORE INSERT ON users FOR EACH ROW SET NEW.name = TRIM(NEW.name);",
+		"CALL count_users(@total); SELECT @total;",
+		"SELECT event_name FROM events;",
 	}
 
 	for _, query := range safeQueries {
@@ -73,6 +77,61 @@ func TestValidator_SafeQueries(t *testing.T) {
 	}
 }
 
+func TestValidator_Routines(t *testing.T) {
+	validator := NewValidator()
+
+	cases := []struct {
+		query    string
+		expected error
+	}{
+		{"CREATE EVENT cleanup ON SCHEDULE EVERY 1 HOUR DO DELETE FROM logs;", domain.ErrEventNotAllowed},
+		{"create  event e on schedule at current_timestamp do select 1;", domain.ErrEventNotAllowed},
+		{"ALTER EVENT cleanup DISABLE;", domain.ErrEventNotAllowed},
+		{"CREATE DEFINER=`root`@`%` EVENT e ON SCHEDULE EVERY 1 DAY DO SELECT 1;", domain.ErrEventNotAllowed},
+		{"CREATE DEFINER = 'root'@'localhost' PROCEDURE p() SELECT 1;", domain.ErrDefinerClause},
+		{"CREATE DEFINER=root@localhost TRIGGER t BEFORE INSERT ON users FOR EACH ROW SET NEW.id = 1;", domain.ErrDefinerClause},
+		{"ALTER DEFINER = CURRENT_USER VIEW v AS SELECT 1;", domain.ErrDefinerClause},
+	}
+
+	for _, c := range cases {
+		if err := validator.Validate(c.query); err != c.expected {
+			t.Errorf("Query %q: expected %v, got %v", c.query, c.expected, err)
+		}
+	}
+}
+
+func TestValidator_Engines(t *testing.T) {
+	validator := NewValidator()
+
+	cases := []struct {
+		engine   string
+		query    string
+		expected error
+	}{
+		{domain.EngineSQLite, "ATTACH DATABASE '/tmp/other.db' AS other;", domain.ErrDangerousCommand},
+		{domain.EngineSQLite, "VACUUM INTO '/tmp/copy.db';", domain.ErrDangerousCommand},
+		{domain.EngineSQLite, "SELECT load_extension('evil');", domain.ErrDangerousCommand},
+		{domain.EngineSQLite, "CREATE TABLE t (id INTEGER PRIMARY KEY); SELECT * FROM t;", nil},
+		{domain.EnginePostgres, "COPY t FROM '/etc/passwd';", domain.ErrDangerousCommand},
+		{domain.EnginePostgres, "SET search_path TO public;", domain.ErrDangerousCommand},
+		{domain.EnginePostgres, "SELECT set_config('search_path', 'public', false);", domain.ErrDangerousCommand},
+		{domain.EnginePostgres, "SELECT pg_read_file('/etc/passwd');", domain.ErrDangerousCommand},
+		{domain.EnginePostgres, "RESET ROLE; SELECT * FROM other_schema.t;", domain.ErrDangerousCommand},
+		{domain.EnginePostgres, "BEGIN; SET LOCAL ROLE postgres;", domain.ErrDangerousCommand},
+		{domain.EnginePostgres, "DISCARD ALL;", domain.ErrDangerousCommand},
+		{domain.EnginePostgres, "CREATE EXTENSION dblink;", domain.ErrDangerousCommand},
+		{domain.EnginePostgres, "CREATE TABLE t (id SERIAL PRIMARY KEY); SELECT * FROM t;", nil},
+		{domain.EngineMySQL, "SELECT 1 AS copy;", nil},
+		{domain.EnginePostgres, "DROP DATABASE test;", domain.ErrDropDatabase},
+	}
+
+	for _, c := range cases {
+		if err := validator.ValidateEngine(c.engine, c.query); err != c.expected {
+			t.Errorf("Engine %s, query %q: expected %v, got %v", c.engine, c.query, c.expected, err)
+		}
+	}
+}
+
 func TestValidator_CaseInsensitive(t *testing.T) {
 	validator := NewValidator()
 
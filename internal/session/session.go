@@ -0,0 +1,352 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"mysql-tui-editor/server/internal/domain"
+	"mysql-tui-editor/server/internal/executor"
+
+	"github.com/google/uuid"
+)
+
+// Session errors
+var (
+	ErrNotFound     = domain.NewError(domain.CodeNotFound, "session not found")
+	ErrForbidden    = domain.NewError(domain.CodeForbidden, "session belongs to another user")
+	ErrLimitReached = domain.NewError(domain.CodeUnavailable, "maximum number of sessions reached")
+)
+
+// Session is a sandbox that persists between requests. Executions in a
+// session are serialized because they share one pinned connection.
+type Session struct {
+	ID        string
+	Owner     string
+	Fixture   string
+	Backend   string
+	Sandbox   string
+	CreatedAt time.Time
+
+	mu           sync.Mutex
+	sandbox      *executor.Sandbox
+	lastActivity time.Time
+}
+
+// Manager creates sessions and expires them after an idle timeout
+type Manager struct {
+	backends    *executor.Backends
+	idleTimeout time.Duration
+	maxSessions int
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewManager creates a session manager and starts expiring idle sessions.
+// A zero idle timeout keeps sessions until they are deleted, a zero
+// maxSessions does not limit the number of sessions.
+func NewManager(backends *executor.Backends, idleTimeout time.Duration, maxSessions int) *Manager {
+	m := &Manager{
+		backends:    backends,
+		idleTimeout: idleTimeout,
+		maxSessions: maxSessions,
+		sessions:    make(map[string]*Session),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	go m.expireLoop()
+	return m
+}
+
+// Create opens a new session owned by owner, optionally prepared from a
+// fixture. The sandbox is created on the given backend, or on the backend of
+// the fixture by default.
+func (m *Manager) Create(ctx context.Context, owner string, fixture string, backend string) (*Session, error) {
+	if m.maxSessions > 0 && m.Count() >= m.maxSessions {
+		return nil, ErrLimitReached
+	}
+
+	exec, err := m.backends.Resolve(backend, fixture)
+	if err != nil {
+		return nil, err
+	}
+
+	sandbox, err := exec.CreateSandbox(ctx, fixture, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := &Session{
+		ID:           uuid.New().String(),
+		Owner:        owner,
+		Fixture:      fixture,
+		Backend:      exec.Name(),
+		Sandbox:      sandbox.Name(),
+		CreatedAt:    now,
+		sandbox:      sandbox,
+		lastActivity: now,
+	}
+
+	m.mu.Lock()
+	m.sessions[session.ID] = session
+	m.mu.Unlock()
+
+	return session, nil
+}
+
+// Get returns the session with the given id. Sessions with an owner are
+// only accessible to that owner.
+func (m *Manager) Get(id string, owner string) (*Session, error) {
+	m.mu.Lock()
+	session, ok := m.sessions[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if session.Owner != "" && session.Owner != owner {
+		return nil, ErrForbidden
+	}
+	return session, nil
+}
+
+// Delete closes the session and drops its sandbox
+func (m *Manager) Delete(ctx context.Context, id string, owner string) error {
+	session, err := m.Get(id, owner)
+	if err != nil {
+		return err
+	}
+
+	m.remove(session)
+	return session.close(ctx)
+}
+
+// Terminate closes a session regardless of its owner. A running statement is
+// aborted instead of waited for.
+func (m *Manager) Terminate(ctx context.Context, id string) error {
+	m.mu.Lock()
+	session, ok := m.sessions[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return ErrNotFound
+	}
+
+	m.remove(session)
+
+	if exec, err := m.backends.Get(session.Backend); err == nil {
+		if err := exec.KillSandbox(ctx, session.Sandbox); err != nil {
+			fmt.Printf("WARNING: Failed to abort statement of session %s: %v\n", session.ID, err)
+		}
+	}
+
+	return session.close(ctx)
+}
+
+// List describes all open sessions, including their sandbox names, ordered
+// by creation time
+func (m *Manager) List() []domain.SessionInfo {
+	m.mu.Lock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	m.mu.Unlock()
+
+	infos := make([]domain.SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		info := m.Info(session)
+		info.Sandbox = session.Sandbox
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+// Count returns the number of open sessions
+func (m *Manager) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// Execute executes a query in the session sandbox
+func (m *Manager) Execute(ctx context.Context, session *Session, req *domain.ExecuteRequest) (*domain.ExecuteResponse, error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.sandbox == nil {
+		return nil, ErrNotFound
+	}
+
+	session.lastActivity = time.Now().UTC()
+	return session.sandbox.Executor().ExecuteIn(ctx, session.sandbox, req)
+}
+
+// Schema returns the current schema of the session sandbox
+func (m *Manager) Schema(ctx context.Context, session *Session) (*domain.Schema, error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.sandbox == nil {
+		return nil, ErrNotFound
+	}
+
+	session.lastActivity = time.Now().UTC()
+	return session.sandbox.Executor().Schema(ctx, session.sandbox)
+}
+
+// Dump writes the session sandbox as a SQL dump of at most maxBytes
+func (m *Manager) Dump(ctx context.Context, session *Session, maxBytes int64) (*executor.Dump, error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.sandbox == nil {
+		return nil, ErrNotFound
+	}
+
+	session.lastActivity = time.Now().UTC()
+	return session.sandbox.Executor().Dump(ctx, session.sandbox, maxBytes)
+}
+
+// Restore replaces the contents of the session sandbox with a SQL dump
+func (m *Manager) Restore(ctx context.Context, session *Session, script string) error {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.sandbox == nil {
+		return ErrNotFound
+	}
+
+	session.lastActivity = time.Now().UTC()
+	return session.sandbox.Executor().Restore(ctx, session.sandbox, script)
+}
+
+// Info returns the description of a session
+func (m *Manager) Info(session *Session) domain.SessionInfo {
+	session.mu.Lock()
+	lastActivity := session.lastActivity
+	session.mu.Unlock()
+
+	info := domain.SessionInfo{
+		ID:           session.ID,
+		Owner:        session.Owner,
+		Fixture:      session.Fixture,
+		Backend:      session.Backend,
+		CreatedAt:    session.CreatedAt,
+		LastActivity: lastActivity,
+	}
+	if m.idleTimeout > 0 {
+		info.ExpiresAt = lastActivity.Add(m.idleTimeout)
+	}
+	return info
+}
+
+// Close stops expiring sessions and closes all open sessions
+func (m *Manager) Close() {
+	close(m.stop)
+	<-m.done
+
+	m.mu.Lock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	m.sessions = make(map[string]*Session)
+	m.mu.Unlock()
+
+	for _, session := range sessions {
+		if err := session.close(context.Background()); err != nil {
+			fmt.Printf("WARNING: Failed to close session %s: %v\n", session.ID, err)
+		}
+	}
+}
+
+// expireLoop periodically closes sessions that have been idle too long
+func (m *Manager) expireLoop() {
+	defer close(m.done)
+
+	if m.idleTimeout <= 0 {
+		<-m.stop
+		return
+	}
+
+	interval := m.idleTimeout / 4
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.expire(now)
+		}
+	}
+}
+
+// expire closes sessions idle since before now minus the idle timeout
+func (m *Manager) expire(now time.Time) {
+	deadline := now.Add(-m.idleTimeout)
+
+	m.mu.Lock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	m.mu.Unlock()
+
+	for _, session := range sessions {
+		// Sessions with a running execution are busy, not idle
+		if !session.mu.TryLock() {
+			continue
+		}
+		idle := session.lastActivity.Before(deadline)
+		session.mu.Unlock()
+
+		if !idle {
+			continue
+		}
+
+		m.remove(session)
+		if err := session.close(context.Background()); err != nil {
+			fmt.Printf("WARNING: Failed to close expired session %s: %v\n", session.ID, err)
+		}
+	}
+}
+
+// remove removes the session from the manager
+func (m *Manager) remove(session *Session) {
+	m.mu.Lock()
+	delete(m.sessions, session.ID)
+	m.mu.Unlock()
+}
+
+// close drops the session sandbox, waiting for a running execution to finish
+func (s *Session) close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sandbox == nil {
+		return nil
+	}
+
+	err := s.sandbox.Cleanup(ctx)
+	s.sandbox = nil
+	return err
+}
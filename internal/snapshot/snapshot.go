@@ -0,0 +1,195 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Snapshot errors
+var (
+	ErrNotFound     = domain.NewError(domain.CodeNotFound, "snapshot not found")
+	ErrForbidden    = domain.NewError(domain.CodeForbidden, "snapshot belongs to another user")
+	ErrLimitReached = domain.NewError(domain.CodeValidation, "maximum number of snapshots reached, delete a snapshot first")
+)
+
+// Snapshot is a saved state of a session sandbox: a SQL dump that recreates
+// its objects and data
+type Snapshot struct {
+	Info   domain.SnapshotInfo
+	Script string
+}
+
+// Manager keeps the snapshots of session sandboxes and removes them after
+// their TTL. Snapshots are independent of their session, so they can be
+// restored after the session has expired.
+type Manager struct {
+	store       Store
+	ttl         time.Duration
+	maxPerOwner int
+
+	// mu serializes adding snapshots, so that the limit per owner holds
+	mu sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewManager creates a snapshot manager with the configured store and
+// starts expiring snapshots
+func NewManager(cfg config.SnapshotsConfig) (*Manager, error) {
+	var store Store = NewMemoryStore()
+	if cfg.Store == "disk" {
+		fileStore, err := NewFileStore(cfg.Dir)
+		if err != nil {
+			return nil, err
+		}
+		store = fileStore
+	}
+
+	m := &Manager{
+		store:       store,
+		ttl:         cfg.TTL,
+		maxPerOwner: cfg.MaxPerOwner,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	go m.expireLoop()
+	return m, nil
+}
+
+// Add saves a snapshot described by info with the given dump. The ID,
+// creation time, expiry and size are filled in.
+func (m *Manager) Add(info domain.SnapshotInfo, script string) (*domain.SnapshotInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.maxPerOwner > 0 {
+		owned, err := m.List(info.Owner, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(owned) >= m.maxPerOwner {
+			return nil, ErrLimitReached
+		}
+	}
+
+	info.ID = uuid.New().String()
+	info.CreatedAt = time.Now().UTC()
+	info.SizeBytes = int64(len(script))
+	if m.ttl > 0 {
+		expiresAt := info.CreatedAt.Add(m.ttl)
+		info.ExpiresAt = &expiresAt
+	}
+
+	if err := m.store.Save(&Snapshot{Info: info, Script: script}); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return &info, nil
+}
+
+// Get returns the snapshot with the given id. Snapshots with an owner are
+// only accessible to that owner.
+func (m *Manager) Get(id string, owner string) (*Snapshot, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound
+	}
+
+	snapshot, err := m.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Info.Owner != "" && snapshot.Info.Owner != owner {
+		return nil, ErrForbidden
+	}
+	return snapshot, nil
+}
+
+// List describes the snapshots of owner ordered by creation time,
+// optionally only those of one session
+func (m *Manager) List(owner string, sessionID string) ([]domain.SnapshotInfo, error) {
+	all, err := m.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]domain.SnapshotInfo, 0, len(all))
+	for _, info := range all {
+		if info.Owner != owner || (sessionID != "" && info.SessionID != sessionID) {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos, nil
+}
+
+// Delete removes a snapshot
+func (m *Manager) Delete(id string, owner string) error {
+	snapshot, err := m.Get(id, owner)
+	if err != nil {
+		return err
+	}
+	return m.store.Delete(snapshot.Info.ID)
+}
+
+// Close stops expiring snapshots
+func (m *Manager) Close() {
+	close(m.stop)
+	<-m.done
+}
+
+// expireLoop periodically removes snapshots whose TTL has passed
+func (m *Manager) expireLoop() {
+	defer close(m.done)
+
+	if m.ttl <= 0 {
+		<-m.stop
+		return
+	}
+
+	interval := m.ttl / 4
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.expire(now)
+		}
+	}
+}
+
+// expire removes snapshots that expired before now
+func (m *Manager) expire(now time.Time) {
+	infos, err := m.store.List()
+	if err != nil {
+		fmt.Printf("WARNING: Failed to list snapshots: %v\n", err)
+		return
+	}
+
+	for _, info := range infos {
+		if info.ExpiresAt == nil || info.ExpiresAt.After(now) {
+			continue
+		}
+		if err := m.store.Delete(info.ID); err != nil {
+			fmt.Printf("WARNING: Failed to delete expired snapshot %s: %v\n", info.ID, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package snapshot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"mysql-tui-editor/server/internal/config"
+	"mysql-tui-editor/server/internal/domain"
+)
+
+func TestManager(t *testing.T) {
+	for _, store := range []string{"memory", "disk"} {
+		t.Run(store, func(t *testing.T) {
+			m, err := NewManager(config.SnapshotsConfig{MaxPerOwner: 2, TTL: time.Hour, Store: store, Dir: t.TempDir()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Close()
+
+			first, err := m.Add(domain.SnapshotInfo{Name: "first", Owner: "alice", SessionID: "s1", Rows: 3}, "CREATE TABLE t (id INT);")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first.ID == "" || first.ExpiresAt == nil || first.SizeBytes != 24 {
+				t.Errorf("Unexpected snapshot info: %+v", first)
+			}
+
+			if _, err := m.Add(domain.SnapshotInfo{Owner: "alice", SessionID: "s2"}, ""); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := m.Add(domain.SnapshotInfo{Owner: "alice", SessionID: "s2"}, ""); !errors.Is(err, ErrLimitReached) {
+				t.Errorf("Expected ErrLimitReached, got %v", err)
+			}
+			if _, err := m.Add(domain.SnapshotInfo{Owner: "bob", SessionID: "s3"}, ""); err != nil {
+				t.Errorf("The limit of one owner affected another: %v", err)
+			}
+
+			snapshot, err := m.Get(first.ID, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if snapshot.Script != "CREATE TABLE t (id INT);" || snapshot.Info.Name != "first" || snapshot.Info.Rows != 3 {
+				t.Errorf("Unexpected snapshot: %+v", snapshot)
+			}
+
+			if _, err := m.Get(first.ID, "bob"); !errors.Is(err, ErrForbidden) {
+				t.Errorf("Expected ErrForbidden, got %v", err)
+			}
+			if _, err := m.Get("../config", "alice"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+
+			all, err := m.List("alice", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 2 || all[0].ID != first.ID {
+				t.Errorf("Expected alice's 2 snapshots oldest first, got %+v", all)
+			}
+			ofSession, err := m.List("alice", "s1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ofSession) != 1 || ofSession[0].ID != first.ID {
+				t.Errorf("Expected the snapshot of session s1, got %+v", ofSession)
+			}
+
+			if err := m.Delete(first.ID, "bob"); !errors.Is(err, ErrForbidden) {
+				t.Errorf("Expected ErrForbidden, got %v", err)
+			}
+			if err := m.Delete(first.ID, "alice"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := m.Get(first.ID, "alice"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound after delete, got %v", err)
+			}
+		})
+	}
+}
+
+func TestManagerExpire(t *testing.T) {
+	m, err := NewManager(config.SnapshotsConfig{TTL: time.Hour, Store: "memory"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	info, err := m.Add(domain.SnapshotInfo{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.expire(time.Now().Add(30 * time.Minute))
+	if _, err := m.Get(info.ID, ""); err != nil {
+		t.Errorf("Snapshot expired before its TTL: %v", err)
+	}
+
+	m.expire(time.Now().Add(2 * time.Hour))
+	if _, err := m.Get(info.ID, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected expired snapshot to be removed, got %v", err)
+	}
+}
+
+func TestFileStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.SnapshotsConfig{Store: "disk", Dir: dir}
+
+	m, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := m.Add(domain.SnapshotInfo{Owner: "alice"}, "SELECT 1;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	m, err = NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	snapshot, err := m.Get(info.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Script != "SELECT 1;" || snapshot.Info.ExpiresAt != nil {
+		t.Errorf("Unexpected snapshot after restart: %+v", snapshot)
+	}
+}
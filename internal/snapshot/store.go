@@ -0,0 +1,200 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"mysql-tui-editor/server/internal/domain"
+)
+
+// Store keeps snapshots by ID
+type Store interface {
+	// Save creates or replaces a snapshot
+	Save(snapshot *Snapshot) error
+
+	// Get returns the snapshot with the given ID or ErrNotFound
+	Get(id string) (*Snapshot, error)
+
+	// Delete removes a snapshot. Unknown IDs are ignored.
+	Delete(id string) error
+
+	// List describes all snapshots in no particular order
+	List() ([]domain.SnapshotInfo, error)
+}
+
+// MemoryStore keeps snapshots in memory; they are lost on restart
+type MemoryStore struct {
+	mu        sync.Mutex
+	snapshots map[string]*Snapshot
+}
+
+// NewMemoryStore creates an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{snapshots: make(map[string]*Snapshot)}
+}
+
+// Save creates or replaces a snapshot
+func (s *MemoryStore) Save(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[snapshot.Info.ID] = snapshot
+	return nil
+}
+
+// Get returns the snapshot with the given ID
+func (s *MemoryStore) Get(id string) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, ok := s.snapshots[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return snapshot, nil
+}
+
+// Delete removes a snapshot
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.snapshots, id)
+	return nil
+}
+
+// List describes all snapshots
+func (s *MemoryStore) List() ([]domain.SnapshotInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := make([]domain.SnapshotInfo, 0, len(s.snapshots))
+	for _, snapshot := range s.snapshots {
+		infos = append(infos, snapshot.Info)
+	}
+	return infos, nil
+}
+
+// FileStore keeps every snapshot as a JSON description and a SQL dump in a
+// directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save writes the dump and then the description of a snapshot. A snapshot
+// without description is not listed, so a crash never exposes a partial dump.
+func (s *FileStore) Save(snapshot *Snapshot) error {
+	if err := writeFileAtomic(s.path(snapshot.Info.ID, ".sql"), []byte(snapshot.Script)); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(snapshot.Info)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path(snapshot.Info.ID, ".json"), data)
+}
+
+// Get reads the snapshot with the given ID
+func (s *FileStore) Get(id string) (*Snapshot, error) {
+	info, err := s.readInfo(id)
+	if err != nil {
+		return nil, err
+	}
+
+	script, err := os.ReadFile(s.path(id, ".sql"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{Info: *info, Script: string(script)}, nil
+}
+
+// Delete removes the files of a snapshot
+func (s *FileStore) Delete(id string) error {
+	for _, ext := range []string{".json", ".sql"} {
+		if err := os.Remove(s.path(id, ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// List reads the descriptions of all snapshots. Unreadable files are
+// skipped with a warning.
+func (s *FileStore) List() ([]domain.SnapshotInfo, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []domain.SnapshotInfo
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		info, err := s.readInfo(id)
+		if err != nil {
+			fmt.Printf("WARNING: Skipping snapshot file %s: %v\n", entry.Name(), err)
+			continue
+		}
+		infos = append(infos, *info)
+	}
+	return infos, nil
+}
+
+// readInfo reads the description of a snapshot
+func (s *FileStore) readInfo(id string) (*domain.SnapshotInfo, error) {
+	data, err := os.ReadFile(s.path(id, ".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var info domain.SnapshotInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+	}
+	return &info, nil
+}
+
+// path returns a file of a snapshot
+func (s *FileStore) path(id string, ext string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+ext)
+}
+
+// writeFileAtomic replaces a file through a temporary file and a rename
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}